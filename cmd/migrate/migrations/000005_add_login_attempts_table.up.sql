@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+    attempt_key VARCHAR(320) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NULL,
+    locked_until TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+ALTER TABLE tokens DROP INDEX idx_tokens_session, DROP COLUMN session_id;
+DROP TABLE IF EXISTS sessions;
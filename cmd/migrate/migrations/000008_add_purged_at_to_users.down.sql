@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN purged_at;
\ No newline at end of file
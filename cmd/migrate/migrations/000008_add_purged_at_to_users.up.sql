@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN purged_at TIMESTAMP NULL;
@@ -0,0 +1 @@
+ALTER TABLE tokens ADD COLUMN device_nonce_hash VARCHAR(64) NULL;
@@ -0,0 +1,2 @@
+DELETE FROM tokens;
+ALTER TABLE tokens DROP INDEX uq_tokens_value, MODIFY value TEXT NOT NULL, ADD UNIQUE KEY value (value(255));
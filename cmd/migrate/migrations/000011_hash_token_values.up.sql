@@ -0,0 +1,3 @@
+-- tokens are now stored as an HMAC-SHA256 hex digest, plaintext tokens can never match again so they are removed
+DELETE FROM tokens;
+ALTER TABLE tokens DROP INDEX value, MODIFY value CHAR(64) NOT NULL, ADD UNIQUE KEY uq_tokens_value (value);
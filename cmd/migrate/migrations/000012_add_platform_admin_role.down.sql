@@ -0,0 +1 @@
+DELETE FROM roles WHERE name = 'platform-admin';
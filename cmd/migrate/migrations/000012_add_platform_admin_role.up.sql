@@ -0,0 +1 @@
+INSERT INTO roles (name, isDefault) VALUES ('platform-admin', FALSE) ON DUPLICATE KEY UPDATE name = name;
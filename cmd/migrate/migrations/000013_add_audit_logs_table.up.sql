@@ -0,0 +1,19 @@
+-- append only, entries outlive the users and institutions they mention so there are no foreign keys
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id SERIAL PRIMARY KEY,
+    actor_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    action VARCHAR(100) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_id VARCHAR(64) NOT NULL DEFAULT '',
+    institution_id BIGINT UNSIGNED NULL,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    changes JSON NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    INDEX idx_audit_logs_institution (institution_id, created_at),
+    INDEX idx_audit_logs_actor (actor_id, created_at),
+    INDEX idx_audit_logs_target (target_type, target_id)
+);
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append only';
+CREATE TRIGGER audit_logs_no_delete BEFORE DELETE ON audit_logs FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append only';
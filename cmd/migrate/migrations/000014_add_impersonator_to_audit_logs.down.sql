@@ -0,0 +1 @@
+ALTER TABLE audit_logs DROP INDEX idx_audit_logs_impersonator, DROP COLUMN impersonator_id;
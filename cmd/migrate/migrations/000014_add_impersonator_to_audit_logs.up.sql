@@ -0,0 +1 @@
+ALTER TABLE audit_logs ADD COLUMN impersonator_id BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER actor_id, ADD INDEX idx_audit_logs_impersonator (impersonator_id, created_at);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_profiles (
+    user_id BIGINT UNSIGNED PRIMARY KEY,
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    bio VARCHAR(500) NOT NULL DEFAULT '',
+    avatar_key VARCHAR(255) NOT NULL DEFAULT '',
+    avatar_url VARCHAR(512) NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    locale VARCHAR(35) NOT NULL DEFAULT 'en',
+    preferences JSON NULL,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_user_profiles_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
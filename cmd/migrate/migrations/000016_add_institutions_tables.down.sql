@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS group_courses;
+DROP TABLE IF EXISTS group_staff;
+DROP TABLE IF EXISTS institution_courses;
+DROP TABLE IF EXISTS institution_groups;
+DROP TABLE IF EXISTS institution_staff;
+DROP TABLE IF EXISTS institutions;
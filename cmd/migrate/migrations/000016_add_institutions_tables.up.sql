@@ -0,0 +1,68 @@
+CREATE TABLE IF NOT EXISTS institutions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(800) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS institution_staff (
+    id SERIAL PRIMARY KEY,
+    institution_id BIGINT UNSIGNED NOT NULL,
+    user_id BIGINT UNSIGNED NULL,
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP NULL,
+    UNIQUE KEY uq_institution_staff_email (institution_id, email),
+    UNIQUE KEY uq_institution_staff_user (institution_id, user_id),
+    INDEX idx_institution_staff_user (user_id),
+    CONSTRAINT fk_institution_staff_institution FOREIGN KEY (institution_id) REFERENCES institutions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_institution_staff_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS institution_groups (
+    id SERIAL PRIMARY KEY,
+    institution_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(800) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE KEY uq_institution_groups_name (institution_id, name),
+    CONSTRAINT fk_institution_groups_institution FOREIGN KEY (institution_id) REFERENCES institutions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS institution_courses (
+    id SERIAL PRIMARY KEY,
+    institution_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(800) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    INDEX idx_institution_courses_institution (institution_id),
+    CONSTRAINT fk_institution_courses_institution FOREIGN KEY (institution_id) REFERENCES institutions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS group_staff (
+    group_id BIGINT UNSIGNED NOT NULL,
+    staff_id BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (group_id, staff_id),
+    INDEX idx_group_staff_staff (staff_id),
+    CONSTRAINT fk_group_staff_group FOREIGN KEY (group_id) REFERENCES institution_groups(id) ON DELETE CASCADE,
+    CONSTRAINT fk_group_staff_staff FOREIGN KEY (staff_id) REFERENCES institution_staff(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS group_courses (
+    group_id BIGINT UNSIGNED NOT NULL,
+    course_id BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (group_id, course_id),
+    INDEX idx_group_courses_course (course_id),
+    CONSTRAINT fk_group_courses_group FOREIGN KEY (group_id) REFERENCES institution_groups(id) ON DELETE CASCADE,
+    CONSTRAINT fk_group_courses_course FOREIGN KEY (course_id) REFERENCES institution_courses(id) ON DELETE CASCADE
+);
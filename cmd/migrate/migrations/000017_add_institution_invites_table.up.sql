@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS institution_invites (
+    id SERIAL PRIMARY KEY,
+    institution_id BIGINT UNSIGNED NOT NULL,
+    staff_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(50) NOT NULL,
+    invited_by BIGINT UNSIGNED NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    INDEX idx_institution_invites_institution (institution_id, created_at),
+    INDEX idx_institution_invites_staff (staff_id, status),
+    CONSTRAINT fk_institution_invites_institution FOREIGN KEY (institution_id) REFERENCES institutions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_institution_invites_staff FOREIGN KEY (staff_id) REFERENCES institution_staff(id) ON DELETE CASCADE
+);
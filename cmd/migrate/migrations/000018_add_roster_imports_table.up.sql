@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS roster_imports (
+    id SERIAL PRIMARY KEY,
+    institution_id BIGINT UNSIGNED NOT NULL,
+    created_by BIGINT UNSIGNED NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    total_rows INT NOT NULL,
+    results JSON NOT NULL,
+    failure TEXT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    finished_at TIMESTAMP NULL,
+    INDEX idx_roster_imports_institution (institution_id, created_at),
+    CONSTRAINT fk_roster_imports_institution FOREIGN KEY (institution_id) REFERENCES institutions(id) ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+ALTER TABLE institution_courses DROP INDEX uq_institution_courses_name;
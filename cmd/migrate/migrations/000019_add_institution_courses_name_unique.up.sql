@@ -0,0 +1 @@
+ALTER TABLE institution_courses ADD UNIQUE KEY uq_institution_courses_name (institution_id, name);
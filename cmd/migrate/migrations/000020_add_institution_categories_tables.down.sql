@@ -0,0 +1,3 @@
+ALTER TABLE institution_courses DROP FOREIGN KEY fk_institution_courses_category, DROP COLUMN category_id;
+DROP TABLE IF EXISTS category_staff;
+DROP TABLE IF EXISTS institution_categories;
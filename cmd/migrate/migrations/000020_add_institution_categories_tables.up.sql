@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS institution_categories (
+    id SERIAL PRIMARY KEY,
+    institution_id BIGINT UNSIGNED NOT NULL,
+    parent_id BIGINT UNSIGNED NULL,
+    kind VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    INDEX idx_institution_categories_institution (institution_id),
+    INDEX idx_institution_categories_parent (parent_id),
+    CONSTRAINT fk_institution_categories_institution FOREIGN KEY (institution_id) REFERENCES institutions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_institution_categories_parent FOREIGN KEY (parent_id) REFERENCES institution_categories(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS category_staff (
+    category_id BIGINT UNSIGNED NOT NULL,
+    staff_id BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (category_id, staff_id),
+    INDEX idx_category_staff_staff (staff_id),
+    CONSTRAINT fk_category_staff_category FOREIGN KEY (category_id) REFERENCES institution_categories(id) ON DELETE CASCADE,
+    CONSTRAINT fk_category_staff_staff FOREIGN KEY (staff_id) REFERENCES institution_staff(id) ON DELETE CASCADE
+);
+
+ALTER TABLE institution_courses ADD COLUMN category_id BIGINT UNSIGNED NULL,
+    ADD CONSTRAINT fk_institution_courses_category FOREIGN KEY (category_id) REFERENCES institution_categories(id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE institution_staff
+    DROP INDEX idx_institution_staff_status,
+    DROP COLUMN status_changed_at,
+    DROP COLUMN status_reason;
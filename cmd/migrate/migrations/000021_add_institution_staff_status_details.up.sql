@@ -0,0 +1,4 @@
+ALTER TABLE institution_staff
+    ADD COLUMN status_reason VARCHAR(500) NULL AFTER status,
+    ADD COLUMN status_changed_at TIMESTAMP NULL AFTER status_reason,
+    ADD INDEX idx_institution_staff_status (institution_id, status);
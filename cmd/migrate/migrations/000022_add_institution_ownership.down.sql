@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS institution_ownership_transfers;
+
+UPDATE institution_staff SET role = 'admin' WHERE role = 'owner';
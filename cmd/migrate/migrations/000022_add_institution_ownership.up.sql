@@ -0,0 +1,35 @@
+-- every institution gets an owner, the admin who has been staff the longest
+UPDATE institution_staff s
+JOIN (
+    SELECT MIN(id) AS id FROM institution_staff
+    WHERE role = 'admin' AND deleted_at IS NULL
+    GROUP BY institution_id
+) first_admin ON first_admin.id = s.id
+SET s.role = 'owner'
+WHERE NOT EXISTS (
+    SELECT 1 FROM (SELECT institution_id FROM institution_staff WHERE role = 'owner') owners
+    WHERE owners.institution_id = s.institution_id
+);
+
+CREATE TABLE IF NOT EXISTS institution_ownership_transfers (
+    id SERIAL PRIMARY KEY,
+    institution_id BIGINT UNSIGNED NOT NULL,
+    from_staff_id BIGINT UNSIGNED NOT NULL,
+    to_staff_id BIGINT UNSIGNED NOT NULL,
+    from_token_hash CHAR(64) NOT NULL,
+    to_token_hash CHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    from_confirmed_at TIMESTAMP NULL,
+    to_confirmed_at TIMESTAMP NULL,
+    expires_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP NULL,
+    cancelled_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE KEY uq_ownership_transfers_from_token (from_token_hash),
+    UNIQUE KEY uq_ownership_transfers_to_token (to_token_hash),
+    INDEX idx_ownership_transfers_institution (institution_id, status),
+    CONSTRAINT fk_ownership_transfers_institution FOREIGN KEY (institution_id) REFERENCES institutions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ownership_transfers_from_staff FOREIGN KEY (from_staff_id) REFERENCES institution_staff(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ownership_transfers_to_staff FOREIGN KEY (to_staff_id) REFERENCES institution_staff(id) ON DELETE CASCADE
+);
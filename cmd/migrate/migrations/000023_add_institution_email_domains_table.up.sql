@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS institution_email_domains (
+    id SERIAL PRIMARY KEY,
+    institution_id BIGINT UNSIGNED NOT NULL,
+    domain VARCHAR(253) NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    mailbox VARCHAR(64) NULL,
+    dns_token VARCHAR(100) NULL,
+    token_hash CHAR(64) NULL,
+    join_policy VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    -- only set once verified, so a domain is verified by one institution at most
+    verified_domain VARCHAR(253) NULL,
+    expires_at TIMESTAMP NULL,
+    verified_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE KEY uq_institution_email_domains_domain (institution_id, domain),
+    UNIQUE KEY uq_institution_email_domains_verified (verified_domain),
+    UNIQUE KEY uq_institution_email_domains_token (token_hash),
+    CONSTRAINT fk_institution_email_domains_institution FOREIGN KEY (institution_id) REFERENCES institutions(id) ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+ALTER TABLE tokens DROP INDEX idx_tokens_email_change, DROP COLUMN email_change_id;
@@ -0,0 +1,4 @@
+-- confirmation and revert tokens only act on the email change they were issued for
+ALTER TABLE tokens ADD COLUMN email_change_id BIGINT UNSIGNED NULL, ADD INDEX idx_tokens_email_change (email_change_id);
+-- the tokens issued before cannot be tied to their change, they are removed
+DELETE FROM tokens WHERE type IN ('email-change', 'email-change-revert');
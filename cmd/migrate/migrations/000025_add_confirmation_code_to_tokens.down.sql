@@ -0,0 +1 @@
+ALTER TABLE tokens DROP COLUMN confirmation_code_hash;
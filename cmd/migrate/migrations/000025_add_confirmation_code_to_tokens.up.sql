@@ -0,0 +1 @@
+ALTER TABLE tokens ADD COLUMN confirmation_code_hash VARCHAR(64) NULL;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS audit_logs_no_update;
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append only';
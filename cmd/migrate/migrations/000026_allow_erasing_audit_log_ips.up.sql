@@ -0,0 +1,12 @@
+-- entries stay append only, the one change allowed is erasing the ip and user agent of a purged account
+DROP TRIGGER IF EXISTS audit_logs_no_update;
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs FOR EACH ROW
+BEGIN
+    IF NOT (NEW.ip = '' AND NEW.user_agent = ''
+        AND NEW.id = OLD.id AND NEW.actor_id = OLD.actor_id AND NEW.impersonator_id = OLD.impersonator_id
+        AND NEW.action = OLD.action AND NEW.target_type = OLD.target_type AND NEW.target_id = OLD.target_id
+        AND NEW.institution_id <=> OLD.institution_id AND NEW.request_id = OLD.request_id
+        AND NEW.changes <=> OLD.changes AND NEW.created_at <=> OLD.created_at) THEN
+        SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append only';
+    END IF;
+END;
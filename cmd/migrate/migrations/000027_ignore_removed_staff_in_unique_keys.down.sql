@@ -0,0 +1,8 @@
+ALTER TABLE institution_staff
+    ADD UNIQUE KEY uq_institution_staff_email (institution_id, email),
+    ADD UNIQUE KEY uq_institution_staff_user (institution_id, user_id);
+ALTER TABLE institution_staff
+    DROP INDEX uq_institution_staff_active_user,
+    DROP INDEX uq_institution_staff_active_email,
+    DROP COLUMN active_user_id,
+    DROP COLUMN active_email;
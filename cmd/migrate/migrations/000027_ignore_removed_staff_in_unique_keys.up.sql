@@ -0,0 +1,9 @@
+-- removed staff keep their row until the retention runs out, only the staff still in the institution hold their email and user
+ALTER TABLE institution_staff
+    ADD COLUMN active_email VARCHAR(255) AS (IF(deleted_at IS NULL, email, NULL)) VIRTUAL,
+    ADD COLUMN active_user_id BIGINT UNSIGNED AS (IF(deleted_at IS NULL, user_id, NULL)) VIRTUAL,
+    ADD UNIQUE KEY uq_institution_staff_active_email (institution_id, active_email),
+    ADD UNIQUE KEY uq_institution_staff_active_user (institution_id, active_user_id);
+ALTER TABLE institution_staff
+    DROP INDEX uq_institution_staff_email,
+    DROP INDEX uq_institution_staff_user;
@@ -0,0 +1,2 @@
+DELETE FROM tokens WHERE used_at IS NOT NULL;
+ALTER TABLE tokens DROP COLUMN used_at;
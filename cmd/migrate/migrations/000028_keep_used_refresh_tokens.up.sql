@@ -0,0 +1,2 @@
+-- rotated refresh tokens are kept as used until their session ends, presenting one again signs the session out
+ALTER TABLE tokens ADD COLUMN used_at TIMESTAMP NULL DEFAULT NULL;
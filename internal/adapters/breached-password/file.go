@@ -0,0 +1,125 @@
+package breachedpasswordadapter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	breachedpassword "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/breached-password"
+)
+
+const (
+	prefixLength = 5
+	hashLength   = 40
+)
+
+// FileBreachedPasswordStore serves ranges from a local copy of a breached password list sorted by hash,
+// one SHA-1 hash per line optionally followed by ":count" (the Have I Been Pwned "ordered by hash" download).
+// The list runs into tens of gigabytes, so it is never loaded, every Range binary searches the file for its prefix.
+type FileBreachedPasswordStore struct {
+	file *os.File
+	size int64
+}
+
+// NewFileBreachedPasswordStore opens the list, it stays open for the life of the store
+func NewFileBreachedPasswordStore(path string) (breachedpassword.BreachedPasswordStore, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening breached password file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error reading breached password file: %w", err)
+	}
+	return &FileBreachedPasswordStore{file: f, size: info.Size()}, nil
+}
+
+func (s *FileBreachedPasswordStore) Range(ctx context.Context, prefix string) ([]string, error) {
+	if len(prefix) != prefixLength {
+		return nil, fmt.Errorf("hash prefix must be %d characters", prefixLength)
+	}
+	prefix = strings.ToUpper(prefix)
+
+	// find the smallest offset whose next line is not below the prefix, lines only grow as the offset does
+	lo, hi := int64(0), s.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		hash, _, err := s.lineFrom(mid)
+		if err != nil {
+			return nil, err
+		}
+		if hash == "" || hash[:prefixLength] >= prefix {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	hash, start, err := s.lineFrom(lo)
+	if err != nil || hash == "" || hash[:prefixLength] != prefix {
+		return nil, err
+	}
+	var suffixes []string
+	scanner := bufio.NewScanner(io.NewSectionReader(s.file, start, s.size-start))
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if hash == "" {
+			continue
+		}
+		if len(hash) != hashLength {
+			return nil, errors.New("invalid hash in breached password file")
+		}
+		hash = strings.ToUpper(hash)
+		if hash[:prefixLength] != prefix {
+			break
+		}
+		suffixes = append(suffixes, hash[prefixLength:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading breached password file: %w", err)
+	}
+	return suffixes, nil
+}
+
+// lineFrom returns the hash of the first line starting at or after offset together with where that line starts,
+// blank lines are skipped and an empty hash means the end of the file was reached
+func (s *FileBreachedPasswordStore) lineFrom(offset int64) (string, int64, error) {
+	start := offset
+	if offset > 0 {
+		// offset may land mid line, begin reading at the byte before it so a line starting exactly at offset is kept
+		start = offset - 1
+	}
+	reader := bufio.NewReader(io.NewSectionReader(s.file, start, s.size-start))
+	if offset > 0 {
+		skipped, readErr := reader.ReadString('\n')
+		if errors.Is(readErr, io.EOF) {
+			return "", s.size, nil
+		}
+		if readErr != nil {
+			return "", 0, fmt.Errorf("error reading breached password file: %w", readErr)
+		}
+		start += int64(len(skipped))
+	}
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return "", 0, fmt.Errorf("error reading breached password file: %w", readErr)
+		}
+		hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+		if hash != "" {
+			if len(hash) != hashLength {
+				return "", 0, fmt.Errorf("invalid hash at byte %d of breached password file", start)
+			}
+			return strings.ToUpper(hash), start, nil
+		}
+		if errors.Is(readErr, io.EOF) {
+			return "", s.size, nil
+		}
+		start += int64(len(line))
+	}
+}
@@ -0,0 +1,72 @@
+package breachedpasswordadapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeList(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFileBreachedPasswordStoreRange(t *testing.T) {
+	path := writeList(t,
+		"000000005AD76BD555C1D6D771DE417A4B87E4B4:4",
+		"00000000A8DAE4228F821FB418F59826079BF368:2",
+		"",
+		"21BD10018A45C4D1DEF81644B54AB7F969B88D65:12",
+		"21BD1001D1CB7D2E4C5A2E3B18E9C3B6D6A1B0E2:1",
+		"21bd1002c51f7c2d6e8c7e8f3a4b3e0f1d2c3b4a:7",
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824",
+		"FFFFFFFFF54D82BD3B6F9AF38A5D1B8DBE7B3B24:1",
+	)
+	store, err := NewFileBreachedPasswordStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{prefix: "00000", want: []string{"0005AD76BD555C1D6D771DE417A4B87E4B4", "000A8DAE4228F821FB418F59826079BF368"}},
+		{prefix: "21BD1", want: []string{"0018A45C4D1DEF81644B54AB7F969B88D65", "001D1CB7D2E4C5A2E3B18E9C3B6D6A1B0E2", "002C51F7C2D6E8C7E8F3A4B3E0F1D2C3B4A"}},
+		{prefix: "5baa6", want: []string{"1E4C9B93F3F0682250B6CF8331B7EE68FD8"}},
+		{prefix: "FFFFF", want: []string{"FFFF54D82BD3B6F9AF38A5D1B8DBE7B3B24"}},
+		{prefix: "21BD0"},
+		{prefix: "6AAAA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got, err := store.Range(context.Background(), tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Range(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileBreachedPasswordStoreRejectsInvalidInput(t *testing.T) {
+	store, err := NewFileBreachedPasswordStore(writeList(t, "5BAA6:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Range(context.Background(), "5BA"); err == nil {
+		t.Error("a short prefix was accepted")
+	}
+	if _, err := store.Range(context.Background(), "5BAA6"); err == nil {
+		t.Error("a malformed hash was accepted")
+	}
+}
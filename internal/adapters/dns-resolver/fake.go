@@ -0,0 +1,56 @@
+package dnsresolveradapter
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	dnsresolver "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/dns-resolver"
+)
+
+// FakeTxtResolver answers from records kept in memory, for local development where claimed domains cannot be published.
+type FakeTxtResolver struct {
+	mu      sync.RWMutex
+	records map[string][]string
+}
+
+// NewFakeTxtResolver serves the records, keyed by name
+func NewFakeTxtResolver(records map[string][]string) *FakeTxtResolver {
+	r := &FakeTxtResolver{records: make(map[string][]string)}
+	for name, values := range records {
+		r.Set(name, values...)
+	}
+	return r
+}
+
+// ParseFakeTxtRecords reads records written as "name=value;name=value", a name can be repeated for several values
+func ParseFakeTxtRecords(val string) map[string][]string {
+	records := make(map[string][]string)
+	for _, entry := range strings.Split(val, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" {
+			continue
+		}
+		records[name] = append(records[name], value)
+	}
+	return records
+}
+
+// Set replaces the TXT records of the name
+func (r *FakeTxtResolver) Set(name string, values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[normaliseName(name)] = values
+}
+
+func (r *FakeTxtResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.records[normaliseName(name)]...), nil
+}
+
+func normaliseName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+var _ dnsresolver.TxtResolver = (*FakeTxtResolver)(nil)
@@ -0,0 +1,27 @@
+package dnsresolveradapter
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	dnsresolver "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/dns-resolver"
+)
+
+// NetTxtResolver asks the system resolver.
+type NetTxtResolver struct {
+	resolver *net.Resolver
+}
+
+func NewNetTxtResolver() dnsresolver.TxtResolver {
+	return &NetTxtResolver{resolver: net.DefaultResolver}
+}
+
+func (r *NetTxtResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, err := r.resolver.LookupTXT(ctx, name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil, nil
+	}
+	return records, err
+}
@@ -0,0 +1,233 @@
+package documentadapter
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/document"
+)
+
+// maxPartSize caps how much of one file inside an xlsx is read, so a small upload cannot expand without limit
+const maxPartSize = 50 << 20
+
+var errInvalidXlsx = errors.New("the file is not a valid xlsx workbook")
+
+// SpreadsheetReader reads csv with encoding/csv and xlsx by reading the workbook xml straight from the zip,
+// only cell values are read, formulas come back as their cached result
+type SpreadsheetReader struct{}
+
+func NewSpreadsheetReader() document.SpreadsheetReader {
+	return &SpreadsheetReader{}
+}
+
+func (sr *SpreadsheetReader) ReadRows(ctx context.Context, content io.ReaderAt, size int64, format document.SpreadsheetFormat) ([][]string, error) {
+	switch format {
+	case document.CSV:
+		return readCsv(io.NewSectionReader(content, 0, size))
+	case document.XLSX:
+		return readXlsx(content, size)
+	}
+	return nil, document.ErrUnsupportedSpreadsheet
+}
+
+func readCsv(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// spreadsheet programs often save csv with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("the file is not valid csv: %w", err)
+	}
+	return rows, nil
+}
+
+type (
+	xlsxWorkbook struct {
+		Sheets []struct {
+			Id string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xlsxRelationships struct {
+		Relationships []struct {
+			Id     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	// xlsxText is a shared or inline string, either plain or split into rich text runs
+	xlsxText struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	}
+	xlsxSharedStrings struct {
+		Items []xlsxText `xml:"si"`
+	}
+	xlsxSheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+)
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var sb strings.Builder
+	for _, run := range t.Runs {
+		sb.WriteString(run.T)
+	}
+	return sb.String()
+}
+
+func readXlsx(content io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(content, size)
+	if err != nil {
+		return nil, errInvalidXlsx
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXlsxPart(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+	var sheet xlsxSheet
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, errInvalidXlsx
+	}
+	if err := decodeXlsxPart(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		var cells []string
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				if col, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			// empty cells are left out of the file, fill the gap
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, errInvalidXlsx
+				}
+				cells[col] = shared.Items[idx].String()
+			case "inlineStr":
+				cells[col] = cell.Inline.String()
+			default:
+				cells[col] = cell.Value
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// firstSheetPath follows the workbook relationships to the file of the first sheet
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	workbookFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errInvalidXlsx
+	}
+	var workbook xlsxWorkbook
+	if err := decodeXlsxPart(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("the workbook has no sheets")
+	}
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return "", errInvalidXlsx
+	}
+	var rels xlsxRelationships
+	if err := decodeXlsxPart(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.Id != workbook.Sheets[0].Id {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", errInvalidXlsx
+}
+
+func decodeXlsxPart(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return errInvalidXlsx
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxPartSize+1))
+	if err != nil {
+		return errInvalidXlsx
+	}
+	if len(data) > maxPartSize {
+		return errors.New("the workbook is too large")
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return errInvalidXlsx
+	}
+	return nil
+}
+
+// columnIndex turns the letters of a cell reference like "AB12" into a zero based column
+func columnIndex(ref string) (int, error) {
+	col := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		letters++
+	}
+	// xlsx has at most 16384 columns, XFD
+	if letters == 0 || letters > 3 || col > 16384 {
+		return 0, errInvalidXlsx
+	}
+	return col - 1, nil
+}
@@ -44,7 +44,6 @@ func (e *EmailNotificationService) sendMail(toEmails []string, subject string, b
 	message.SetBody("text/plain", body)
 
 	dialer := gomail.NewDialer(e.config.Host, e.config.Port, e.config.Username, e.config.Password)
-	e.logger.Info("WHAT ARE THOSE", e.config.Host, e.config.Port, e.config.Username, e.config.Password)
 
 	err := dialer.DialAndSend(message)
 	if err != nil {
@@ -0,0 +1,93 @@
+package filestorageadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	filestorage "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/file-storage"
+)
+
+// LocalFileStorage keeps files in a directory on disk, fine for a single node or a shared volume.
+// The files are served by Handler under baseUrl.
+type LocalFileStorage struct {
+	dir     string
+	baseUrl string
+}
+
+// NewLocalFileStorage creates the directory if it does not exist
+func NewLocalFileStorage(dir, baseUrl string) (*LocalFileStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating upload directory: %w", err)
+	}
+	return &LocalFileStorage{dir: dir, baseUrl: strings.TrimSuffix(baseUrl, "/")}, nil
+}
+
+var _ filestorage.FileStorage = (*LocalFileStorage)(nil)
+
+// Put writes to a temporary file first so a reader never sees a half written file
+func (s *LocalFileStorage) Put(ctx context.Context, key, contentType string, content io.Reader) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return s.baseUrl + "/" + key, nil
+}
+
+func (s *LocalFileStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Handler serves the stored files, directories are not listed
+func (s *LocalFileStorage) Handler() http.Handler {
+	files := http.FileServer(http.Dir(s.dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		files.ServeHTTP(w, r)
+	})
+}
+
+// path keeps keys inside the directory
+func (s *LocalFileStorage) path(key string) (string, error) {
+	if !filepath.IsLocal(key) {
+		return "", fmt.Errorf("invalid file key: %s", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
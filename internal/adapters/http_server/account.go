@@ -0,0 +1,151 @@
+package httpserver
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+type DeleteAccountPayload struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type RestoreAccountPayload struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (app *application) deleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	parentTraceCtx, span := app.trace.Start(r.Context(), "delete account")
+
+	defer span.End()
+
+	user, ok := getUserFromContext(parentTraceCtx)
+	if !ok {
+		err := errors.New("unable to retrieve user")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	var payload DeleteAccountPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error reading delete account payload as json", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error validating delete account payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := app.service.user.DeleteAccount(parentTraceCtx, user.Id, payload.Password); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("unable to delete account", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Your account has been scheduled for deletion. Please check your mail!", nil); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) restoreAccountHandler(w http.ResponseWriter, r *http.Request) {
+	parentTraceCtx, span := app.trace.Start(r.Context(), "restore account")
+
+	defer span.End()
+
+	var payload RestoreAccountPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error reading restore account payload as json", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error validating restore account payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	user, err := app.service.user.RestoreAccount(parentTraceCtx, payload.Email, payload.Password)
+	if err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("unable to restore account", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Account restored successfully. Proceed to login!", user); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) exportAccountDataHandler(w http.ResponseWriter, r *http.Request) {
+	parentTraceCtx, span := app.trace.Start(r.Context(), "export account data")
+
+	defer span.End()
+
+	user, ok := getUserFromContext(parentTraceCtx)
+	if !ok {
+		err := errors.New("unable to retrieve user")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	export, err := app.service.user.ExportUserData(parentTraceCtx, user.Id)
+	if err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("unable to export account data", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="assessmate-data-%s.zip"`, time.Now().UTC().Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+	if err := writeJsonZip(w, export.Files()); err != nil {
+		// headers are already out, all that is left is to log
+		app.logger.WithContext(parentTraceCtx).Error("Error writing account data export", err)
+	}
+}
+
+// writeJsonZip writes a zip archive with one indented json file per entry
+func writeJsonZip(w http.ResponseWriter, files map[string]any) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	archive := zip.NewWriter(w)
+	for _, name := range names {
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(files[name]); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,172 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	usermanagment "github.com/kaasikodes/assessmate_backend/internal/core/application/services/user-managment"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// requirePlatformAdmin must come after authMiddleware
+func (app *application) requirePlatformAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := getUserFromContext(r.Context())
+		if !ok {
+			app.unauthorizedErrorResponse(w, r, errors.New("unable to retrieve user"))
+			return
+		}
+		isAdmin, err := app.service.user.IsPlatformAdmin(r.Context(), user.Id)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if !isAdmin {
+			app.forbiddenResponse(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminUsersFilter reads the filter from the query string:
+// ?status=active&verified=true&search=jo&createdAfter=2025-01-01T00:00:00Z&createdBefore=...&page=1&pageSize=20
+func adminUsersFilter(r *http.Request) (*usermanagment.UserFilter, error) {
+	query := r.URL.Query()
+	filter := &usermanagment.UserFilter{Search: query.Get("search")}
+	if status := query.Get("status"); status != "" {
+		filter.Status = &status
+	}
+	if verified := query.Get("verified"); verified != "" {
+		v, err := strconv.ParseBool(verified)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verified: %w", err)
+		}
+		filter.IsVerified = &v
+	}
+	for name, dest := range map[string]**time.Time{"createdAfter": &filter.CreatedAfter, "createdBefore": &filter.CreatedBefore} {
+		if val := query.Get(name); val != "" {
+			t, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s, expected RFC3339: %w", name, err)
+			}
+			*dest = &t
+		}
+	}
+	for name, dest := range map[string]*int{"page": &filter.Page, "pageSize": &filter.PageSize} {
+		if val := query.Get(name); val != "" {
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dest = n
+		}
+	}
+	return filter, nil
+}
+
+func (app *application) adminErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, usermanagment.ErrNotPlatformAdmin) || errors.Is(err, auditlog.ErrImpersonated) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+	app.badRequestResponse(w, r, err)
+}
+
+func (app *application) adminListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "admin list users")
+	defer span.End()
+
+	admin, _ := getUserFromContext(ctx)
+	filter, err := adminUsersFilter(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	users, err := app.service.user.AdminGetUsers(ctx, admin.Id, filter)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to list users", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.adminErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Users retrieved successfully!", users); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// adminUserAction runs an action against the user in the {userId} url param
+func (app *application) adminUserAction(spanName, message string, action func(r *http.Request, adminId, userId int) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := app.trace.Start(r.Context(), spanName)
+		defer span.End()
+
+		admin, _ := getUserFromContext(ctx)
+		userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+		if err != nil {
+			app.badRequestResponse(w, r, errors.New("invalid user id"))
+			return
+		}
+		data, err := action(r.WithContext(ctx), admin.Id, userId)
+		if err != nil {
+			app.logger.WithContext(ctx).Error("unable to "+spanName, err)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			app.adminErrorResponse(w, r, err)
+			return
+		}
+
+		if err := app.jsonResponse(w, http.StatusOK, message, data); err != nil {
+			app.internalServerError(w, r, err)
+		}
+	}
+}
+
+func (app *application) adminGetUserHandler() http.HandlerFunc {
+	return app.adminUserAction("admin get user", "User retrieved successfully!", func(r *http.Request, adminId, userId int) (any, error) {
+		return app.service.user.AdminGetUser(r.Context(), adminId, userId)
+	})
+}
+
+func (app *application) adminSuspendUserHandler() http.HandlerFunc {
+	return app.adminUserAction("admin suspend user", "User suspended successfully!", func(r *http.Request, adminId, userId int) (any, error) {
+		return app.service.user.SuspendUser(r.Context(), adminId, userId)
+	})
+}
+
+func (app *application) adminReactivateUserHandler() http.HandlerFunc {
+	return app.adminUserAction("admin reactivate user", "User reactivated successfully!", func(r *http.Request, adminId, userId int) (any, error) {
+		return app.service.user.ReactivateUser(r.Context(), adminId, userId)
+	})
+}
+
+func (app *application) adminForcePasswordResetHandler() http.HandlerFunc {
+	return app.adminUserAction("admin force password reset", "Password reset forced, the user has been emailed a reset token!", func(r *http.Request, adminId, userId int) (any, error) {
+		return nil, app.service.user.ForcePasswordReset(r.Context(), adminId, userId)
+	})
+}
+
+func (app *application) adminResendVerificationHandler() http.HandlerFunc {
+	return app.adminUserAction("admin resend verification", "Verification token sent successfully!", func(r *http.Request, adminId, userId int) (any, error) {
+		return nil, app.service.user.AdminResendVerification(r.Context(), adminId, userId)
+	})
+}
+
+func (app *application) adminListUserSessionsHandler() http.HandlerFunc {
+	return app.adminUserAction("admin list user sessions", "Sessions retrieved successfully!", func(r *http.Request, adminId, userId int) (any, error) {
+		return app.service.user.AdminListSessions(r.Context(), adminId, userId)
+	})
+}
+
+func (app *application) adminImpersonateUserHandler() http.HandlerFunc {
+	return app.adminUserAction("admin impersonate user", "Impersonation token issued, every request made with it is audited!", func(r *http.Request, adminId, userId int) (any, error) {
+		return app.service.user.Impersonate(r.Context(), adminId, userId)
+	})
+}
@@ -0,0 +1,69 @@
+package httpserver
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi"
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func (app *application) getAnalyticsHandler() http.HandlerFunc {
+	return app.institutionAction("get institution analytics", "Analytics retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		return app.service.institution.GetAnalytics(r.Context(), institution.GetAnalyticsRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+		})
+	})
+}
+
+// exportAnalyticsHandler downloads one metric of the analytics as csv
+func (app *application) exportAnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "export institution analytics")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		app.unauthorizedErrorResponse(w, r, errors.New("unable to retrieve user"))
+		return
+	}
+	institutionId, err := tenantInstitutionId(ctx)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	metric := chi.URLParam(r, "metric")
+	if !slices.Contains(institution.AnalyticsMetrics, metric) {
+		app.notFoundResponse(w, r, institution.ErrUnknownAnalyticsMetric)
+		return
+	}
+	analytics, err := app.service.institution.GetAnalytics(ctx, institution.GetAnalyticsRequest{
+		ActorId:       user.Id,
+		InstitutionId: institutionId,
+	})
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to export analytics", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.institutionErrorResponse(w, r, err)
+		return
+	}
+	rows, err := analytics.Table(metric)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.csv"`, metric, analytics.GeneratedAt.Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		// headers are already out, all that is left is to log
+		app.logger.WithContext(ctx).Error("Error writing analytics export", err)
+	}
+}
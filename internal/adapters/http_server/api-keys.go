@@ -0,0 +1,129 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	usermanagment "github.com/kaasikodes/assessmate_backend/internal/core/application/services/user-managment"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type CreateApiKeyPayload struct {
+	Name          string     `json:"name" validate:"required,max=100"`
+	Scopes        []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	InstitutionId *int       `json:"institutionId" validate:"omitempty,min=1"`
+}
+
+func (app *application) createApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "create api key")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		err := errors.New("unable to retrieve user")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	var payload CreateApiKeyPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.logger.WithContext(ctx).Error("Error reading api key payload as json", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.logger.WithContext(ctx).Error("Error validating api key payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	key, err := app.service.user.CreateApiKey(ctx, user.Id, payload.Name, payload.Scopes, payload.ExpiresAt, payload.InstitutionId)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to create api key", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, usermanagment.ErrNotInstitutionAdmin) {
+			app.forbiddenResponse(w, r)
+			return
+		}
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, "Api key created, copy it now as it will not be shown again!", key); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) listApiKeysHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "list api keys")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		err := errors.New("unable to retrieve user")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	keys, err := app.service.user.ListApiKeys(ctx, user.Id)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to list api keys", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Api keys retrieved successfully!", keys); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) revokeApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "revoke api key")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		err := errors.New("unable to retrieve user")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	keyId, err := strconv.Atoi(chi.URLParam(r, "keyId"))
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid api key id"))
+		return
+	}
+
+	err = app.service.user.RevokeApiKey(ctx, user.Id, keyId)
+	if errors.Is(err, user_repo.ErrApiKeyNotFound) {
+		app.notFoundResponse(w, r, err)
+		return
+	}
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to revoke api key", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Api key revoked successfully!", nil); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
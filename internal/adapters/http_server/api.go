@@ -1,24 +1,43 @@
 package httpserver
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/cors"
 	"github.com/kaasikodes/assessmate_backend/env"
+	breachedpasswordadapter "github.com/kaasikodes/assessmate_backend/internal/adapters/breached-password"
+	dnsresolveradapter "github.com/kaasikodes/assessmate_backend/internal/adapters/dns-resolver"
+	documentadapter "github.com/kaasikodes/assessmate_backend/internal/adapters/document"
 	email_adapter "github.com/kaasikodes/assessmate_backend/internal/adapters/email"
+	filestorageadapter "github.com/kaasikodes/assessmate_backend/internal/adapters/file-storage"
 	jwttoken "github.com/kaasikodes/assessmate_backend/internal/adapters/jwt"
 	log_adapter "github.com/kaasikodes/assessmate_backend/internal/adapters/logger"
+	loginattemptadapter "github.com/kaasikodes/assessmate_backend/internal/adapters/login-attempt"
 	randomadapter "github.com/kaasikodes/assessmate_backend/internal/adapters/random"
+	ratelimiteradapter "github.com/kaasikodes/assessmate_backend/internal/adapters/rate-limiter"
 	"github.com/kaasikodes/assessmate_backend/internal/adapters/store"
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
 	usermanagment "github.com/kaasikodes/assessmate_backend/internal/core/application/services/user-managment"
+	domaininstitution "github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
 	"github.com/kaasikodes/assessmate_backend/internal/db"
+	breachedpassword "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/breached-password"
+	dnsresolver "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/dns-resolver"
 	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
+	filestorage "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/file-storage"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
 	jwtport "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/jwt"
 	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/logger"
+	loginattempt "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/login-attempt"
 	randomidgenerator "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/random-id-generator"
+	ratelimiter "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/rate-limiter"
 	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -27,12 +46,29 @@ import (
 )
 
 type config struct {
-	addr        string
-	grpcAddr    string
-	db          dbConfig
-	env         string
-	apiURL      string
-	frontendUrl string
+	addr              string
+	grpcAddr          string
+	db                dbConfig
+	env               string
+	apiURL            string
+	frontendUrl       string
+	loginAttemptStore string       // memory(single node) or sql(multiple replicas)
+	dnsResolver       string       // net, or fake for local development where claimed domains cannot be published
+	dnsFakeRecords    string       // the records the fake resolver serves, "name=value;name=value"
+	trustedProxies    []*net.IPNet // the forwarded client ip is only read from requests coming through these
+	password          passwordConfig
+	uploads           uploadsConfig
+}
+
+type uploadsConfig struct {
+	dir     string
+	baseUrl string // public url the files in dir are served from
+}
+
+type passwordConfig struct {
+	minLength    int
+	minStrength  int
+	breachedFile string // local breached password list, the check is skipped when empty
 }
 
 type dbConfig struct {
@@ -48,12 +84,24 @@ type application struct {
 	jwt     jwtport.JwtMaker
 	trace   trace.Tracer
 
+	rateLimiter ratelimiter.RateLimiter
+	// serves uploaded files, e.g. avatars
+	uploads http.Handler
+
 	// service
 	service Service
 }
 
 type Service struct {
-	user usermanagment.UserManagementService
+	user        usermanagment.UserManagementService
+	audit       *auditlog.AuditLogService
+	institution *institution.InstitutionManagementService
+	// tenants is the institution service as resolveTenant uses it
+	tenants tenantResolver
+}
+
+type tenantResolver interface {
+	ResolveTenant(ctx context.Context, actorId, institutionId int) (*domaininstitution.Tenant, error)
 }
 
 func (app *application) mount(reg *prometheus.Registry) http.Handler {
@@ -71,29 +119,37 @@ func (app *application) mount(reg *prometheus.Registry) http.Handler {
 	}
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   allowedOrigins, // use "*" to allow all
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", requestIdHeader},
+		ExposedHeaders:   []string{"Link", "Retry-After", requestIdHeader, impersonatedByHeader, "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy"},
 		AllowCredentials: allowCredentials,
 		MaxAge:           300, // Maximum value not ignored by major browsers //TODO: Find out what does this really mean
 	}))
 	// Add the metrics middleware
 	r.Use(app.metricsMiddleware)
+	r.Use(app.requestMetadataMiddleware)
 
 	r.Get("/healthz", app.healthzHandler)
+	r.Get("/.well-known/jwks.json", app.jwksHandler)
+	r.Handle("/uploads/*", http.StripPrefix("/uploads/", app.uploads))
 	r.Get("/metrics", func(w http.ResponseWriter, r *http.Request) {
 		promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}).ServeHTTP(w, r)
 		// promhttp.Handler().ServeHTTP(w, r)
 	})
 	r.Route("/v1", func(r chi.Router) {
 		r.Route("/auth", func(r chi.Router) {
-			// TODO: add rate limiting for auth required endpoints to prevent abuse
-			r.Post("/register", app.registerHandler) // customer(happy path), vendor
-			r.Post("/verify", app.verifyHandler)
-			r.Post("/login", app.loginHandler)
-			r.Post("/forgot-password", app.forgotPasswordHandler)
-			r.Post("/reset-password", app.resetPasswordHandler)
-			r.Post("/resend-verification", app.resendVerificationHandler)
+			r.With(app.rateLimit(registerRateLimit, app.keyByIP)).Post("/register", app.registerHandler) // customer(happy path), vendor
+			r.With(app.rateLimit(verifyRateLimit, app.keyByIP)).Post("/verify", app.verifyHandler)
+			r.With(app.rateLimit(loginRateLimit, app.keyByIP)).Post("/login", app.loginHandler)
+			r.With(app.rateLimit(forgotPasswordRateLimit, app.keyByIP)).Post("/forgot-password", app.forgotPasswordHandler)
+			r.With(app.rateLimit(resetPasswordLimit, app.keyByIP)).Post("/reset-password", app.resetPasswordHandler)
+			r.With(app.rateLimit(resendVerificationRateLimit, app.keyByIP)).Post("/resend-verification", app.resendVerificationHandler)
+			r.With(app.rateLimit(refreshRateLimit, app.keyByIP)).Post("/refresh", app.refreshTokenHandler)
+			r.With(app.rateLimit(confirmEmailChangeRateLimit, app.keyByIP)).Post("/email-change/confirm", app.confirmEmailChangeHandler)
+			r.With(app.rateLimit(revertEmailChangeRateLimit, app.keyByIP)).Post("/email-change/revert", app.revertEmailChangeHandler)
+			r.With(app.rateLimit(restoreAccountRateLimit, app.keyByIP)).Post("/account/restore", app.restoreAccountHandler)
+			r.With(app.rateLimit(magicLinkRateLimit, app.keyByIP)).Post("/magic-link", app.requestMagicLinkHandler)
+			r.With(app.rateLimit(magicLinkLoginRateLimit, app.keyByIP)).Post("/magic-link/login", app.magicLinkLoginHandler)
 			// oauth providers
 			// r.Route("/oauth", func(r chi.Router) {
 			// 	r.Get("/github/login", app.githubOauthLoginHandler)
@@ -102,15 +158,151 @@ func (app *application) mount(reg *prometheus.Registry) http.Handler {
 
 			r.Group(func(r chi.Router) {
 				r.Use(app.authMiddleware)
-				r.Get("/me", app.retriveAuthAccountHandler)
+				r.Use(app.rateLimit(authenticatedLimit, app.keyByApiKey))
+				r.With(app.requireScope(user.ScopeAccountRead)).Get("/me", app.retriveAuthAccountHandler)
+
+				r.Group(func(r chi.Router) {
+					r.Use(app.requireSession)
+					r.Get("/sessions", app.listSessionsHandler)
+					r.Delete("/sessions/{sessionId}", app.revokeSessionHandler)
+					r.Get("/account/export", app.exportAccountDataHandler)
+					r.Get("/profile", app.getProfileHandler)
+					r.Patch("/profile", app.updateProfileHandler)
+					r.With(app.rateLimit(uploadRateLimit, app.keyByUser)).Put("/profile/avatar", app.uploadAvatarHandler)
+					r.Delete("/profile/avatar", app.removeAvatarHandler)
+					r.Get("/api-keys", app.listApiKeysHandler)
+
+					// actions an impersonating admin must not take for the user
+					r.Group(func(r chi.Router) {
+						r.Use(app.forbidImpersonation)
+						r.With(app.rateLimit(emailChangeRateLimit, app.keyByUser)).Post("/email-change", app.requestEmailChangeHandler)
+						r.Delete("/account", app.deleteAccountHandler)
+						r.Post("/api-keys", app.createApiKeyHandler)
+						r.Delete("/api-keys/{keyId}", app.revokeApiKeyHandler)
+						r.Post("/switch-institution", app.switchInstitutionHandler)
+					})
+				})
 			})
 		})
 
+		// platform administration
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(app.authMiddleware)
+			r.Use(app.requireSession)
+			r.Use(app.requirePlatformAdmin)
+			r.Use(app.rateLimit(authenticatedLimit, app.keyByUser))
+			r.Get("/users", app.adminListUsersHandler)
+			r.Get("/users/{userId}", app.adminGetUserHandler())
+			r.Get("/users/{userId}/sessions", app.adminListUserSessionsHandler())
+			r.Post("/users/{userId}/suspend", app.adminSuspendUserHandler())
+			r.Post("/users/{userId}/reactivate", app.adminReactivateUserHandler())
+			r.Post("/users/{userId}/force-password-reset", app.adminForcePasswordResetHandler())
+			r.Post("/users/{userId}/resend-verification", app.adminResendVerificationHandler())
+			r.Post("/users/{userId}/impersonate", app.adminImpersonateUserHandler())
+			r.Get("/audit-logs", app.adminListAuditLogsHandler)
+		})
+
+		r.With(app.rateLimit(acceptInviteRateLimit, app.keyByIP)).Post("/invites/accept", app.acceptInviteHandler)
+		r.With(app.rateLimit(confirmOwnershipTransferRateLimit, app.keyByIP)).Post("/ownership-transfers/confirm", app.confirmOwnershipTransferHandler)
+		r.With(app.rateLimit(confirmEmailDomainRateLimit, app.keyByIP)).Post("/email-domains/confirm", app.confirmEmailDomainHandler)
+
+		// api keys reach the routes their scopes cover, everything else needs the user signed in
+		r.Route("/institutions", func(r chi.Router) {
+			r.Use(app.authMiddleware)
+			r.Use(app.rateLimit(authenticatedLimit, app.keyByApiKey))
+			r.With(app.requireSession).Post("/", app.createInstitutionHandler)
+			// the institution comes from the X-Institution-Id header or the token from switching institution
+			r.Route("/current", app.institutionRoutes)
+			r.Route("/{institutionId}", app.institutionRoutes)
+		})
+
 	})
 
 	return r
 
 }
+
+// institutionRoutes are the routes scoped to one institution by resolveTenant
+func (app *application) institutionRoutes(r chi.Router) {
+	r.Use(app.resolveTenant)
+
+	// running the institution itself, no api key scope covers these
+	r.Group(func(r chi.Router) {
+		r.Use(app.requireSession)
+		r.Get("/", app.getInstitutionHandler())
+		r.Patch("/", app.updateInstitutionHandler())
+		r.With(app.forbidImpersonation).Delete("/", app.deleteInstitutionHandler())
+		r.Post("/staff/{staffId}/role", app.changeStaffRoleHandler())
+		r.Get("/ownership-transfer", app.getOwnershipTransferHandler())
+		r.With(app.forbidImpersonation, app.rateLimit(ownershipTransferRateLimit, app.keyByUser)).Post("/ownership-transfer", app.startOwnershipTransferHandler())
+		r.Delete("/ownership-transfer", app.cancelOwnershipTransferHandler())
+		r.Get("/email-domains", app.listEmailDomainsHandler())
+		r.With(app.rateLimit(emailDomainClaimRateLimit, app.keyByUser)).Post("/email-domains", app.claimEmailDomainHandler())
+		r.Post("/email-domains/{domainId}/verify", app.verifyEmailDomainHandler())
+		r.Patch("/email-domains/{domainId}", app.updateEmailDomainHandler())
+		r.Delete("/email-domains/{domainId}", app.removeEmailDomainHandler())
+		r.Get("/audit-logs", app.institutionAuditLogsHandler)
+		r.Get("/analytics", app.getAnalyticsHandler())
+		r.Get("/analytics/{metric}/export", app.exportAnalyticsHandler)
+	})
+
+	// staff, the groups they are in and inviting them
+	r.Group(func(r chi.Router) {
+		r.Use(app.requireScope(user.ScopeRosterRead))
+		r.Get("/staff", app.listInstitutionStaffHandler())
+		r.Get("/groups", app.listGroupsHandler())
+		r.Get("/groups/{groupId}", app.getGroupHandler())
+		r.Get("/categories/{categoryId}/staff", app.listCategoryStaffHandler())
+		r.Get("/invites", app.listInvitesHandler())
+		r.Get("/roster-imports/{importId}", app.getRosterImportHandler())
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(app.requireScope(user.ScopeRosterWrite))
+		r.Post("/staff", app.addInstitutionStaffHandler())
+		r.Delete("/staff/{staffId}", app.removeInstitutionStaffHandler())
+		r.Post("/staff/{staffId}/status", app.changeStaffStatusHandler())
+		r.Post("/staff/{staffId}/restore", app.restoreStaffHandler())
+		r.Post("/groups", app.createGroupHandler())
+		r.Patch("/groups/{groupId}", app.updateGroupHandler())
+		r.Delete("/groups/{groupId}", app.deleteGroupHandler())
+		r.Post("/groups/{groupId}/staff", app.addGroupStaffHandler())
+		r.Delete("/groups/{groupId}/staff/{staffId}", app.removeGroupStaffHandler())
+		r.Put("/categories/{categoryId}/staff/{staffId}", app.addCategoryStaffHandler())
+		r.Delete("/categories/{categoryId}/staff/{staffId}", app.removeCategoryStaffHandler())
+		r.With(app.rateLimit(inviteRateLimit, app.keyByUser)).Post("/invites", app.inviteStaffHandler())
+		r.With(app.rateLimit(resendInviteRateLimit, app.keyByUser)).Post("/invites/{inviteId}/resend", app.resendInviteHandler())
+		r.Delete("/invites/{inviteId}", app.revokeInviteHandler())
+		r.With(app.rateLimit(rosterImportRateLimit, app.keyByUser)).Post("/roster-imports", app.importRosterHandler)
+	})
+
+	// courses, the categories they are filed under and which groups can access them
+	r.Group(func(r chi.Router) {
+		r.Use(app.requireScope(user.ScopeCoursesRead))
+		r.Get("/courses", app.listCoursesHandler())
+		r.Get("/courses/{courseId}", app.getCourseHandler())
+		r.Get("/courses/{courseId}/access", app.courseAccessHandler())
+		r.Get("/groups/{groupId}/courses", app.listGroupCoursesHandler())
+		r.Get("/categories", app.categoryTreeHandler())
+		r.Get("/categories/{categoryId}/courses", app.listCategoryCoursesHandler())
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(app.requireScope(user.ScopeCoursesWrite))
+		r.Post("/courses", app.createCourseHandler())
+		r.Patch("/courses/{courseId}", app.updateCourseHandler())
+		r.Delete("/courses/{courseId}", app.deleteCourseHandler())
+		r.Put("/courses/{courseId}/category", app.setCourseCategoryHandler())
+		r.Put("/groups/{groupId}/courses/{courseId}", app.grantGroupCourseHandler())
+		r.Delete("/groups/{groupId}/courses/{courseId}", app.revokeGroupCourseHandler())
+		r.Post("/categories", app.createCategoryHandler())
+		r.Patch("/categories/{categoryId}", app.renameCategoryHandler())
+		r.Post("/categories/{categoryId}/move", app.moveCategoryHandler())
+		r.Delete("/categories/{categoryId}", app.deleteCategoryHandler())
+	})
+
+	// the courses the caller can set assessments for
+	r.With(app.requireScope(user.ScopeAssessmentsRead)).Get("/courses/assessable", app.listAssessableCoursesHandler())
+}
+
 func (app *application) run(mux http.Handler) error {
 
 	server := &http.Server{
@@ -133,20 +325,65 @@ func (app *application) run(mux http.Handler) error {
 	return nil
 
 }
-func createUserMgtService(repo user_repo.UserRepository, jwt jwtport.JwtMaker, emailClient email.EmailClient, logger logger.Logger, randIdGen randomidgenerator.RandomIdGenerator) (*usermanagment.UserManagementService, error) {
+func createUserMgtService(repo user_repo.UserRepository, jwt jwtport.JwtMaker, emailClient email.EmailClient, logger logger.Logger, randIdGen randomidgenerator.RandomIdGenerator, loginAttempts loginattempt.LoginAttemptStore, passwordCfg passwordConfig, audit *auditlog.AuditLogService, files filestorage.FileStorage, memberships institute_repo.MembershipReader, domainJoins institute_repo.EmailDomainJoiner, personalData institute_repo.PersonalDataReader) (*usermanagment.UserManagementService, error) {
+	policy := user.DefaultPasswordPolicy
+	policy.MinLength = passwordCfg.minLength
+	policy.MinStrength = passwordCfg.minStrength
 
-	service := usermanagment.NewUserManagementService(repo, jwt, emailClient, logger, randIdGen)
+	var breachedPasswords breachedpassword.BreachedPasswordStore
+	if passwordCfg.breachedFile != "" {
+		fileStore, err := breachedpasswordadapter.NewFileBreachedPasswordStore(passwordCfg.breachedFile)
+		if err != nil {
+			return nil, err
+		}
+		breachedPasswords = fileStore
+	}
+
+	service := usermanagment.NewUserManagementService(repo, jwt, emailClient, logger, randIdGen, loginAttempts, policy, breachedPasswords, audit, files, memberships, domainJoins, personalData)
 	return service, nil
 
 }
+
+func createLoginAttemptStore(kind string, persistentStorage store.StoreCombinedRepository) (loginattempt.LoginAttemptStore, error) {
+	switch kind {
+	case "memory":
+		return loginattemptadapter.NewMemoryLoginAttemptStore(), nil
+	case "sql":
+		return persistentStorage, nil
+	default:
+		return nil, fmt.Errorf("unknown login attempt store: %s", kind)
+	}
+}
+func createTxtResolver(kind, fakeRecords string) (dnsresolver.TxtResolver, error) {
+	switch kind {
+	case "net":
+		return dnsresolveradapter.NewNetTxtResolver(), nil
+	case "fake":
+		return dnsresolveradapter.NewFakeTxtResolver(dnsresolveradapter.ParseFakeTxtRecords(fakeRecords)), nil
+	default:
+		return nil, fmt.Errorf("unknown dns resolver: %s", kind)
+	}
+}
 func Start() error {
 
 	cfg := config{
-		grpcAddr:    env.GetString("GRPC_ADDR", ":4010"),
-		addr:        env.GetString("ADDR", ":3010"),
-		apiURL:      env.GetString("API_URL", "localhost:9010"),
-		frontendUrl: env.GetString("FRONTEND_URL", "localhost:3000"),
-		env:         env.GetString("ENV", "development"),
+		grpcAddr:          env.GetString("GRPC_ADDR", ":4010"),
+		addr:              env.GetString("ADDR", ":3010"),
+		apiURL:            env.GetString("API_URL", "localhost:9010"),
+		frontendUrl:       env.GetString("FRONTEND_URL", "localhost:3000"),
+		env:               env.GetString("ENV", "development"),
+		loginAttemptStore: env.GetString("LOGIN_ATTEMPT_STORE", "memory"),
+		dnsResolver:       env.GetString("DNS_RESOLVER", "net"),
+		dnsFakeRecords:    env.GetString("DNS_FAKE_TXT_RECORDS", ""),
+		password: passwordConfig{
+			minLength:    env.GetInt("PASSWORD_MIN_LENGTH", user.DefaultPasswordPolicy.MinLength),
+			minStrength:  env.GetInt("PASSWORD_MIN_STRENGTH", user.DefaultPasswordPolicy.MinStrength),
+			breachedFile: env.GetString("BREACHED_PASSWORDS_FILE", ""),
+		},
+		uploads: uploadsConfig{
+			dir:     env.GetString("UPLOADS_DIR", "uploads"),
+			baseUrl: env.GetString("UPLOADS_BASE_URL", "/uploads"),
+		},
 		db: dbConfig{
 			addr:         env.GetString("DB_ADDR", ""),
 			maxOpenConns: env.GetInt("DB_MAX_OPEN_CONNS", 30),
@@ -171,7 +408,11 @@ func Start() error {
 
 	//jwt
 
-	jwt := jwttoken.NewJwtMaker(env.GetString("JWT_SECRET", ""))
+	// every *.pem in the directory verifies tokens, JWT_SIGNING_KID picks the one that signs (the last by name when unset)
+	jwt, err := jwttoken.NewJwtMaker(env.GetString("JWT_KEYS_DIR", ""), env.GetString("JWT_SIGNING_KID", ""))
+	if err != nil {
+		return fmt.Errorf("error loading jwt keys: %w", err)
+	}
 	//email
 	email := email_adapter.NewEmailNotificationService(email_adapter.MailConfig{
 		Host:      env.GetString("SMTP_HOST", "sandbox.smtp.mailtrap.io"),
@@ -186,23 +427,56 @@ func Start() error {
 		logger.Fatal(err)
 	}
 	defer db.Close()
-	persistentStorage := store.NewUserRepository(db, logger)
+	tokenHashKey := env.GetString("TOKEN_HASH_KEY", "")
+	if tokenHashKey == "" {
+		return errors.New("TOKEN_HASH_KEY must be set")
+	}
+	persistentStorage := store.NewUserRepository(db, logger, []byte(tokenHashKey))
 	//randIdGen
 	randIdGen := randomadapter.NewRandomIdAdapter()
+	loginAttempts, err := createLoginAttemptStore(cfg.loginAttemptStore, persistentStorage)
+	if err != nil {
+		return err
+	}
+	cfg.trustedProxies, err = parseTrustedProxies(env.GetString("TRUSTED_PROXIES", ""))
+	if err != nil {
+		return err
+	}
+	txtResolver, err := createTxtResolver(cfg.dnsResolver, cfg.dnsFakeRecords)
+	if err != nil {
+		return err
+	}
+	//uploads
+	fileStorage, err := filestorageadapter.NewLocalFileStorage(cfg.uploads.dir, cfg.uploads.baseUrl)
+	if err != nil {
+		return err
+	}
 	// service
-	userMgtService, err := createUserMgtService(persistentStorage, jwt, email, logger, randIdGen)
+	auditLogService := auditlog.NewAuditLogService(persistentStorage, logger)
+	userMgtService, err := createUserMgtService(persistentStorage, jwt, email, logger, randIdGen, loginAttempts, cfg.password, auditLogService, fileStorage, persistentStorage, persistentStorage, persistentStorage)
 	if err != nil {
 		return fmt.Errorf("error creating user management service: %w", err)
 	}
 
+	institutionService := institution.NewInstitutionManagementService(store.NewTenantScopedRepository(persistentStorage), email, logger, randIdGen, userMgtService, documentadapter.NewSpreadsheetReader(), auditLogService, txtResolver)
+
+	userMgtService.StartSessionActivityFlusher(context.Background(), sessionActivityFlushInterval)
+	userMgtService.StartAccountPurger(context.Background(), accountPurgeInterval)
+
 	app := &application{
 		config:  cfg,
 		logger:  logger,
 		metrics: metrics,
 		trace:   tracing,
 		jwt:     jwt,
+
+		rateLimiter: ratelimiteradapter.NewTokenBucketLimiter(),
+		uploads:     fileStorage.Handler(),
 		service: Service{
-			user: *userMgtService,
+			user:        *userMgtService,
+			audit:       auditLogService,
+			institution: institutionService,
+			tenants:     institutionService,
 		},
 	}
 	mux := app.mount(metricsReg)
@@ -0,0 +1,152 @@
+package httpserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	requestIdHeader = "X-Request-Id"
+	// set on responses to requests made with an impersonation token
+	impersonatedByHeader = "X-Impersonated-By"
+)
+
+// a request id from a proxy is kept as long as it cannot be used to inject anything into the log
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestMetadataMiddleware puts what the audit log records about the caller on the context, authMiddleware adds the actor
+func (app *application) requestMetadataMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(requestIdHeader)
+		if !validRequestId.MatchString(requestId) {
+			requestId = newRequestId()
+		}
+		w.Header().Set(requestIdHeader, requestId)
+
+		ctx := auditlog.WithRequestMetadata(r.Context(), &auditlog.RequestMetadata{
+			Ip:        app.clientIP(r),
+			UserAgent: r.UserAgent(),
+			RequestId: requestId,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// auditFilter reads the filter from the query string:
+// ?actorId=1&impersonatorId=3&action=user.suspend&targetType=user&targetId=2&from=2025-01-01T00:00:00Z&to=...&page=1&pageSize=20
+func auditFilter(r *http.Request) (*auditlog.Filter, error) {
+	query := r.URL.Query()
+	filter := &auditlog.Filter{
+		Action:     query.Get("action"),
+		TargetType: query.Get("targetType"),
+		TargetId:   query.Get("targetId"),
+	}
+	for name, dest := range map[string]**int{"actorId": &filter.ActorId, "impersonatorId": &filter.ImpersonatorId, "institutionId": &filter.InstitutionId} {
+		if val := query.Get(name); val != "" {
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dest = &n
+		}
+	}
+	for name, dest := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if val := query.Get(name); val != "" {
+			t, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s, expected RFC3339: %w", name, err)
+			}
+			*dest = &t
+		}
+	}
+	for name, dest := range map[string]*int{"page": &filter.Page, "pageSize": &filter.PageSize} {
+		if val := query.Get(name); val != "" {
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dest = n
+		}
+	}
+	return filter, nil
+}
+
+// adminListAuditLogsHandler lets platform admins query the whole log
+func (app *application) adminListAuditLogsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "admin list audit logs")
+	defer span.End()
+
+	filter, err := auditFilter(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	entries, err := app.service.audit.GetEntries(ctx, filter)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to list audit logs", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Audit logs retrieved successfully!", entries); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// institutionAuditLogsHandler lets institution admins query what happened within their institution
+func (app *application) institutionAuditLogsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "institution list audit logs")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		app.unauthorizedErrorResponse(w, r, errors.New("unable to retrieve user"))
+		return
+	}
+	institutionId, err := tenantInstitutionId(ctx)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	filter, err := auditFilter(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	entries, err := app.service.institution.GetAuditTrail(ctx, user.Id, institutionId, filter)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to list audit logs", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, institution.ErrNotInstitutionAdmin) {
+			app.forbiddenResponse(w, r)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Audit logs retrieved successfully!", entries); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
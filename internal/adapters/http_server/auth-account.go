@@ -21,7 +21,15 @@ func (app *application) retriveAuthAccountHandler(w http.ResponseWriter, r *http
 		return
 
 	}
-	if err := app.jsonResponse(w, http.StatusOK, "Authenticated user retrieved successfully!", user); err != nil {
+	authUser, err := app.service.user.GetAuthUser(ctx, user.Email)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("Retrieving authenticated user", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, "Authenticated user retrieved successfully!", authUser); err != nil {
 		app.internalServerError(w, r, err)
 	}
 
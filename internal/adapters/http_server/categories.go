@@ -0,0 +1,177 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
+)
+
+type CreateCategoryPayload struct {
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Kind     string `json:"kind" validate:"required,oneof=faculty department"`
+	ParentId *int   `json:"parentId" validate:"omitempty,min=1"`
+}
+
+type RenameCategoryPayload struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+}
+
+// MoveCategoryPayload, leaving out parentId moves the category to the top level
+type MoveCategoryPayload struct {
+	ParentId *int `json:"parentId" validate:"omitempty,min=1"`
+}
+
+// SetCourseCategoryPayload, leaving out categoryId takes the course out of its category
+type SetCourseCategoryPayload struct {
+	CategoryId *int `json:"categoryId" validate:"omitempty,min=1"`
+}
+
+func (app *application) categoryTreeHandler() http.HandlerFunc {
+	return app.institutionAction("get category tree", "Categories retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		return app.service.institution.GetCategoryTree(r.Context(), actorId, institutionId)
+	})
+}
+
+func (app *application) createCategoryHandler() http.HandlerFunc {
+	return app.institutionAction("create category", "Category created successfully!", http.StatusCreated, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		var payload CreateCategoryPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.CreateCategory(r.Context(), institution.CreateCategoryRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			Name:          payload.Name,
+			Kind:          payload.Kind,
+			ParentId:      payload.ParentId,
+		})
+	})
+}
+
+func (app *application) renameCategoryHandler() http.HandlerFunc {
+	return app.institutionAction("rename category", "Category renamed successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		categoryId, err := urlParamId(r, "categoryId")
+		if err != nil {
+			return nil, err
+		}
+		var payload RenameCategoryPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.RenameCategory(r.Context(), institution.RenameCategoryRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			CategoryId:    categoryId,
+			Name:          payload.Name,
+		})
+	})
+}
+
+func (app *application) moveCategoryHandler() http.HandlerFunc {
+	return app.institutionAction("move category", "Category moved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		categoryId, err := urlParamId(r, "categoryId")
+		if err != nil {
+			return nil, err
+		}
+		var payload MoveCategoryPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.MoveCategory(r.Context(), institution.MoveCategoryRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			CategoryId:    categoryId,
+			ParentId:      payload.ParentId,
+		})
+	})
+}
+
+// deleteCategoryHandler, ?reparent=true moves the sub categories up instead of refusing the delete
+func (app *application) deleteCategoryHandler() http.HandlerFunc {
+	return app.institutionAction("delete category", "Category deleted successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		categoryId, err := urlParamId(r, "categoryId")
+		if err != nil {
+			return nil, err
+		}
+		reparent := false
+		if val := r.URL.Query().Get("reparent"); val != "" {
+			if reparent, err = strconv.ParseBool(val); err != nil {
+				return nil, fmt.Errorf("invalid reparent: %w", err)
+			}
+		}
+		return nil, app.service.institution.DeleteCategory(r.Context(), actorId, institutionId, categoryId, reparent)
+	})
+}
+
+func (app *application) listCategoryStaffHandler() http.HandlerFunc {
+	return app.institutionAction("list category staff", "Staff retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		categoryId, err := urlParamId(r, "categoryId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.GetCategoryStaff(r.Context(), actorId, institutionId, categoryId)
+	})
+}
+
+func (app *application) addCategoryStaffHandler() http.HandlerFunc {
+	return app.institutionAction("add category staff", "Staff added to category successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		req, err := categoryStaffRequest(r, actorId, institutionId)
+		if err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.AddStaffToCategory(r.Context(), req)
+	})
+}
+
+func (app *application) removeCategoryStaffHandler() http.HandlerFunc {
+	return app.institutionAction("remove category staff", "Staff removed from category successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		req, err := categoryStaffRequest(r, actorId, institutionId)
+		if err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.RemoveStaffFromCategory(r.Context(), req)
+	})
+}
+
+func (app *application) listCategoryCoursesHandler() http.HandlerFunc {
+	return app.institutionAction("list category courses", "Courses retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		categoryId, err := urlParamId(r, "categoryId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.GetCategoryCourses(r.Context(), actorId, institutionId, categoryId)
+	})
+}
+
+func (app *application) setCourseCategoryHandler() http.HandlerFunc {
+	return app.institutionAction("set course category", "Course category updated successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		courseId, err := urlParamId(r, "courseId")
+		if err != nil {
+			return nil, err
+		}
+		var payload SetCourseCategoryPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.SetCourseCategory(r.Context(), institution.SetCourseCategoryRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			CourseId:      courseId,
+			CategoryId:    payload.CategoryId,
+		})
+	})
+}
+
+func categoryStaffRequest(r *http.Request, actorId, institutionId int) (institution.CategoryStaffRequest, error) {
+	categoryId, err := urlParamId(r, "categoryId")
+	if err != nil {
+		return institution.CategoryStaffRequest{}, err
+	}
+	staffId, err := urlParamId(r, "staffId")
+	if err != nil {
+		return institution.CategoryStaffRequest{}, err
+	}
+	return institution.CategoryStaffRequest{ActorId: actorId, InstitutionId: institutionId, CategoryId: categoryId, StaffId: staffId}, nil
+}
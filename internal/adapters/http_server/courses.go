@@ -0,0 +1,157 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
+)
+
+type CreateCoursePayload struct {
+	Name        string `json:"name" validate:"required,min=3,max=100"`
+	Description string `json:"description" validate:"required,min=150,max=800"`
+}
+
+// UpdateCoursePayload changes only the fields that are sent
+type UpdateCoursePayload struct {
+	Name        *string `json:"name" validate:"omitempty,min=3,max=100"`
+	Description *string `json:"description" validate:"omitempty,min=150,max=800"`
+}
+
+// listCoursesHandler lists a page of the courses, ?categoryId= only those under the category
+func (app *application) listCoursesHandler() http.HandlerFunc {
+	return app.institutionAction("list courses", "Courses retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		pagination, err := readPagination(r)
+		if err != nil {
+			return nil, err
+		}
+		categoryId, err := queryId(r, "categoryId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.GetCourses(r.Context(), institution.ListCoursesRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			CategoryId:    categoryId,
+			Pagination:    pagination,
+		})
+	})
+}
+
+// listAssessableCoursesHandler lists the courses the user may create assessments in, ?categoryId= only those under the category
+func (app *application) listAssessableCoursesHandler() http.HandlerFunc {
+	return app.institutionAction("list assessable courses", "Courses retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		categoryId, err := queryId(r, "categoryId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.GetAssessableCourses(r.Context(), actorId, institutionId, categoryId)
+	})
+}
+
+func (app *application) createCourseHandler() http.HandlerFunc {
+	return app.institutionAction("create course", "Course created successfully!", http.StatusCreated, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		var payload CreateCoursePayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.CreateCourse(r.Context(), institution.CreateCourseRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			Name:          payload.Name,
+			Description:   payload.Description,
+		})
+	})
+}
+
+func (app *application) getCourseHandler() http.HandlerFunc {
+	return app.institutionAction("get course", "Course retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		courseId, err := urlParamId(r, "courseId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.GetCourse(r.Context(), actorId, institutionId, courseId)
+	})
+}
+
+func (app *application) updateCourseHandler() http.HandlerFunc {
+	return app.institutionAction("update course", "Course updated successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		courseId, err := urlParamId(r, "courseId")
+		if err != nil {
+			return nil, err
+		}
+		var payload UpdateCoursePayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.UpdateCourse(r.Context(), institution.UpdateCourseRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			CourseId:      courseId,
+			Name:          payload.Name,
+			Description:   payload.Description,
+		})
+	})
+}
+
+func (app *application) deleteCourseHandler() http.HandlerFunc {
+	return app.institutionAction("delete course", "Course deleted successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		courseId, err := urlParamId(r, "courseId")
+		if err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.DeleteCourse(r.Context(), actorId, institutionId, courseId)
+	})
+}
+
+// courseAccessHandler answers whether the user may create assessments in the course, 403 when they may not
+func (app *application) courseAccessHandler() http.HandlerFunc {
+	return app.institutionAction("check course access", "You can create assessments in this course", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		courseId, err := urlParamId(r, "courseId")
+		if err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.RequireCourseAccess(r.Context(), actorId, institutionId, courseId)
+	})
+}
+
+func (app *application) listGroupCoursesHandler() http.HandlerFunc {
+	return app.institutionAction("list group courses", "Courses retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		groupId, err := urlParamId(r, "groupId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.GetGroupCourses(r.Context(), actorId, institutionId, groupId)
+	})
+}
+
+func (app *application) grantGroupCourseHandler() http.HandlerFunc {
+	return app.institutionAction("grant group course access", "Course access granted successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		req, err := groupCourseRequest(r, actorId, institutionId)
+		if err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.GrantGroupCourseAccess(r.Context(), req)
+	})
+}
+
+func (app *application) revokeGroupCourseHandler() http.HandlerFunc {
+	return app.institutionAction("revoke group course access", "Course access revoked successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		req, err := groupCourseRequest(r, actorId, institutionId)
+		if err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.RevokeGroupCourseAccess(r.Context(), req)
+	})
+}
+
+func groupCourseRequest(r *http.Request, actorId, institutionId int) (institution.GroupCourseRequest, error) {
+	groupId, err := urlParamId(r, "groupId")
+	if err != nil {
+		return institution.GroupCourseRequest{}, err
+	}
+	courseId, err := urlParamId(r, "courseId")
+	if err != nil {
+		return institution.GroupCourseRequest{}, err
+	}
+	return institution.GroupCourseRequest{ActorId: actorId, InstitutionId: institutionId, GroupId: groupId, CourseId: courseId}, nil
+}
@@ -0,0 +1,126 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+type RequestEmailChangePayload struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required"`
+}
+
+type EmailChangeTokenPayload struct {
+	Token  string `json:"token" validate:"required,min=5,max=200"`
+	UserId int    `json:"uid" validate:"required"`
+}
+
+func (app *application) requestEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	parentTraceCtx, span := app.trace.Start(r.Context(), "request email change")
+
+	defer span.End()
+
+	user, ok := getUserFromContext(parentTraceCtx)
+	if !ok {
+		err := errors.New("unable to retrieve user")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	var payload RequestEmailChangePayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error reading email change payload as json", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error validating email change payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := app.service.user.RequestEmailChange(parentTraceCtx, user.Id, payload.Email, payload.Password); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("unable to request email change", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "A confirmation token has been sent to the new email. Please check your mail!", nil); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) confirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	parentTraceCtx, span := app.trace.Start(r.Context(), "confirm email change")
+
+	defer span.End()
+
+	var payload EmailChangeTokenPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error reading confirm email change payload as json", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error validating confirm email change payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	user, err := app.service.user.ConfirmEmailChange(parentTraceCtx, payload.UserId, payload.Token)
+	if err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("unable to confirm email change", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Email changed successfully!", user); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) revertEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	parentTraceCtx, span := app.trace.Start(r.Context(), "revert email change")
+
+	defer span.End()
+
+	var payload EmailChangeTokenPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error reading revert email change payload as json", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error validating revert email change payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := app.service.user.RevertEmailChange(parentTraceCtx, payload.UserId, payload.Token); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("unable to revert email change", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Email change reverted. Please reset your password if you suspect your account was compromised!", nil); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,118 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ClaimEmailDomainPayload, Mailbox is only used to verify by mailbox
+type ClaimEmailDomainPayload struct {
+	Domain  string `json:"domain" validate:"required"`
+	Method  string `json:"method" validate:"required,oneof=dns mailbox"`
+	Mailbox string `json:"mailbox" validate:"required_if=Method mailbox"`
+	Policy  string `json:"policy" validate:"omitempty,oneof=pending active"`
+}
+
+type UpdateEmailDomainPayload struct {
+	Policy string `json:"policy" validate:"required,oneof=pending active"`
+}
+
+type ConfirmEmailDomainPayload struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (app *application) emailDomainConfirmUrl() string {
+	return app.config.frontendUrl + "/email-domains/confirm"
+}
+
+func (app *application) listEmailDomainsHandler() http.HandlerFunc {
+	return app.institutionAction("list email domains", "Email domains retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		return app.service.institution.ListEmailDomains(r.Context(), actorId, institutionId)
+	})
+}
+
+func (app *application) claimEmailDomainHandler() http.HandlerFunc {
+	return app.institutionAction("claim email domain", "Email domain claimed, verify it to let people join with it!", http.StatusCreated, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		var payload ClaimEmailDomainPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.ClaimEmailDomain(r.Context(), institution.ClaimEmailDomainRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			Domain:        payload.Domain,
+			Method:        payload.Method,
+			Mailbox:       payload.Mailbox,
+			Policy:        payload.Policy,
+			ConfirmUrl:    app.emailDomainConfirmUrl(),
+		})
+	})
+}
+
+func (app *application) verifyEmailDomainHandler() http.HandlerFunc {
+	return app.institutionAction("verify email domain", "Email domain verified successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		domainId, err := urlParamId(r, "domainId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.VerifyEmailDomain(r.Context(), actorId, institutionId, domainId)
+	})
+}
+
+func (app *application) updateEmailDomainHandler() http.HandlerFunc {
+	return app.institutionAction("update email domain", "Email domain updated successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		domainId, err := urlParamId(r, "domainId")
+		if err != nil {
+			return nil, err
+		}
+		var payload UpdateEmailDomainPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.UpdateEmailDomain(r.Context(), institution.UpdateEmailDomainRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			DomainId:      domainId,
+			Policy:        payload.Policy,
+		})
+	})
+}
+
+func (app *application) removeEmailDomainHandler() http.HandlerFunc {
+	return app.institutionAction("remove email domain", "Email domain removed successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		domainId, err := urlParamId(r, "domainId")
+		if err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.RemoveEmailDomain(r.Context(), actorId, institutionId, domainId)
+	})
+}
+
+// confirmEmailDomainHandler is public, the token in the link sent to the admin mailbox identifies the domain
+func (app *application) confirmEmailDomainHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "confirm email domain")
+	defer span.End()
+
+	var payload ConfirmEmailDomainPayload
+	if err := readPayload(w, r, &payload); err != nil {
+		app.logger.WithContext(ctx).Error("Error reading confirm email domain payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	domain, err := app.service.institution.ConfirmEmailDomain(ctx, payload.Token)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to confirm email domain", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.institutionErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Email domain confirmed successfully!", domain); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
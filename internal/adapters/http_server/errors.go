@@ -1,6 +1,10 @@
 package httpserver
 
-import "net/http"
+import (
+	"net/http"
+
+	domainuser "github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+)
 
 func (app *application) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
 	app.logger.Error("internal error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
@@ -84,3 +88,25 @@ func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http
 
 	writeJsonError(w, http.StatusTooManyRequests, "rate limit exceeded, retry after: "+retryAfter, errors)
 }
+
+func (app *application) passwordPolicyViolationResponse(w http.ResponseWriter, r *http.Request, err *domainuser.PasswordPolicyError) {
+	app.logger.Warn("password policy violation", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+
+	type violation struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	type envelope struct {
+		Errors     []string    `json:"errors"`
+		Message    string      `json:"message"`
+		Violations []violation `json:"violations"`
+	}
+	errors := []string{}
+	violations := make([]violation, len(err.Violations))
+	for i, v := range err.Violations {
+		errors = append(errors, v.Message)
+		violations[i] = violation{Code: v.Code.String(), Message: v.Message}
+	}
+
+	writeJson(w, http.StatusUnprocessableEntity, &envelope{Message: "password does not meet the policy", Errors: errors, Violations: violations})
+}
@@ -0,0 +1,391 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type CreateInstitutionPayload struct {
+	Name        string `json:"name" validate:"required,min=3,max=100"`
+	Email       string `json:"email" validate:"required,email,max=255"`
+	Description string `json:"description" validate:"required,min=150,max=800"`
+}
+
+// UpdateInstitutionPayload changes only the fields that are sent
+type UpdateInstitutionPayload struct {
+	Name        *string `json:"name" validate:"omitempty,min=3,max=100"`
+	Email       *string `json:"email" validate:"omitempty,email,max=255"`
+	Description *string `json:"description" validate:"omitempty,min=150,max=800"`
+}
+
+type AddStaffPayload struct {
+	Name  string `json:"name" validate:"required,min=3,max=100"`
+	Email string `json:"email" validate:"required,email,max=255"`
+	Role  string `json:"role" validate:"omitempty,oneof=admin member"`
+}
+
+// ChangeStaffStatusPayload, the reason is required to suspend or blacklist
+type ChangeStaffStatusPayload struct {
+	Status string `json:"status" validate:"required,oneof=active suspended blacklisted"`
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type CreateGroupPayload struct {
+	Name        string `json:"name" validate:"required,min=3,max=100"`
+	Description string `json:"description" validate:"required,min=150,max=800"`
+}
+
+// UpdateGroupPayload changes only the fields that are sent
+type UpdateGroupPayload struct {
+	Name        *string `json:"name" validate:"omitempty,min=3,max=100"`
+	Description *string `json:"description" validate:"omitempty,min=150,max=800"`
+}
+
+type AddGroupStaffPayload struct {
+	StaffId int `json:"staffId" validate:"required,min=1"`
+}
+
+func (app *application) institutionErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, institution.ErrNotInstitutionAdmin), errors.Is(err, institution.ErrNotInstitutionMember), errors.Is(err, institution.ErrCourseAccessDenied), errors.Is(err, auditlog.ErrImpersonated), errors.Is(err, institute_repo.ErrCrossTenantAccess), errors.Is(err, institution.ErrNotInstitutionOwner):
+		app.forbiddenResponse(w, r)
+	case errors.Is(err, institute_repo.ErrInstitutionNotFound), errors.Is(err, institute_repo.ErrStaffNotFound), errors.Is(err, institute_repo.ErrGroupNotFound), errors.Is(err, institute_repo.ErrCourseNotFound), errors.Is(err, institute_repo.ErrInviteNotFound), errors.Is(err, institute_repo.ErrImportNotFound), errors.Is(err, institute_repo.ErrCategoryNotFound), errors.Is(err, institute_repo.ErrTransferNotFound), errors.Is(err, institute_repo.ErrEmailDomainNotFound):
+		app.notFoundResponse(w, r, err)
+	case errors.Is(err, institute_repo.ErrStaffExists), errors.Is(err, institute_repo.ErrGroupExists), errors.Is(err, institute_repo.ErrCourseExists), errors.Is(err, institution.ErrCategoryExists), errors.Is(err, institution.ErrCategoryHasChildren), errors.Is(err, institution.ErrStaffBlacklisted), errors.Is(err, institution.ErrLastOwner), errors.Is(err, institute_repo.ErrEmailDomainExists), errors.Is(err, institute_repo.ErrEmailDomainClaimed):
+		app.conflictResponse(w, r, err)
+	default:
+		app.badRequestResponse(w, r, err)
+	}
+}
+
+// tenantInstitutionId returns the institution resolveTenant scoped the request to
+func tenantInstitutionId(ctx context.Context) (int, error) {
+	tenant, ok := institute_repo.TenantFromContext(ctx)
+	if !ok {
+		return 0, errors.New("the request is not scoped to an institution")
+	}
+	return tenant.InstitutionId().Value(), nil
+}
+
+// readPayload reads and validates the json body into payload
+func readPayload(w http.ResponseWriter, r *http.Request, payload any) error {
+	if err := readJson(w, r, payload); err != nil {
+		return err
+	}
+	return Validate.Struct(payload)
+}
+
+// urlParamId reads a numeric id from the url
+func urlParamId(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, name))
+	if err != nil {
+		return 0, errors.New("invalid " + name)
+	}
+	return id, nil
+}
+
+// queryId reads an optional numeric id from the query string, nil when it is not sent
+func queryId(r *http.Request, name string) (*int, error) {
+	val := r.URL.Query().Get(name)
+	if val == "" {
+		return nil, nil
+	}
+	id, err := strconv.Atoi(val)
+	if err != nil {
+		return nil, errors.New("invalid " + name)
+	}
+	return &id, nil
+}
+
+// readPagination reads the ?page= and ?pageSize= of a listing, the service fills in what is left out
+func readPagination(r *http.Request) (institution.Pagination, error) {
+	var pagination institution.Pagination
+	for name, dest := range map[string]*int{"page": &pagination.Page, "pageSize": &pagination.PageSize} {
+		if val := r.URL.Query().Get(name); val != "" {
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return pagination, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dest = n
+		}
+	}
+	return pagination, nil
+}
+
+// institutionAction runs an action of the user within the institution in the {institutionId} url param
+func (app *application) institutionAction(spanName, message string, status int, action func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := app.trace.Start(r.Context(), spanName)
+		defer span.End()
+
+		user, ok := getUserFromContext(ctx)
+		if !ok {
+			app.unauthorizedErrorResponse(w, r, errors.New("unable to retrieve user"))
+			return
+		}
+		institutionId, err := tenantInstitutionId(ctx)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		data, err := action(w, r.WithContext(ctx), user.Id, institutionId)
+		if err != nil {
+			app.logger.WithContext(ctx).Error("unable to "+spanName, err)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			app.institutionErrorResponse(w, r, err)
+			return
+		}
+
+		if err := app.jsonResponse(w, status, message, data); err != nil {
+			app.internalServerError(w, r, err)
+		}
+	}
+}
+
+// createInstitutionHandler makes the user the first admin of the new institution
+func (app *application) createInstitutionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "create institution")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		app.unauthorizedErrorResponse(w, r, errors.New("unable to retrieve user"))
+		return
+	}
+	var payload CreateInstitutionPayload
+	if err := readPayload(w, r, &payload); err != nil {
+		app.logger.WithContext(ctx).Error("Error reading institution payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	created, err := app.service.institution.CreateInstitution(ctx, institution.CreateInstitutionRequest{
+		Name:         payload.Name,
+		Email:        payload.Email,
+		Description:  payload.Description,
+		CreatorId:    user.Id,
+		CreatorName:  user.Name,
+		CreatorEmail: user.Email,
+	})
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to create institution", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.institutionErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, "Institution created successfully!", created); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getInstitutionHandler() http.HandlerFunc {
+	return app.institutionAction("get institution", "Institution retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		return app.service.institution.GetInstitutionById(r.Context(), actorId, institutionId)
+	})
+}
+
+func (app *application) updateInstitutionHandler() http.HandlerFunc {
+	return app.institutionAction("update institution", "Institution updated successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		var payload UpdateInstitutionPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.UpdateInstitution(r.Context(), institution.UpdateInstitutionRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			Name:          payload.Name,
+			Description:   payload.Description,
+			Email:         payload.Email,
+		})
+	})
+}
+
+func (app *application) deleteInstitutionHandler() http.HandlerFunc {
+	return app.institutionAction("delete institution", "Institution deleted successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		return nil, app.service.institution.DeleteInstitution(r.Context(), actorId, institutionId)
+	})
+}
+
+// listInstitutionStaffHandler, ?status= lists only the staff with the status, ?status=removed the removed staff.
+// The staff come a page at a time, see readPagination
+func (app *application) listInstitutionStaffHandler() http.HandlerFunc {
+	return app.institutionAction("list institution staff", "Staff retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		pagination, err := readPagination(r)
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.GetStaff(r.Context(), actorId, institutionId, r.URL.Query().Get("status"), pagination)
+	})
+}
+
+func (app *application) addInstitutionStaffHandler() http.HandlerFunc {
+	return app.institutionAction("add institution staff", "Staff added successfully!", http.StatusCreated, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		var payload AddStaffPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.AddStaff(r.Context(), institution.AddStaffRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			Name:          payload.Name,
+			Email:         payload.Email,
+			Role:          payload.Role,
+		})
+	})
+}
+
+func (app *application) removeInstitutionStaffHandler() http.HandlerFunc {
+	return app.institutionAction("remove institution staff", "Staff removed successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		staffId, err := urlParamId(r, "staffId")
+		if err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.RemoveStaff(r.Context(), actorId, institutionId, staffId)
+	})
+}
+
+func (app *application) changeStaffStatusHandler() http.HandlerFunc {
+	return app.institutionAction("change staff status", "Staff status changed successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		staffId, err := urlParamId(r, "staffId")
+		if err != nil {
+			return nil, err
+		}
+		var payload ChangeStaffStatusPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.ChangeStaffStatus(r.Context(), institution.ChangeStaffStatusRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			StaffId:       staffId,
+			Status:        payload.Status,
+			Reason:        payload.Reason,
+		})
+	})
+}
+
+func (app *application) restoreStaffHandler() http.HandlerFunc {
+	return app.institutionAction("restore staff", "Staff restored successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		staffId, err := urlParamId(r, "staffId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.RestoreStaff(r.Context(), actorId, institutionId, staffId)
+	})
+}
+
+func (app *application) listGroupsHandler() http.HandlerFunc {
+	return app.institutionAction("list groups", "Groups retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		pagination, err := readPagination(r)
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.GetGroups(r.Context(), actorId, institutionId, pagination)
+	})
+}
+
+func (app *application) createGroupHandler() http.HandlerFunc {
+	return app.institutionAction("create group", "Group created successfully!", http.StatusCreated, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		var payload CreateGroupPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.CreateGroup(r.Context(), institution.CreateGroupRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			Name:          payload.Name,
+			Description:   payload.Description,
+		})
+	})
+}
+
+func (app *application) getGroupHandler() http.HandlerFunc {
+	return app.institutionAction("get group", "Group retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		groupId, err := urlParamId(r, "groupId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.GetGroup(r.Context(), actorId, institutionId, groupId)
+	})
+}
+
+func (app *application) updateGroupHandler() http.HandlerFunc {
+	return app.institutionAction("update group", "Group updated successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		groupId, err := urlParamId(r, "groupId")
+		if err != nil {
+			return nil, err
+		}
+		var payload UpdateGroupPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.UpdateGroup(r.Context(), institution.UpdateGroupRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			GroupId:       groupId,
+			Name:          payload.Name,
+			Description:   payload.Description,
+		})
+	})
+}
+
+func (app *application) deleteGroupHandler() http.HandlerFunc {
+	return app.institutionAction("delete group", "Group deleted successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		groupId, err := urlParamId(r, "groupId")
+		if err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.DeleteGroup(r.Context(), actorId, institutionId, groupId)
+	})
+}
+
+func (app *application) addGroupStaffHandler() http.HandlerFunc {
+	return app.institutionAction("add group staff", "Staff added to group successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		groupId, err := urlParamId(r, "groupId")
+		if err != nil {
+			return nil, err
+		}
+		var payload AddGroupStaffPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.AddStaffToGroup(r.Context(), institution.AddStaffToGroupRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			GroupId:       groupId,
+			StaffId:       payload.StaffId,
+		})
+	})
+}
+
+func (app *application) removeGroupStaffHandler() http.HandlerFunc {
+	return app.institutionAction("remove group staff", "Staff removed from group successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		groupId, err := urlParamId(r, "groupId")
+		if err != nil {
+			return nil, err
+		}
+		staffId, err := urlParamId(r, "staffId")
+		if err != nil {
+			return nil, err
+		}
+		return nil, app.service.institution.RemoveStaffFromGroup(r.Context(), institution.AddStaffToGroupRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			GroupId:       groupId,
+			StaffId:       staffId,
+		})
+	})
+}
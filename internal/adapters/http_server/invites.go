@@ -0,0 +1,112 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
+	usermanagment "github.com/kaasikodes/assessmate_backend/internal/core/application/services/user-managment"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type StaffInvitePayload struct {
+	Name  string `json:"name" validate:"required,min=3,max=100"`
+	Email string `json:"email" validate:"required,email,max=255"`
+	Role  string `json:"role" validate:"omitempty,oneof=admin member"`
+}
+
+type InviteStaffPayload struct {
+	Invites []StaffInvitePayload `json:"invites" validate:"required,min=1,max=100,dive"`
+}
+
+// AcceptInvitePayload, name and password are only needed when the invitee has no account yet
+type AcceptInvitePayload struct {
+	Token    string `json:"token" validate:"required"`
+	Name     string `json:"name" validate:"omitempty,min=3,max=100"`
+	Password string `json:"password" validate:"omitempty,max=72"`
+}
+
+func (app *application) inviteAcceptUrl() string {
+	return app.config.frontendUrl + "/invites/accept"
+}
+
+func (app *application) inviteStaffHandler() http.HandlerFunc {
+	return app.institutionAction("invite staff", "Invites processed successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		var payload InviteStaffPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		staff := make([]institution.StaffInvite, len(payload.Invites))
+		for i, invite := range payload.Invites {
+			staff[i] = institution.StaffInvite{Name: invite.Name, Email: invite.Email, Role: invite.Role}
+		}
+		return app.service.institution.InviteStaff(r.Context(), institution.InviteStaffRequest{
+			Staff:         staff,
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			AcceptUrl:     app.inviteAcceptUrl(),
+		})
+	})
+}
+
+func (app *application) listInvitesHandler() http.HandlerFunc {
+	return app.institutionAction("list invites", "Invites retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		return app.service.institution.ListInvites(r.Context(), actorId, institutionId)
+	})
+}
+
+func (app *application) resendInviteHandler() http.HandlerFunc {
+	return app.institutionAction("resend invite", "Invite resent successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		inviteId, err := urlParamId(r, "inviteId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.ResendInvite(r.Context(), actorId, institutionId, inviteId, app.inviteAcceptUrl())
+	})
+}
+
+func (app *application) revokeInviteHandler() http.HandlerFunc {
+	return app.institutionAction("revoke invite", "Invite revoked successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		inviteId, err := urlParamId(r, "inviteId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.RevokeInvite(r.Context(), actorId, institutionId, inviteId)
+	})
+}
+
+// acceptInviteHandler is public, the token in the invite email identifies the invitee
+func (app *application) acceptInviteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "accept invite")
+	defer span.End()
+
+	var payload AcceptInvitePayload
+	if err := readPayload(w, r, &payload); err != nil {
+		app.logger.WithContext(ctx).Error("Error reading accept invite payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	accepted, err := app.service.institution.AcceptInvite(ctx, institution.AcceptInviteRequest{
+		Token:    payload.Token,
+		Name:     payload.Name,
+		Password: payload.Password,
+	})
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to accept invite", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		switch {
+		case errors.Is(err, usermanagment.ErrAccountSuspended), errors.Is(err, usermanagment.ErrAccountDeleted):
+			app.forbiddenResponse(w, r)
+		default:
+			app.institutionErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Invite accepted successfully!", accepted); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,11 @@
+package httpserver
+
+import "net/http"
+
+// jwksHandler publishes the public signing keys, the set is written bare (not in the usual envelope) as JWKS clients expect
+func (app *application) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := writeJson(w, http.StatusOK, app.jwt.JWKS()); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
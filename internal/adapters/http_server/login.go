@@ -1,7 +1,11 @@
 package httpserver
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+
+	usermanagment "github.com/kaasikodes/assessmate_backend/internal/core/application/services/user-managment"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -9,7 +13,7 @@ import (
 
 type LoginUserPayload struct {
 	Email    string `json:"email" validate:"required,email,max=255"`
-	Password string `json:"password" validate:"required,min=5,max=17"`
+	Password string `json:"password" validate:"required,max=256"`
 }
 
 func (app *application) loginHandler(w http.ResponseWriter, r *http.Request) {
@@ -36,7 +40,15 @@ func (app *application) loginHandler(w http.ResponseWriter, r *http.Request) {
 	span.SetAttributes(
 		attribute.String("email", payload.Email),
 	)
-	userData, err := app.service.user.Login(parentTraceCtx, payload.Email, payload.Password)
+	userData, err := app.service.user.Login(parentTraceCtx, payload.Email, payload.Password, app.deviceInfo(r))
+	var tooManyAttempts *usermanagment.TooManyAttemptsError
+	if errors.As(err, &tooManyAttempts) {
+		app.logger.WithContext(parentTraceCtx).Warn("Login throttled", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.rateLimitExceededResponse(w, r, strconv.Itoa(ceilSeconds(tooManyAttempts.RetryAfter)))
+		return
+	}
 	// unable to find user
 	if err != nil {
 		app.logger.WithContext(parentTraceCtx).Error("Unable to locate user", err)
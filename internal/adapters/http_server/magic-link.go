@@ -0,0 +1,117 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+
+	usermanagment "github.com/kaasikodes/assessmate_backend/internal/core/application/services/user-managment"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+// the nonce binding a magic link to the browser that asked for it
+const magicLinkCookieName = "magic_link_nonce"
+
+type RequestMagicLinkPayload struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+type MagicLinkLoginPayload struct {
+	Token  string `json:"token" validate:"required,min=5,max=200"`
+	UserId int    `json:"uid" validate:"required"`
+	// the code shown by the browser that requested the link, needed to sign in on any other device
+	Code string `json:"code" validate:"omitempty,max=20"`
+}
+
+func (app *application) requestMagicLinkHandler(w http.ResponseWriter, r *http.Request) {
+	parentTraceCtx, span := app.trace.Start(r.Context(), "request magic link")
+
+	defer span.End()
+
+	var payload RequestMagicLinkPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error reading magic link payload as json", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error validating magic link payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	req, err := app.service.user.RequestMagicLink(parentTraceCtx, payload.Email, app.config.frontendUrl+"/auth/magic-link")
+	if err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("unable to request magic link", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	app.setMagicLinkCookie(w, req.Nonce, int(magicLinkCookieMaxAge.Seconds()))
+
+	// shown to the user, it is asked for when the link is opened on another device
+	if err := app.jsonResponse(w, http.StatusOK, "If an account exists for this email, a sign in link has been sent!", map[string]string{"confirmationCode": req.Code}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) magicLinkLoginHandler(w http.ResponseWriter, r *http.Request) {
+	parentTraceCtx, span := app.trace.Start(r.Context(), "magic link login")
+
+	defer span.End()
+
+	var payload MagicLinkLoginPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error reading magic link login payload as json", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error validating magic link login payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	var nonce string
+	if cookie, err := r.Cookie(magicLinkCookieName); err == nil {
+		nonce = cookie.Value
+	}
+	userData, err := app.service.user.LoginWithMagicLink(parentTraceCtx, payload.UserId, payload.Token, nonce, payload.Code, app.deviceInfo(r))
+	if errors.Is(err, usermanagment.ErrMagicLinkConfirmationRequired) {
+		if err := app.jsonResponse(w, http.StatusAccepted, err.Error(), map[string]bool{"confirmationRequired": true}); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("unable to login with magic link", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.unauthorizedErrorResponse(w, r, err)
+		return
+	}
+	app.setMagicLinkCookie(w, "", -1)
+
+	if err := app.jsonResponse(w, http.StatusOK, "User logged in successfully!", userData); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) setMagicLinkCookie(w http.ResponseWriter, nonce string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     magicLinkCookieName,
+		Value:    nonce,
+		Path:     "/v1/auth/magic-link",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   app.isProduction(),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
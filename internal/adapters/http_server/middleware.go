@@ -6,9 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/go-chi/chi"
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	domainuser "github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
 	"go.opentelemetry.io/otel/codes"
 )
 
@@ -17,6 +22,35 @@ func (app *application) authMiddleware(next http.Handler) http.Handler {
 		ctx, span := app.trace.Start(r.Context(), "Auth middleware")
 		defer span.End()
 
+		// Integrations authenticate with an api key instead of a jwt
+		if rawKey, ok := apiKeyFromRequest(r); ok {
+			principal, err := app.service.user.AuthenticateApiKey(ctx, rawKey)
+			if err != nil {
+				app.logger.WithContext(ctx).Error("Api key error", err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				app.unauthorizedErrorResponse(w, r, fmt.Errorf("please provide a valid api key: %w", err))
+				return
+			}
+			if !principal.User.IsVerified {
+				app.badRequestResponse(w, r, errors.New("user is not verified"))
+				return
+			}
+			if principal.User.DeletedAt != nil {
+				app.unauthorizedErrorResponse(w, r, errors.New("account has been scheduled for deletion"))
+				return
+			}
+			if principal.User.Status == domainuser.Suspended.String() {
+				app.unauthorizedErrorResponse(w, r, errors.New("account has been suspended"))
+				return
+			}
+			auditlog.SetActor(ctx, principal.User.Id)
+			ctx = context.WithValue(ctx, ContextKeyUser{}, &principal.User)
+			ctx = context.WithValue(ctx, ContextKeyApiKey{}, &principal.ApiKey)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		// Step 1: Verify token
 		claims, err := app.jwt.ExtractAndVerifyToken(r)
 		if err != nil {
@@ -42,21 +76,158 @@ func (app *application) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Step 3: Check if verified
+		// Step 3: Check if verified, not deleted and not suspended
 		if !user.IsVerified {
 			app.badRequestResponse(w, r, errors.New("user is not verified"))
 			return
 		}
+		if user.DeletedAt != nil {
+			app.unauthorizedErrorResponse(w, r, errors.New("account has been scheduled for deletion"))
+			return
+		}
+		if user.Status == domainuser.Suspended.String() {
+			app.unauthorizedErrorResponse(w, r, errors.New("account has been suspended"))
+			return
+		}
 
-		// Step 4: Add user and claims to context
+		// Step 4: Check the session has not been revoked, tokens issued before sessions existed carry none
+		if claims.SessionID != "" {
+			sessionID, err := strconv.Atoi(claims.SessionID)
+			if err != nil {
+				app.unauthorizedErrorResponse(w, r, fmt.Errorf("invalid session ID in token"))
+				return
+			}
+			if err := app.service.user.ValidateSession(ctx, userID, sessionID); err != nil {
+				app.logger.WithContext(ctx).Error("Session is not valid", err)
+				app.unauthorizedErrorResponse(w, r, fmt.Errorf("session is no longer valid"))
+				return
+			}
+			app.service.user.RecordSessionActivity(sessionID)
+		}
+
+		auditlog.SetActor(ctx, user.Id)
+
+		// Step 5: An admin acting as the user must still be allowed to, and everything they do is audited
+		if claims.IsImpersonation() {
+			impersonatorID, err := strconv.Atoi(claims.ImpersonatorID)
+			if err != nil {
+				app.unauthorizedErrorResponse(w, r, fmt.Errorf("invalid impersonator ID in token"))
+				return
+			}
+			if err := app.service.user.ValidateImpersonator(ctx, impersonatorID); err != nil {
+				app.logger.WithContext(ctx).Error("Impersonator is not valid", err)
+				app.unauthorizedErrorResponse(w, r, fmt.Errorf("impersonation is no longer allowed"))
+				return
+			}
+			auditlog.SetImpersonator(ctx, impersonatorID)
+			app.service.audit.Record(ctx, auditlog.Event{
+				Action:     "impersonation.request",
+				TargetType: auditlog.TargetUser,
+				TargetId:   user.Id,
+				After:      map[string]any{"method": r.Method, "path": r.URL.Path},
+			})
+			w.Header().Set(impersonatedByHeader, claims.ImpersonatorID)
+		}
+
+		// Step 6: Add user and claims to context
 		ctx = context.WithValue(ctx, ContextKeyUser{}, user)
 		ctx = context.WithValue(ctx, ContextKeyClaims{}, claims)
 
-		// Step 5: Call next handler with the new context
+		// Step 7: Call next handler with the new context
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// requireScope limits api keys to routes their scopes cover, requests made with a jwt act with the user's full access
+func (app *application) requireScope(scope domainuser.ApiKeyScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key, ok := getApiKeyFromContext(r.Context()); ok && !slices.Contains(key.Scopes, scope.String()) {
+				app.forbiddenResponse(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forbidImpersonation keeps impersonating admins off sensitive routes, e.g. email change and payments.
+// The services refuse these actions too, this answers before any work is done.
+func (app *application) forbidImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auditlog.IsImpersonated(r.Context()) {
+			app.forbiddenResponse(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// institutionIdHeader names the institution on routes that do not carry it in the path
+const institutionIdHeader = "X-Institution-Id"
+
+// resolveTenant scopes the request to the institution in the path, or in the X-Institution-Id header under /institutions/current.
+// An institution api key or a token from switching institution supplies the institution when neither does, and cannot be used for any other.
+// The user must be an active staff of it, and the repositories refuse to touch another institution's data for the rest of the request.
+func (app *application) resolveTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := app.trace.Start(r.Context(), "Tenant middleware")
+		defer span.End()
+
+		user, ok := getUserFromContext(ctx)
+		if !ok {
+			app.unauthorizedErrorResponse(w, r, errors.New("unable to retrieve user"))
+			return
+		}
+		raw, header := chi.URLParam(r, "institutionId"), r.Header.Get(institutionIdHeader)
+		switch {
+		case raw == "":
+			raw = header
+		case header != "" && header != raw:
+			app.badRequestResponse(w, r, fmt.Errorf("the %s header does not match the institution in the path", institutionIdHeader))
+			return
+		}
+		// an institution api key and a token from switching institution are both bound to one institution
+		var scoped string
+		if key, ok := getApiKeyFromContext(ctx); ok && key.InstitutionId != nil {
+			scoped = strconv.Itoa(*key.InstitutionId)
+		} else if claims, ok := getClaimsFromContext(ctx); ok {
+			scoped = claims.InstitutionID
+		}
+		if raw == "" {
+			raw = scoped
+		}
+		institutionId, err := strconv.Atoi(raw)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("a valid institution id is required in the path or the %s header", institutionIdHeader))
+			return
+		}
+		if scoped != "" && scoped != strconv.Itoa(institutionId) {
+			app.institutionErrorResponse(w, r, institute_repo.ErrCrossTenantAccess)
+			return
+		}
+		tenant, err := app.service.tenants.ResolveTenant(ctx, user.Id, institutionId)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			app.institutionErrorResponse(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(institute_repo.WithTenant(ctx, tenant)))
+	})
+}
+
+// requireSession keeps api keys off routes that manage the account itself, e.g. sessions and the keys themselves
+func (app *application) requireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := getApiKeyFromContext(r.Context()); ok {
+			app.forbiddenResponse(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (app *application) metricsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		normalizedPath := normalizePath(r.URL.Path)
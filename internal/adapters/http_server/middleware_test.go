@@ -0,0 +1,173 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
+	usermanagment "github.com/kaasikodes/assessmate_backend/internal/core/application/services/user-managment"
+	domaininstitution "github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+	jwtport "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/jwt"
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/logger"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type discardLogger struct{}
+
+func (discardLogger) Info(v ...any)                                   {}
+func (discardLogger) Warn(v ...any)                                   {}
+func (discardLogger) Error(v ...any)                                  {}
+func (discardLogger) Fatal(v ...any)                                  {}
+func (l discardLogger) WithContext(ctx context.Context) logger.Logger { return l }
+
+// fakeTenantResolver makes the user an active staff of the institutions in members only
+type fakeTenantResolver struct {
+	members map[int]bool
+	calls   int
+}
+
+func (f *fakeTenantResolver) ResolveTenant(ctx context.Context, actorId, institutionId int) (*domaininstitution.Tenant, error) {
+	f.calls++
+	if !f.members[institutionId] {
+		return nil, institution.ErrNotInstitutionMember
+	}
+	staff, err := domaininstitution.NewStaff(domaininstitution.Name("Ada"), domaininstitution.Email("ada@example.com"), domaininstitution.Active)
+	if err != nil {
+		return nil, err
+	}
+	staff.SetId(1)
+	staff.LinkUser(domaininstitution.Id(actorId))
+	return domaininstitution.NewTenant(domaininstitution.Id(institutionId), staff)
+}
+
+// tenantRouter routes /institutions/current and /institutions/{institutionId} through resolveTenant for user 7,
+// signed in with the claims or the api key
+func tenantRouter(app *application, claims *jwtport.CustomClaims, apiKey *usermanagment.ApiKey, next http.HandlerFunc) http.Handler {
+	router := chi.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), ContextKeyUser{}, &usermanagment.User{Id: 7})
+			if claims != nil {
+				ctx = context.WithValue(ctx, ContextKeyClaims{}, claims)
+			}
+			if apiKey != nil {
+				ctx = context.WithValue(ctx, ContextKeyApiKey{}, apiKey)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	router.With(app.resolveTenant).Get("/institutions/current", next)
+	router.With(app.resolveTenant).Get("/institutions/{institutionId}", next)
+	return router
+}
+
+func institutionApiKey(institutionId int) *usermanagment.ApiKey {
+	return &usermanagment.ApiKey{Id: 3, Scopes: []string{"roster:read"}, InstitutionId: &institutionId}
+}
+
+func TestResolveTenantRejectsMismatchedInstitutions(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		claims *jwtport.CustomClaims
+		apiKey *usermanagment.ApiKey
+		want   int
+	}{
+		{name: "header names another institution than the path", path: "/institutions/1", header: "2", want: http.StatusBadRequest},
+		{name: "switched token used for another institution in the path", path: "/institutions/2", claims: &jwtport.CustomClaims{UserID: "7", InstitutionID: "1"}, want: http.StatusForbidden},
+		{name: "switched token used for another institution in the header", path: "/institutions/current", header: "2", claims: &jwtport.CustomClaims{UserID: "7", InstitutionID: "1"}, want: http.StatusForbidden},
+		{name: "no institution at all", path: "/institutions/current", want: http.StatusBadRequest},
+		{name: "institution api key used for another institution in the path", path: "/institutions/2", apiKey: institutionApiKey(1), want: http.StatusForbidden},
+		{name: "institution api key used for another institution in the header", path: "/institutions/current", header: "2", apiKey: institutionApiKey(1), want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the user is staff of both institutions, only the binding of the token or key keeps them out
+			resolver := &fakeTenantResolver{members: map[int]bool{1: true, 2: true}}
+			app := &application{logger: discardLogger{}, trace: noop.NewTracerProvider().Tracer("test"), service: Service{tenants: resolver}}
+			reached := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { reached = true })
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				r.Header.Set(institutionIdHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			tenantRouter(app, tt.claims, tt.apiKey, next).ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if reached {
+				t.Error("the request reached the handler")
+			}
+			if resolver.calls != 0 {
+				t.Errorf("the institution service was asked %d times", resolver.calls)
+			}
+		})
+	}
+}
+
+func TestResolveTenantScopesInstitutionApiKeys(t *testing.T) {
+	resolver := &fakeTenantResolver{members: map[int]bool{1: true, 2: true}}
+	app := &application{logger: discardLogger{}, trace: noop.NewTracerProvider().Tracer("test"), service: Service{tenants: resolver}}
+
+	var tenant *domaininstitution.Tenant
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, _ = institute_repo.TenantFromContext(r.Context())
+	})
+	w := httptest.NewRecorder()
+	tenantRouter(app, nil, institutionApiKey(1), next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/institutions/current", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if tenant == nil || tenant.InstitutionId() != 1 {
+		t.Errorf("tenant = %+v, want the institution the key was issued for", tenant)
+	}
+}
+
+func TestResolveTenantRefusesNonMembers(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		claims *jwtport.CustomClaims
+		apiKey *usermanagment.ApiKey
+	}{
+		{name: "signed in user in the path of an institution they are not staff of", path: "/institutions/2", claims: &jwtport.CustomClaims{UserID: "7"}},
+		{name: "signed in user naming an institution they are not staff of in the header", path: "/institutions/current", header: "2", claims: &jwtport.CustomClaims{UserID: "7"}},
+		{name: "personal api key on an institution the user is not staff of", path: "/institutions/2", apiKey: &usermanagment.ApiKey{Id: 3, Scopes: []string{"roster:read"}}},
+		{name: "institution api key of an institution the user has since left", path: "/institutions/current", apiKey: institutionApiKey(2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &fakeTenantResolver{members: map[int]bool{1: true}}
+			app := &application{logger: discardLogger{}, trace: noop.NewTracerProvider().Tracer("test"), service: Service{tenants: resolver}}
+			reached := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { reached = true })
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				r.Header.Set(institutionIdHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			tenantRouter(app, tt.claims, tt.apiKey, next).ServeHTTP(w, r)
+
+			if w.Code != http.StatusForbidden && w.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want %d or %d", w.Code, http.StatusForbidden, http.StatusNotFound)
+			}
+			if reached {
+				t.Error("the request reached the handler")
+			}
+			if resolver.calls != 1 {
+				t.Errorf("the institution service was asked %d times, want 1", resolver.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ChangeStaffRolePayload, the owner role is only given by transferring ownership
+type ChangeStaffRolePayload struct {
+	Role string `json:"role" validate:"required,oneof=admin member"`
+}
+
+type StartOwnershipTransferPayload struct {
+	StaffId int `json:"staffId" validate:"required,min=1"`
+}
+
+type ConfirmOwnershipTransferPayload struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (app *application) ownershipTransferConfirmUrl() string {
+	return app.config.frontendUrl + "/ownership-transfers/confirm"
+}
+
+func (app *application) changeStaffRoleHandler() http.HandlerFunc {
+	return app.institutionAction("change staff role", "Staff role changed successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		staffId, err := urlParamId(r, "staffId")
+		if err != nil {
+			return nil, err
+		}
+		var payload ChangeStaffRolePayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.ChangeStaffRole(r.Context(), institution.ChangeStaffRoleRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			StaffId:       staffId,
+			Role:          payload.Role,
+		})
+	})
+}
+
+func (app *application) startOwnershipTransferHandler() http.HandlerFunc {
+	return app.institutionAction("start ownership transfer", "Ownership transfer started, both of you need to confirm by email!", http.StatusCreated, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		var payload StartOwnershipTransferPayload
+		if err := readPayload(w, r, &payload); err != nil {
+			return nil, err
+		}
+		return app.service.institution.StartOwnershipTransfer(r.Context(), institution.StartOwnershipTransferRequest{
+			ActorId:       actorId,
+			InstitutionId: institutionId,
+			StaffId:       payload.StaffId,
+			ConfirmUrl:    app.ownershipTransferConfirmUrl(),
+		})
+	})
+}
+
+func (app *application) getOwnershipTransferHandler() http.HandlerFunc {
+	return app.institutionAction("get ownership transfer", "Ownership transfer retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		return app.service.institution.GetOwnershipTransfer(r.Context(), actorId, institutionId)
+	})
+}
+
+func (app *application) cancelOwnershipTransferHandler() http.HandlerFunc {
+	return app.institutionAction("cancel ownership transfer", "Ownership transfer cancelled successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		return app.service.institution.CancelOwnershipTransfer(r.Context(), actorId, institutionId)
+	})
+}
+
+// confirmOwnershipTransferHandler is public, the token in the email identifies which party is confirming
+func (app *application) confirmOwnershipTransferHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "confirm ownership transfer")
+	defer span.End()
+
+	var payload ConfirmOwnershipTransferPayload
+	if err := readPayload(w, r, &payload); err != nil {
+		app.logger.WithContext(ctx).Error("Error reading confirm ownership transfer payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	transfer, err := app.service.institution.ConfirmOwnershipTransfer(ctx, payload.Token)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to confirm ownership transfer", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.institutionErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Ownership transfer confirmed successfully!", transfer); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
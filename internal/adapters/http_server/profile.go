@@ -0,0 +1,158 @@
+package httpserver
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	usermanagment "github.com/kaasikodes/assessmate_backend/internal/core/application/services/user-managment"
+	domainuser "github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// the form around the image is small, anything much bigger than the image limit is rejected before it is read
+const avatarFormMaxSize = domainuser.MaxAvatarSize + 64<<10
+
+// UpdateProfilePayload changes only the fields that are sent, the domain validates the values
+type UpdateProfilePayload struct {
+	DisplayName          *string  `json:"displayName" validate:"omitempty,max=100"`
+	Bio                  *string  `json:"bio" validate:"omitempty,max=500"`
+	Timezone             *string  `json:"timezone" validate:"omitempty,max=64"`
+	Locale               *string  `json:"locale" validate:"omitempty,max=35"`
+	NotificationChannels []string `json:"notificationChannels" validate:"omitempty,max=10"`
+	DefaultQuestionType  *string  `json:"defaultQuestionType" validate:"omitempty,max=50"`
+	DefaultAiDifficulty  *string  `json:"defaultAiDifficulty" validate:"omitempty,max=20"`
+}
+
+func (app *application) getProfileHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "get profile")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		app.badRequestResponse(w, r, errors.New("unable to retrieve user"))
+		return
+	}
+	profile, err := app.service.user.GetProfile(ctx, user.Id)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to retrieve profile", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Profile retrieved successfully!", profile); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "update profile")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		app.badRequestResponse(w, r, errors.New("unable to retrieve user"))
+		return
+	}
+	var payload UpdateProfilePayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.logger.WithContext(ctx).Error("Error reading profile payload as json", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.logger.WithContext(ctx).Error("Error validating profile payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	profile, err := app.service.user.UpdateProfile(ctx, user.Id, usermanagment.UpdateProfileRequest{
+		DisplayName:          payload.DisplayName,
+		Bio:                  payload.Bio,
+		Timezone:             payload.Timezone,
+		Locale:               payload.Locale,
+		NotificationChannels: payload.NotificationChannels,
+		DefaultQuestionType:  payload.DefaultQuestionType,
+		DefaultAiDifficulty:  payload.DefaultAiDifficulty,
+	})
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to update profile", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Profile updated successfully!", profile); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// uploadAvatarHandler takes the image in the "avatar" field of a multipart form, its type is sniffed from the content
+func (app *application) uploadAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "upload avatar")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		app.badRequestResponse(w, r, errors.New("unable to retrieve user"))
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, avatarFormMaxSize)
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("the avatar must be sent in the avatar field of a multipart form and be at most 2MB"))
+		return
+	}
+	defer file.Close()
+	image, err := io.ReadAll(io.LimitReader(file, domainuser.MaxAvatarSize+1))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	profile, err := app.service.user.UploadAvatar(ctx, user.Id, http.DetectContentType(image), image)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to upload avatar", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, usermanagment.ErrAvatarUploadsDisabled) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Avatar uploaded successfully!", profile); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) removeAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "remove avatar")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		app.badRequestResponse(w, r, errors.New("unable to retrieve user"))
+		return
+	}
+	profile, err := app.service.user.RemoveAvatar(ctx, user.Id)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to remove avatar", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Avatar removed successfully!", profile); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,102 @@
+package httpserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	ratelimiter "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/rate-limiter"
+)
+
+// route policies, each route using the limiter declares one of these together with how callers are keyed
+var (
+	// endpoints that send out emails, each has its own bucket so using one does not lock the caller out of the others
+	registerRateLimit           = ratelimiter.Policy{Name: "register", Limit: 5, Window: time.Hour}
+	forgotPasswordRateLimit     = ratelimiter.Policy{Name: "forgot-password", Limit: 5, Window: time.Hour}
+	resendVerificationRateLimit = ratelimiter.Policy{Name: "resend-verification", Limit: 5, Window: time.Hour}
+	magicLinkRateLimit          = ratelimiter.Policy{Name: "magic-link", Limit: 5, Window: time.Hour}
+	emailChangeRateLimit        = ratelimiter.Policy{Name: "email-change", Limit: 5, Window: time.Hour}
+	inviteRateLimit             = ratelimiter.Policy{Name: "invites", Limit: 20, Window: time.Hour}
+	resendInviteRateLimit       = ratelimiter.Policy{Name: "invite-resend", Limit: 20, Window: time.Hour}
+	rosterImportRateLimit       = ratelimiter.Policy{Name: "roster-import", Limit: 10, Window: time.Hour}
+	ownershipTransferRateLimit  = ratelimiter.Policy{Name: "ownership-transfer", Limit: 5, Window: time.Hour}
+	emailDomainClaimRateLimit   = ratelimiter.Policy{Name: "email-domain-claim", Limit: 10, Window: time.Hour}
+
+	// endpoints that redeem an emailed token, each has its own bucket so guessing at one does not use up the others
+	verifyRateLimit                   = ratelimiter.Policy{Name: "verify", Limit: 10, Window: time.Minute * 15}
+	confirmEmailChangeRateLimit       = ratelimiter.Policy{Name: "email-change-confirm", Limit: 10, Window: time.Minute * 15}
+	revertEmailChangeRateLimit        = ratelimiter.Policy{Name: "email-change-revert", Limit: 10, Window: time.Minute * 15}
+	magicLinkLoginRateLimit           = ratelimiter.Policy{Name: "magic-link-login", Limit: 10, Window: time.Minute * 15}
+	acceptInviteRateLimit             = ratelimiter.Policy{Name: "invite-accept", Limit: 10, Window: time.Minute * 15}
+	confirmOwnershipTransferRateLimit = ratelimiter.Policy{Name: "ownership-transfer-confirm", Limit: 10, Window: time.Minute * 15}
+	confirmEmailDomainRateLimit       = ratelimiter.Policy{Name: "email-domain-confirm", Limit: 10, Window: time.Minute * 15}
+	resetPasswordLimit                = ratelimiter.Policy{Name: "reset-password", Limit: 10, Window: time.Minute * 15}
+
+	// endpoints that hand out tokens, refreshing or restoring must not eat into the budget for signing in
+	loginRateLimit          = ratelimiter.Policy{Name: "login", Limit: 20, Window: time.Minute}
+	refreshRateLimit        = ratelimiter.Policy{Name: "refresh", Limit: 20, Window: time.Minute}
+	restoreAccountRateLimit = ratelimiter.Policy{Name: "account-restore", Limit: 10, Window: time.Minute * 15}
+
+	uploadRateLimit    = ratelimiter.Policy{Name: "upload", Limit: 20, Window: time.Hour}
+	authenticatedLimit = ratelimiter.Policy{Name: "authenticated", Limit: 120, Window: time.Minute}
+)
+
+// rateLimitKeyFunc returns the identity a request is counted against
+type rateLimitKeyFunc func(r *http.Request) string
+
+func (app *application) keyByIP(r *http.Request) string {
+	return "ip:" + app.clientIP(r)
+}
+
+// keyByUser counts against the authenticated user, it falls back to the ip on routes without the auth middleware
+func (app *application) keyByUser(r *http.Request) string {
+	if user, ok := getUserFromContext(r.Context()); ok {
+		return "user:" + strconv.Itoa(user.Id)
+	}
+	return app.keyByIP(r)
+}
+
+// keyByApiKey counts against the api key sent in the Authorization header, falling back to the user
+func (app *application) keyByApiKey(r *http.Request) string {
+	if credential, ok := apiKeyFromRequest(r); ok {
+		// never hold on to the raw key
+		sum := sha256.Sum256([]byte(credential))
+		return "apikey:" + hex.EncodeToString(sum[:])
+	}
+	return app.keyByUser(r)
+}
+
+// rateLimit enforces the policy on the route and sets the RateLimit-* headers on every response
+func (app *application) rateLimit(policy ratelimiter.Policy, keyFunc rateLimitKeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			result, err := app.rateLimiter.Allow(ctx, keyFunc(r), policy)
+			if err != nil {
+				// do not take the route down because the limiter is unavailable
+				app.logger.WithContext(ctx).Error("rate limiter error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+			w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Window)))
+
+			if !result.Allowed {
+				app.rateLimitExceededResponse(w, r, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,46 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refreshToken" validate:"required,min=5,max=300"`
+}
+
+func (app *application) refreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	parentTraceCtx, span := app.trace.Start(r.Context(), "refresh token")
+
+	defer span.End()
+
+	var payload RefreshTokenPayload
+	if err := readJson(w, r, &payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error reading refresh token payload as json", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("Error validating refresh token payload", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	userData, err := app.service.user.RefreshSession(parentTraceCtx, payload.RefreshToken)
+	if err != nil {
+		app.logger.WithContext(parentTraceCtx).Error("unable to refresh session", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.unauthorizedErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Token refreshed successfully!", userData); err != nil {
+		app.internalServerError(w, r, err)
+	}
+
+}
@@ -2,10 +2,13 @@ package httpserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	domainuser "github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+
 	"go.opentelemetry.io/otel/attribute"
 
 	"go.opentelemetry.io/otel/codes"
@@ -14,7 +17,7 @@ import (
 type RegisterUserPayload struct {
 	Email    string `json:"email" validate:"required,email,max=255"`
 	Name     string `json:"name" validate:"required,max=100"`
-	Password string `json:"password" validate:"required,min=5,max=17"`
+	Password string `json:"password" validate:"required,max=256"`
 }
 
 func (app *application) registerHandler(w http.ResponseWriter, r *http.Request) {
@@ -50,6 +53,11 @@ func (app *application) registerHandler(w http.ResponseWriter, r *http.Request)
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
+		var policyErr *domainuser.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			app.passwordPolicyViolationResponse(w, r, policyErr)
+			return
+		}
 		app.badRequestResponse(w, r, fmt.Errorf("error registering user: %w", err))
 		return
 	}
@@ -1,15 +1,18 @@
 package httpserver
 
 import (
+	"errors"
 	"net/http"
 
+	domainuser "github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+
 	"go.opentelemetry.io/otel/codes"
 )
 
 type ResetPasswordPayload struct {
 	Email       string `json:"email" validate:"required,email"`
 	Token       string `json:"token" validate:"required"`
-	NewPassword string `json:"password" validate:"required"`
+	NewPassword string `json:"password" validate:"required,max=256"`
 	UserId      int    `json:"uid" validate:"required"`
 }
 
@@ -38,6 +41,11 @@ func (app *application) resetPasswordHandler(w http.ResponseWriter, r *http.Requ
 		app.logger.WithContext(parentTraceCtx).Error("unable to verify user", err)
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		var policyErr *domainuser.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			app.passwordPolicyViolationResponse(w, r, policyErr)
+			return
+		}
 		app.badRequestResponse(w, r, err)
 		return
 	}
@@ -0,0 +1,86 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/application/services/institution"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	rosterFileMaxSize = 10 << 20
+	rosterFormMaxSize = rosterFileMaxSize + 64<<10
+)
+
+// importRosterHandler takes the roster in the file field of a multipart form, ?dryRun=true previews the import.
+// Small rosters are imported straight away, larger ones are accepted and imported in the background.
+func (app *application) importRosterHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "import roster")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		app.unauthorizedErrorResponse(w, r, errors.New("unable to retrieve user"))
+		return
+	}
+	institutionId, err := tenantInstitutionId(ctx)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	dryRun := false
+	if val := r.URL.Query().Get("dryRun"); val != "" {
+		if dryRun, err = strconv.ParseBool(val); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid dryRun: %w", err))
+			return
+		}
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, rosterFormMaxSize)
+	file, header, err := r.FormFile("file")
+	if err != nil || header.Size > rosterFileMaxSize {
+		app.badRequestResponse(w, r, errors.New("the roster must be sent in the file field of a multipart form and be at most 10MB"))
+		return
+	}
+	defer file.Close()
+
+	imported, err := app.service.institution.ImportRoster(ctx, institution.ImportRosterRequest{
+		ActorId:       user.Id,
+		InstitutionId: institutionId,
+		FileName:      header.Filename,
+		Content:       file,
+		Size:          header.Size,
+		DryRun:        dryRun,
+		AcceptUrl:     app.inviteAcceptUrl(),
+	})
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to import roster", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.institutionErrorResponse(w, r, err)
+		return
+	}
+
+	status, message := http.StatusOK, "Roster imported successfully!"
+	switch {
+	case imported.DryRun:
+		message = "Roster import previewed successfully!"
+	case imported.FinishedAt == nil:
+		status, message = http.StatusAccepted, "Roster import started, check its progress for the results"
+	}
+	if err := app.jsonResponse(w, status, message, imported); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getRosterImportHandler() http.HandlerFunc {
+	return app.institutionAction("get roster import", "Roster import retrieved successfully!", http.StatusOK, func(w http.ResponseWriter, r *http.Request, actorId, institutionId int) (any, error) {
+		importId, err := urlParamId(r, "importId")
+		if err != nil {
+			return nil, err
+		}
+		return app.service.institution.GetRosterImport(r.Context(), actorId, institutionId, importId)
+	})
+}
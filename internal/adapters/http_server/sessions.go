@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func (app *application) listSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "list sessions")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		err := errors.New("unable to retrieve user")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	currentSessionId := 0
+	if claims, ok := getClaimsFromContext(ctx); ok {
+		currentSessionId, _ = strconv.Atoi(claims.SessionID)
+	}
+
+	sessions, err := app.service.user.ListSessions(ctx, user.Id, currentSessionId)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to list sessions", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Sessions retrieved successfully!", sessions); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) revokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "revoke session")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		err := errors.New("unable to retrieve user")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	sessionId, err := strconv.Atoi(chi.URLParam(r, "sessionId"))
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid session id"))
+		return
+	}
+
+	err = app.service.user.RevokeSession(ctx, user.Id, sessionId)
+	if errors.Is(err, user_repo.ErrSessionNotFound) {
+		app.notFoundResponse(w, r, err)
+		return
+	}
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to revoke session", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "Session revoked successfully!", nil); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,57 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	usermanagment "github.com/kaasikodes/assessmate_backend/internal/core/application/services/user-managment"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type SwitchInstitutionPayload struct {
+	InstitutionId int `json:"institutionId" validate:"required,min=1"`
+}
+
+// switchInstitutionHandler issues an access token for the current session that only works within the chosen institution
+func (app *application) switchInstitutionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.trace.Start(r.Context(), "switch institution")
+	defer span.End()
+
+	user, ok := getUserFromContext(ctx)
+	if !ok {
+		app.unauthorizedErrorResponse(w, r, errors.New("unable to retrieve user"))
+		return
+	}
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok || claims.SessionID == "" {
+		app.badRequestResponse(w, r, errors.New("switching institution needs a signed in session"))
+		return
+	}
+	sessionId, err := strconv.Atoi(claims.SessionID)
+	if err != nil {
+		app.unauthorizedErrorResponse(w, r, errors.New("invalid session ID in token"))
+		return
+	}
+	var payload SwitchInstitutionPayload
+	if err := readPayload(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	switched, err := app.service.user.SwitchInstitution(ctx, user.Id, sessionId, payload.InstitutionId)
+	if err != nil {
+		app.logger.WithContext(ctx).Error("unable to switch institution", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, usermanagment.ErrNoInstitutionAccess) {
+			app.forbiddenResponse(w, r)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, "Institution switched successfully!", switched); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -2,6 +2,10 @@ package httpserver
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
 	"time"
 
 	usermanagment "github.com/kaasikodes/assessmate_backend/internal/core/application/services/user-managment"
@@ -11,10 +15,17 @@ import (
 const (
 	ExpiresAtVerificationToken = time.Hour * 24 * 5
 	AccessTokenDuration        = time.Duration(time.Hour * 24 * 3)
+	// how often session last seen times are written to the store
+	sessionActivityFlushInterval = time.Second * 30
+	// how often accounts past their deletion grace period are purged
+	accountPurgeInterval = time.Hour
+	// matches how long a magic link stays valid
+	magicLinkCookieMaxAge = time.Minute * 15
 )
 
 type ContextKeyUser struct{}
 type ContextKeyClaims struct{}
+type ContextKeyApiKey struct{}
 
 type paginatedResponse struct {
 	Total  int   `json:"total"`
@@ -41,3 +52,96 @@ func getClaimsFromContext(ctx context.Context) (*jwtport.CustomClaims, bool) {
 	claims, ok := ctx.Value(ContextKeyClaims{}).(*jwtport.CustomClaims)
 	return claims, ok
 }
+
+// getApiKeyFromContext returns the api key the request authenticated with, if it did not use a jwt
+func getApiKeyFromContext(ctx context.Context) (*usermanagment.ApiKey, bool) {
+	key, ok := ctx.Value(ContextKeyApiKey{}).(*usermanagment.ApiKey)
+	return key, ok
+}
+
+// apiKeyFromRequest returns the key sent as "Authorization: ApiKey <key>"
+func apiKeyFromRequest(r *http.Request) (string, bool) {
+	scheme, credential, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "ApiKey") || credential == "" {
+		return "", false
+	}
+	return strings.TrimSpace(credential), true
+}
+
+func (app *application) deviceInfo(r *http.Request) usermanagment.DeviceInfo {
+	return usermanagment.DeviceInfo{
+		Ip:        app.clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// clientIP returns the address of the caller. The X-Forwarded-For and X-Real-IP headers can be set by anyone,
+// so they are only read when the request comes from one of the trusted proxies.
+func (app *application) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !app.isTrustedProxy(host) {
+		return host
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		// each proxy appends the address it got the request from, the first one that is not a trusted proxy is the caller
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if !app.isTrustedProxy(hop) {
+				return hop
+			}
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return host
+}
+
+func (app *application) isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range app.config.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies reads a comma separated list of ips and cidr ranges, e.g. "10.0.0.0/8,192.168.1.10"
+func parseTrustedProxies(val string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %s", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
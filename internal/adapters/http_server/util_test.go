@@ -0,0 +1,54 @@
+package httpserver
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := parseTrustedProxies("10.0.0.0/8, 192.168.1.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &application{config: config{trustedProxies: proxies}}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{name: "direct", remoteAddr: "203.0.113.7:5000", want: "203.0.113.7"},
+		{name: "forwarded header from an untrusted caller is ignored", remoteAddr: "203.0.113.7:5000", forwarded: "198.51.100.1", want: "203.0.113.7"},
+		{name: "real ip header from an untrusted caller is ignored", remoteAddr: "203.0.113.7:5000", realIP: "198.51.100.1", want: "203.0.113.7"},
+		{name: "forwarded through a trusted proxy", remoteAddr: "10.1.2.3:5000", forwarded: "198.51.100.1", want: "198.51.100.1"},
+		{name: "spoofed entries before the real caller are skipped", remoteAddr: "10.1.2.3:5000", forwarded: "1.1.1.1, 198.51.100.1", want: "198.51.100.1"},
+		{name: "trusted hops are skipped", remoteAddr: "10.1.2.3:5000", forwarded: "198.51.100.1, 192.168.1.10", want: "198.51.100.1"},
+		{name: "real ip through a trusted proxy", remoteAddr: "192.168.1.10:5000", realIP: "198.51.100.1", want: "198.51.100.1"},
+		{name: "garbage from a trusted proxy falls back to the proxy", remoteAddr: "10.1.2.3:5000", forwarded: "not-an-ip", want: "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := app.clientIP(r); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxiesRejectsInvalidEntries(t *testing.T) {
+	for _, val := range []string{"10.0.0.0/33", "proxy.internal"} {
+		if _, err := parseTrustedProxies(val); err == nil {
+			t.Errorf("parseTrustedProxies(%q) should fail", val)
+		}
+	}
+}
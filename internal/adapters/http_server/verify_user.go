@@ -36,7 +36,7 @@ func (app *application) verifyHandler(w http.ResponseWriter, r *http.Request) {
 	span.SetAttributes(
 		attribute.String("email", payload.Email),
 	)
-	user, err := app.service.user.VerifyUser(parentTraceCtx, payload.Email, payload.Token)
+	user, err := app.service.user.VerifyUser(parentTraceCtx, payload.Email, payload.Token, app.deviceInfo(r))
 	if err != nil {
 		app.logger.WithContext(parentTraceCtx).Error("unable to verify user", err)
 		span.RecordError(err)
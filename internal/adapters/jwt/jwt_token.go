@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,17 +13,42 @@ import (
 )
 
 type CustomClaims struct {
-	UserID string `json:"sub"`
-	Email  string `json:"email,omitempty"` // Optional field
+	UserID    string `json:"sub"`
+	Email     string `json:"email,omitempty"` // Optional field
+	SessionID string `json:"sid,omitempty"`
+	// InstitutionID scopes the token to one institution
+	InstitutionID string `json:"iid,omitempty"`
+	// Actor is the admin impersonating the subject (RFC 8693 "act" claim)
+	Actor *ActorClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// impersonationAudience marks impersonation tokens so they can't be mistaken for the user's own
+const impersonationAudience = "impersonation"
+
+// JwtMaker signs with one key and verifies with every key in its key set, so keys can be rotated
+// without invalidating tokens signed by the previous key.
 type JwtMaker struct {
-	secretKey string
+	keys       *keySet
+	signingKey *signingKey
 }
 
-func NewJwtMaker(secret string) *JwtMaker {
-	return &JwtMaker{secretKey: secret}
+// NewJwtMaker loads the keys in keysDir and signs with signingKid, or with the last key by name when it is empty.
+// It fails when there is no private key to sign with.
+func NewJwtMaker(keysDir, signingKid string) (*JwtMaker, error) {
+	keys, err := loadKeySet(keysDir)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := keys.signer(signingKid)
+	if err != nil {
+		return nil, err
+	}
+	return &JwtMaker{keys: keys, signingKey: signer}, nil
 }
 
 var (
@@ -32,19 +58,63 @@ var (
 	ErrWrongFormat  = errors.New("authorization header format must be Bearer {token}")
 )
 
-// CreateToken generates a JWT signed with HS256
-func (j *JwtMaker) CreateToken(userID, userEmail string, duration time.Duration) (string, error) {
+// CreateToken generates a JWT signed with the current signing key, its kid is set in the header
+func (j *JwtMaker) CreateToken(userID, userEmail, sessionID string, duration time.Duration) (string, error) {
+	claims := CustomClaims{
+		UserID:    userID,
+		Email:     userEmail,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return j.sign(claims)
+}
+
+// CreateTenantToken generates a JWT like CreateToken that can only be used within the institution
+func (j *JwtMaker) CreateTenantToken(userID, userEmail, sessionID, institutionID string, duration time.Duration) (string, error) {
+	if institutionID == "" {
+		return "", errors.New("tenant token needs an institution")
+	}
+	claims := CustomClaims{
+		UserID:        userID,
+		Email:         userEmail,
+		SessionID:     sessionID,
+		InstitutionID: institutionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return j.sign(claims)
+}
+
+// CreateImpersonationToken generates a JWT for the admin to act as the user, the admin is the token's actor
+func (j *JwtMaker) CreateImpersonationToken(userID, userEmail, impersonatorID string, duration time.Duration) (string, error) {
+	if impersonatorID == "" {
+		return "", errors.New("impersonation token needs an impersonator")
+	}
 	claims := CustomClaims{
 		UserID: userID,
 		Email:  userEmail,
+		Actor:  &ActorClaim{Subject: impersonatorID},
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   userID,
+			Audience:  jwt.ClaimStrings{impersonationAudience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	return j.sign(claims)
+}
+
+func (j *JwtMaker) sign(claims CustomClaims) (string, error) {
+	token := jwt.NewWithClaims(j.signingKey.method, claims)
+	token.Header["kid"] = j.signingKey.kid
+	return token.SignedString(j.signingKey.private)
 }
 
 // VerifyToken parses and validates the JWT token
@@ -52,11 +122,17 @@ func (j *JwtMaker) VerifyToken(tokenStr string) (*jwtport.CustomClaims, error) {
 	claims := &CustomClaims{}
 
 	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := j.keys.get(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		// the algorithm comes from our key, never from the token
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(j.secretKey), nil
-	})
+		return key.public, nil
+	}, jwt.WithValidMethods(j.keys.algorithms()))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -69,11 +145,26 @@ func (j *JwtMaker) VerifyToken(tokenStr string) (*jwtport.CustomClaims, error) {
 		return nil, ErrInvalidToken
 	}
 
-	if claims.ExpiresAt.Time.Before(time.Now()) {
+	if claims.ExpiresAt == nil || claims.ExpiresAt.Time.Before(time.Now()) {
 		return nil, ErrExpiredToken
 	}
 
-	return &jwtport.CustomClaims{UserID: claims.UserID, Email: claims.Email}, nil
+	result := &jwtport.CustomClaims{UserID: claims.UserID, Email: claims.Email, SessionID: claims.SessionID, InstitutionID: claims.InstitutionID}
+	if claims.Actor != nil {
+		// an actor without the audience (or the reverse) was not issued by CreateImpersonationToken
+		if claims.Actor.Subject == "" || !slices.Contains(claims.Audience, impersonationAudience) {
+			return nil, ErrInvalidToken
+		}
+		result.ImpersonatorID = claims.Actor.Subject
+	} else if slices.Contains(claims.Audience, impersonationAudience) {
+		return nil, ErrInvalidToken
+	}
+	return result, nil
+}
+
+// JWKS returns the public half of every key so other services can verify our tokens
+func (j *JwtMaker) JWKS() jwtport.JSONWebKeySet {
+	return j.keys.jwks()
 }
 
 // ExtractToken extracts token from Authorization header
@@ -0,0 +1,162 @@
+package jwttoken
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	jwtport "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/jwt"
+)
+
+const minRSAKeyBits = 2048
+
+// key is one PEM file in the key directory, its kid is the file name without the .pem extension.
+// A file holding only a public key verifies tokens but never signs, which is how a retired key is kept
+// until the tokens it signed have expired.
+type key struct {
+	kid     string
+	method  jwt.SigningMethod
+	public  crypto.PublicKey
+	private crypto.PrivateKey
+}
+
+type signingKey = key
+
+type keySet struct {
+	keys map[string]*key
+	kids []string // sorted
+}
+
+func loadKeySet(dir string) (*keySet, error) {
+	if dir == "" {
+		return nil, errors.New("no jwt key directory configured")
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing jwt keys: %w", err)
+	}
+	set := &keySet{keys: make(map[string]*key)}
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		k, err := loadKey(kid, path)
+		if err != nil {
+			return nil, fmt.Errorf("error loading jwt key %s: %w", kid, err)
+		}
+		set.keys[kid] = k
+		set.kids = append(set.kids, kid)
+	}
+	sort.Strings(set.kids)
+	return set, nil
+}
+
+func loadKey(kid, path string) (*key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block: %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k := &key{kid: kid}
+	switch v := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.public, k.private = jwt.SigningMethodRS256, &v.PublicKey, v
+	case *rsa.PublicKey:
+		k.method, k.public = jwt.SigningMethodRS256, v
+	case ed25519.PrivateKey:
+		k.method, k.public, k.private = jwt.SigningMethodEdDSA, v.Public(), v
+	case ed25519.PublicKey:
+		k.method, k.public = jwt.SigningMethodEdDSA, v
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+	if rsaKey, ok := k.public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSAKeyBits)
+	}
+	return k, nil
+}
+
+func (s *keySet) get(kid string) (*key, bool) {
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+// signer returns the key to sign with, the last private key by name when kid is empty
+func (s *keySet) signer(kid string) (*signingKey, error) {
+	if kid != "" {
+		k, ok := s.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("jwt signing key %s not found", kid)
+		}
+		if k.private == nil {
+			return nil, fmt.Errorf("jwt signing key %s has no private key", kid)
+		}
+		return k, nil
+	}
+	for i := len(s.kids) - 1; i >= 0; i-- {
+		if k := s.keys[s.kids[i]]; k.private != nil {
+			return k, nil
+		}
+	}
+	return nil, errors.New("no jwt signing key configured")
+}
+
+func (s *keySet) algorithms() []string {
+	seen := map[string]bool{}
+	var algs []string
+	for _, k := range s.keys {
+		if alg := k.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	return algs
+}
+
+func (s *keySet) jwks() jwtport.JSONWebKeySet {
+	set := jwtport.JSONWebKeySet{Keys: []jwtport.JSONWebKey{}}
+	for _, kid := range s.kids {
+		k := s.keys[kid]
+		jwk := jwtport.JSONWebKey{Kid: kid, Use: "sig", Alg: k.method.Alg()}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
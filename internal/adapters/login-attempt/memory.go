@@ -0,0 +1,72 @@
+package loginattemptadapter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	loginattempt "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/login-attempt"
+)
+
+type record struct {
+	failures     int
+	lastFailedAt *time.Time
+	lockedUntil  *time.Time
+}
+
+// MemoryLoginAttemptStore keeps attempts in process memory, only suitable for a single node.
+type MemoryLoginAttemptStore struct {
+	mu      sync.Mutex
+	records map[user.LoginAttemptKey]record
+}
+
+func NewMemoryLoginAttemptStore() loginattempt.LoginAttemptStore {
+	return &MemoryLoginAttemptStore{records: make(map[user.LoginAttemptKey]record)}
+}
+
+func (m *MemoryLoginAttemptStore) GetLoginAttempt(ctx context.Context, key user.LoginAttemptKey) (*user.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.load(key), nil
+}
+
+func (m *MemoryLoginAttemptStore) RecordLoginFailure(ctx context.Context, key user.LoginAttemptKey, now time.Time, policy user.LockoutPolicy) (*user.LoginAttempt, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempt := m.load(key)
+	locked := attempt.RecordFailure(now, policy)
+	m.records[key] = record{
+		failures:     attempt.Failures(),
+		lastFailedAt: attempt.LastFailedAt(),
+		lockedUntil:  attempt.LockedUntil(),
+	}
+	return attempt, locked, nil
+}
+
+func (m *MemoryLoginAttemptStore) DeleteLoginAttempt(ctx context.Context, key user.LoginAttemptKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+	return nil
+}
+
+// load expects the caller to hold the lock
+func (m *MemoryLoginAttemptStore) load(key user.LoginAttemptKey) *user.LoginAttempt {
+	attempt := user.NewLoginAttempt(key)
+	rec, ok := m.records[key]
+	if !ok {
+		return attempt
+	}
+	attempt.SetFailures(rec.failures)
+	if rec.lastFailedAt != nil {
+		attempt.SetLastFailedAt(*rec.lastFailedAt)
+	}
+	if rec.lockedUntil != nil {
+		attempt.SetLockedUntil(*rec.lockedUntil)
+	}
+	return attempt
+}
@@ -0,0 +1,81 @@
+package ratelimiteradapter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	ratelimiter "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/rate-limiter"
+)
+
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+	window     time.Duration
+}
+
+// TokenBucketLimiter is an in-memory token bucket limiter, each policy+key pair gets its own bucket
+// holding up to policy.Limit tokens that refill evenly across policy.Window.
+type TokenBucketLimiter struct {
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+func NewTokenBucketLimiter() ratelimiter.RateLimiter {
+	return &TokenBucketLimiter{
+		buckets:     make(map[string]*bucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+func (l *TokenBucketLimiter) Allow(ctx context.Context, key string, policy ratelimiter.Policy) (ratelimiter.Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.cleanup(now)
+
+	limit := float64(policy.Limit)
+	ratePerSecond := limit / policy.Window.Seconds()
+	bucketKey := policy.Name + ":" + key
+
+	b, ok := l.buckets[bucketKey]
+	if !ok {
+		b = &bucket{tokens: limit, lastRefill: now, window: policy.Window}
+		l.buckets[bucketKey] = b
+	}
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.tokens = math.Min(limit, b.tokens+elapsed*ratePerSecond)
+	b.lastRefill = now
+
+	result := ratelimiter.Result{Limit: policy.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / ratePerSecond)
+	}
+	result.Remaining = int(math.Floor(b.tokens))
+	result.ResetAfter = secondsToDuration((limit - b.tokens) / ratePerSecond)
+
+	return result, nil
+}
+
+// cleanup drops buckets that have been idle long enough to be full again, it runs at most once a minute
+func (l *TokenBucketLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < time.Minute {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastRefill) > b.window {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+)
+
+func (r *MySqlRepo) UpdateUserDeletedAt(ctx context.Context, u *user.User) (*user.User, error) {
+	query := `UPDATE users SET deleted_at = ?, updated_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, u.GetDeletedAt(), u.GetUpdatedAt(), u.GetId().Value())
+	return u, err
+}
+
+func (r *MySqlRepo) GetUsersDeletedBefore(ctx context.Context, cutoff user.DateTime) ([]user.User, error) {
+	query := `SELECT id, name, email, status, password, created_at, updated_at, verified_at, deleted_at FROM users WHERE deleted_at IS NOT NULL AND deleted_at < ? AND purged_at IS NULL`
+	rows, err := r.db.QueryContext(ctx, query, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []user.User
+	for rows.Next() {
+		u, err := r.scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, rows.Err()
+}
+
+type statement struct {
+	query string
+	args  []interface{}
+}
+
+// PurgeUser runs in a single transaction so a user is never left half anonymised
+func (r *MySqlRepo) PurgeUser(ctx context.Context, u *user.User) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the email still on record is needed to find the account's login attempts
+	var currentEmail string
+	if err := tx.QueryRowContext(ctx, `SELECT email FROM users WHERE id = ? FOR UPDATE`, u.GetId().Value()).Scan(&currentEmail); err != nil {
+		return err
+	}
+
+	// staff records linked to the account or held under its email are the person's too, as are the invites sent to them
+	staffIds, err := staffIdsOfPerson(ctx, tx, u.GetId().Value(), currentEmail)
+	if err != nil {
+		return err
+	}
+	invites := statement{`DELETE FROM institution_invites WHERE email = ?`, []interface{}{currentEmail}}
+	if len(staffIds) > 0 {
+		in, ids := idList(staffIds)
+		invites.query += ` OR staff_id IN (` + in + `)`
+		invites.args = append(invites.args, ids...)
+	}
+
+	now := time.Now()
+	statements := []statement{
+		{`UPDATE users SET name = ?, email = ?, password = ?, status = ?, updated_at = ?, purged_at = ? WHERE id = ?`,
+			[]interface{}{u.GetName().String(), u.GetEmail().String(), string(u.PasswordHash()), u.GetStatus().String(), u.GetUpdatedAt(), now, u.GetId().Value()}},
+		{`DELETE FROM tokens WHERE user_id = ?`, []interface{}{u.GetId().Value()}},
+		{`DELETE FROM sessions WHERE user_id = ?`, []interface{}{u.GetId().Value()}},
+		{`DELETE FROM email_changes WHERE user_id = ?`, []interface{}{u.GetId().Value()}},
+		{`DELETE FROM api_keys WHERE user_id = ?`, []interface{}{u.GetId().Value()}},
+		{`DELETE FROM user_profiles WHERE user_id = ?`, []interface{}{u.GetId().Value()}},
+		invites,
+		// the staff records held the user's name and email, the institution keeps no trace of them
+		{`DELETE FROM institution_staff WHERE user_id = ? OR email = ?`, []interface{}{u.GetId().Value(), currentEmail}},
+		// the audit log keeps what happened, the ip and user agent the user acted from go. An admin acting as the user
+		// made the entry from their own ip, so those are kept
+		{`UPDATE audit_logs SET ip = '', user_agent = '' WHERE (actor_id = ? AND impersonator_id = 0) OR impersonator_id = ?`, []interface{}{u.GetId().Value(), u.GetId().Value()}},
+		{`DELETE FROM userRoles WHERE userId = ?`, []interface{}{u.GetId().Value()}},
+	}
+	if key, err := user.NewAccountLoginAttemptKey(user.Email(currentEmail)); err == nil {
+		statements = append(statements, statement{`DELETE FROM login_attempts WHERE attempt_key = ?`, []interface{}{key.String()}})
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func staffIdsOfPerson(ctx context.Context, tx *sql.Tx, userId int, email string) ([]institution.Id, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM institution_staff WHERE user_id = ? OR email = ? FOR UPDATE`, userId, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []institution.Id
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, institution.Id(id))
+	}
+	return ids, rows.Err()
+}
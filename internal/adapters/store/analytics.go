@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+)
+
+func (r *MySqlRepo) GetStaffActivity(ctx context.Context, institutionId institution.Id) (*institution.StaffActivity, error) {
+	query := `SELECT status, COUNT(*) FROM institution_staff WHERE institution_id = ? AND deleted_at IS NULL GROUP BY status`
+	rows, err := r.db.QueryContext(ctx, query, institutionId.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := &institution.StaffActivity{}
+	for rows.Next() {
+		var (
+			status string
+			count  int
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		switch institution.StaffStatus(status) {
+		case institution.Active:
+			activity.Active = count
+		case institution.InActive:
+			activity.Inactive = count
+		case institution.Suspended:
+			activity.Suspended = count
+		case institution.Blacklisted:
+			activity.Blacklisted = count
+		}
+	}
+	return activity, rows.Err()
+}
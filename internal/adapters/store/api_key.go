@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+)
+
+const apiKeyColumns = `id, user_id, institution_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func (r *MySqlRepo) CreateApiKey(ctx context.Context, k *user.ApiKey) (*user.ApiKey, error) {
+	var institutionId interface{}
+	if k.InstitutionId() != nil {
+		institutionId = k.InstitutionId().Value()
+	}
+	query := `INSERT INTO api_keys (user_id, institution_id, name, prefix, key_hash, scopes, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, k.UserId().Value(), institutionId, k.Name(), k.Prefix(), k.Hash(), joinScopes(k.Scopes()), k.ExpiresAt(), k.CreatedAt())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := user.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	k.SetId(parsedId)
+	return k, nil
+}
+
+func (r *MySqlRepo) GetApiKeyByPrefix(ctx context.Context, prefix string) (*user.ApiKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = ?`
+	k, err := r.scanApiKey(r.db.QueryRowContext(ctx, query, prefix))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, user_repo.ErrApiKeyNotFound
+	}
+	return k, err
+}
+
+func (r *MySqlRepo) ListApiKeys(ctx context.Context, userId user.Id) ([]user.ApiKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = ? AND revoked_at IS NULL ORDER BY created_at DESC`
+	rows, err := r.db.QueryContext(ctx, query, userId.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []user.ApiKey
+	for rows.Next() {
+		k, err := r.scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *MySqlRepo) RevokeApiKey(ctx context.Context, userId, keyId user.Id) error {
+	query := `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, time.Now(), keyId.Value(), userId.Value())
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return user_repo.ErrApiKeyNotFound
+	}
+	return nil
+}
+
+func (r *MySqlRepo) UpdateApiKeyLastUsedAt(ctx context.Context, k *user.ApiKey) error {
+	query := `UPDATE api_keys SET last_used_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, k.LastUsedAt(), k.Id().Value())
+	return err
+}
+
+func (r *MySqlRepo) scanApiKey(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*user.ApiKey, error) {
+	var (
+		id, userId                       int
+		institutionId                    sql.NullInt64
+		name, prefix, hash, scopes       string
+		expiresAt, lastUsedAt, revokedAt sql.NullTime
+		createdAt                        time.Time
+	)
+	if err := scanner.Scan(&id, &userId, &institutionId, &name, &prefix, &hash, &scopes, &expiresAt, &lastUsedAt, &revokedAt, &createdAt); err != nil {
+		return nil, err
+	}
+	uid, err := user.NewId(userId)
+	if err != nil {
+		return nil, err
+	}
+	var parsedScopes []user.ApiKeyScope
+	for _, s := range strings.Split(scopes, ",") {
+		// scopes that are no longer known are dropped rather than failing the key
+		if scope, err := user.NewApiKeyScope(s); err == nil {
+			parsedScopes = append(parsedScopes, scope)
+		}
+	}
+	k, err := user.NewApiKey(uid, name, parsedScopes, nil)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := user.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	k.SetId(kid)
+	k.SetPrefix(prefix)
+	k.SetHash(hash)
+	k.SetCreatedAt(createdAt)
+	if institutionId.Valid {
+		k.SetInstitutionId(user.Id(institutionId.Int64))
+	}
+	if expiresAt.Valid {
+		k.SetExpiresAt(expiresAt.Time)
+	}
+	if lastUsedAt.Valid {
+		k.SetLastUsedAt(lastUsedAt.Time)
+	}
+	if revokedAt.Valid {
+		k.Revoke(revokedAt.Time)
+	}
+	return k, nil
+}
+
+func joinScopes(scopes []user.ApiKeyScope) string {
+	parts := make([]string, len(scopes))
+	for i, s := range scopes {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, ",")
+}
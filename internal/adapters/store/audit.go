@@ -0,0 +1,172 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/audit"
+)
+
+const auditColumns = `id, actor_id, impersonator_id, action, target_type, target_id, institution_id, ip, user_agent, request_id, changes, created_at`
+
+func (r *MySqlRepo) AppendAuditEntry(ctx context.Context, e *audit.Entry) (*audit.Entry, error) {
+	var changes interface{}
+	if len(e.Changes()) > 0 {
+		data, err := json.Marshal(e.Changes())
+		if err != nil {
+			return nil, err
+		}
+		changes = string(data)
+	}
+	query := `INSERT INTO audit_logs (actor_id, impersonator_id, action, target_type, target_id, institution_id, ip, user_agent, request_id, changes, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, e.ActorId(), e.ImpersonatorId(), e.Action(), e.TargetType(), e.TargetId(), e.InstitutionId(), e.Ip(), e.UserAgent(), e.RequestId(), changes, e.CreatedAt())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	e.SetId(int(id))
+	return e, nil
+}
+
+func (r *MySqlRepo) GetAuditEntries(ctx context.Context, filter audit.Filter) ([]audit.Entry, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.ActorId != nil {
+		conditions = append(conditions, `actor_id = ?`)
+		args = append(args, *filter.ActorId)
+	}
+	if filter.ImpersonatorId != nil {
+		conditions = append(conditions, `impersonator_id = ?`)
+		args = append(args, *filter.ImpersonatorId)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, `action = ?`)
+		args = append(args, filter.Action)
+	}
+	if filter.TargetType != "" {
+		conditions = append(conditions, `target_type = ?`)
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetId != "" {
+		conditions = append(conditions, `target_id = ?`)
+		args = append(args, filter.TargetId)
+	}
+	if filter.InstitutionId != nil {
+		conditions = append(conditions, `institution_id = ?`)
+		args = append(args, *filter.InstitutionId)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, `created_at >= ?`)
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, `created_at < ?`)
+		args = append(args, *filter.To)
+	}
+	var whereClause string
+	if len(conditions) > 0 {
+		whereClause = ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_logs`+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	selectQuery := `SELECT ` + auditColumns + ` FROM audit_logs` + whereClause + ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		selectQuery += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+	rows, err := r.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []audit.Entry
+	for rows.Next() {
+		e, err := r.scanAuditEntry(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, total, rows.Err()
+}
+
+func (r *MySqlRepo) ListAuditEntriesAboutUser(ctx context.Context, userId int, staffIds []int) ([]audit.Entry, error) {
+	// the target types are the ones the audit log service records users and staff under
+	query := `SELECT ` + auditColumns + ` FROM audit_logs WHERE actor_id = ? OR impersonator_id = ? OR (target_type = 'user' AND target_id = ?)`
+	args := []interface{}{userId, userId, strconv.Itoa(userId)}
+	if len(staffIds) > 0 {
+		query += ` OR (target_type = 'staff' AND target_id IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(staffIds)), ", ") + `))`
+		for _, id := range staffIds {
+			args = append(args, strconv.Itoa(id))
+		}
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []audit.Entry{}
+	for rows.Next() {
+		e, err := r.scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *MySqlRepo) scanAuditEntry(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*audit.Entry, error) {
+	var (
+		id, actorId, impersonatorId                            int
+		action, targetType, targetId, ip, userAgent, requestId string
+		institutionId                                          sql.NullInt64
+		rawChanges                                             sql.NullString
+		createdAt                                              time.Time
+	)
+	if err := scanner.Scan(&id, &actorId, &impersonatorId, &action, &targetType, &targetId, &institutionId, &ip, &userAgent, &requestId, &rawChanges, &createdAt); err != nil {
+		return nil, err
+	}
+	params := audit.EntryParams{
+		ActorId:        actorId,
+		ImpersonatorId: impersonatorId,
+		Action:         action,
+		TargetType:     targetType,
+		TargetId:       targetId,
+		Ip:             ip,
+		UserAgent:      userAgent,
+		RequestId:      requestId,
+	}
+	if institutionId.Valid {
+		iid := int(institutionId.Int64)
+		params.InstitutionId = &iid
+	}
+	if rawChanges.Valid {
+		if err := json.Unmarshal([]byte(rawChanges.String), &params.Changes); err != nil {
+			return nil, err
+		}
+	}
+	e, err := audit.NewEntry(params)
+	if err != nil {
+		return nil, err
+	}
+	e.SetId(id)
+	e.SetCreatedAt(createdAt)
+	return e, nil
+}
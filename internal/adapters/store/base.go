@@ -1,23 +1,55 @@
 package store
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
 	// sub_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/subscription"
+	audit_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/audit"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
 	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/logger"
+	loginattempt "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/login-attempt"
 	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
 )
 
 type StoreCombinedRepository interface {
 	user_repo.UserRepository
+	loginattempt.LoginAttemptStore
+	audit_repo.AuditLogRepository
+	institute_repo.InstitutionRepository
+	institute_repo.MembershipReader
+	institute_repo.PersonalDataReader
+	institute_repo.EmailDomainJoiner
 	// sub_repo.SubscriptionRepository
 }
 type MySqlRepo struct {
 	db     *sql.DB
 	logger logger.Logger
+	// key for the HMAC tokens are stored as, a leaked tokens table is useless without it
+	tokenHashKey []byte
 }
 
-func NewUserRepository(db *sql.DB, logger logger.Logger) StoreCombinedRepository {
-	return &MySqlRepo{db, logger}
+func NewUserRepository(db *sql.DB, logger logger.Logger, tokenHashKey []byte) StoreCombinedRepository {
+	return &MySqlRepo{db, logger, tokenHashKey}
 
 }
+
+// hashToken returns the keyed hash a token value is stored and looked up by
+func (r *MySqlRepo) hashToken(value user.TokenValue) string {
+	return r.hashSecret(value.String())
+}
+
+// hashSecret is hashToken for secrets that are not user tokens, e.g. invite tokens
+func (r *MySqlRepo) hashSecret(value string) string {
+	mac := hmac.New(sha256.New, r.tokenHashKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// tokenHashMatches compares a stored hash with the value in constant time
+func (r *MySqlRepo) tokenHashMatches(stored string, value user.TokenValue) bool {
+	return hmac.Equal([]byte(stored), []byte(r.hashToken(value)))
+}
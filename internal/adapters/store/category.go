@@ -0,0 +1,206 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+)
+
+const categoryColumns = `id, parent_id, kind, name, created_at, updated_at`
+
+func (r *MySqlRepo) CreateCategory(ctx context.Context, institutionId institution.Id, category *institution.Category) (*institution.Category, error) {
+	query := `INSERT INTO institution_categories (institution_id, parent_id, kind, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, institutionId.Value(), nullableId(category.ParentId()), category.Kind().String(), category.Name().String(), category.CreatedAt(), category.UpdatedAt())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	category.SetId(parsedId)
+	return category, nil
+}
+
+func (r *MySqlRepo) ListCategories(ctx context.Context, institutionId institution.Id) ([]institution.Category, error) {
+	query := `SELECT ` + categoryColumns + ` FROM institution_categories WHERE institution_id = ? ORDER BY name, id`
+	rows, err := r.db.QueryContext(ctx, query, institutionId.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []institution.Category{}
+	for rows.Next() {
+		c, err := r.scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *MySqlRepo) UpdateCategory(ctx context.Context, institutionId institution.Id, category *institution.Category) error {
+	query := `UPDATE institution_categories SET parent_id = ?, name = ?, updated_at = ? WHERE id = ? AND institution_id = ?`
+	res, err := r.db.ExecContext(ctx, query, nullableId(category.ParentId()), category.Name().String(), category.UpdatedAt(), category.Id().Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrCategoryNotFound)
+}
+
+func (r *MySqlRepo) DeleteCategory(ctx context.Context, institutionId, categoryId institution.Id, newParentId *institution.Id) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	newParent := nullableId(newParentId)
+	if _, err := tx.ExecContext(ctx, `UPDATE institution_categories SET parent_id = ?, updated_at = ? WHERE parent_id = ? AND institution_id = ?`, newParent, now, categoryId.Value(), institutionId.Value()); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE institution_courses SET category_id = ?, updated_at = ? WHERE category_id = ? AND institution_id = ?`, newParent, now, categoryId.Value(), institutionId.Value()); err != nil {
+		return err
+	}
+	if newParentId != nil {
+		// the staff already in the new parent are left where they are, the delete below drops their old membership
+		if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO category_staff (category_id, staff_id, created_at) SELECT ?, staff_id, ? FROM category_staff WHERE category_id = ?`, newParentId.Value(), now, categoryId.Value()); err != nil {
+			return err
+		}
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM institution_categories WHERE id = ? AND institution_id = ?`, categoryId.Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res, institute_repo.ErrCategoryNotFound); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddStaffToCategory is idempotent, both the category and the staff must belong to the institution
+func (r *MySqlRepo) AddStaffToCategory(ctx context.Context, institutionId, categoryId, staffId institution.Id) error {
+	if err := r.categoryExists(ctx, institutionId, categoryId); err != nil {
+		return err
+	}
+	if _, err := r.GetStaffById(ctx, institutionId, staffId); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `INSERT IGNORE INTO category_staff (category_id, staff_id, created_at) VALUES (?, ?, ?)`, categoryId.Value(), staffId.Value(), time.Now())
+	return err
+}
+
+func (r *MySqlRepo) RemoveStaffFromCategory(ctx context.Context, institutionId, categoryId, staffId institution.Id) error {
+	query := `DELETE cs FROM category_staff cs JOIN institution_categories c ON c.id = cs.category_id WHERE cs.category_id = ? AND cs.staff_id = ? AND c.institution_id = ?`
+	res, err := r.db.ExecContext(ctx, query, categoryId.Value(), staffId.Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrStaffNotFound)
+}
+
+func (r *MySqlRepo) ListStaffInCategories(ctx context.Context, institutionId institution.Id, categoryIds []institution.Id) ([]institution.Staff, error) {
+	staff := []institution.Staff{}
+	if len(categoryIds) == 0 {
+		return staff, nil
+	}
+	in, args := idList(categoryIds)
+	query := `SELECT ` + staffColumns + ` FROM institution_staff WHERE institution_id = ? AND deleted_at IS NULL
+		AND id IN (SELECT staff_id FROM category_staff WHERE category_id IN (` + in + `)) ORDER BY name, id`
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{institutionId.Value()}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		s, err := r.scanStaff(rows)
+		if err != nil {
+			return nil, err
+		}
+		staff = append(staff, *s)
+	}
+	return staff, rows.Err()
+}
+
+func (r *MySqlRepo) SetCourseCategory(ctx context.Context, institutionId institution.Id, course *institution.Course) error {
+	query := `UPDATE institution_courses SET category_id = ?, updated_at = ? WHERE id = ? AND institution_id = ?`
+	res, err := r.db.ExecContext(ctx, query, nullableId(course.CategoryId()), course.UpdatedAt(), course.Id().Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrCourseNotFound)
+}
+
+func (r *MySqlRepo) categoryExists(ctx context.Context, institutionId, categoryId institution.Id) error {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM institution_categories WHERE id = ? AND institution_id = ?)`
+	if err := r.db.QueryRowContext(ctx, query, categoryId.Value(), institutionId.Value()).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return institute_repo.ErrCategoryNotFound
+	}
+	return nil
+}
+
+// idList returns the placeholders and arguments of an IN clause
+func idList(ids []institution.Id) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id.Value()
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), args
+}
+
+// nullableId stores a missing id as NULL
+func nullableId(id *institution.Id) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.Value()
+}
+
+func idPtr(id int) *institution.Id {
+	parsed := institution.Id(id)
+	return &parsed
+}
+
+func (r *MySqlRepo) scanCategory(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*institution.Category, error) {
+	var (
+		id                   int
+		parentId             sql.NullInt64
+		kind, name           string
+		createdAt, updatedAt time.Time
+	)
+	if err := scanner.Scan(&id, &parentId, &kind, &name, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	var parent *institution.Id
+	if parentId.Valid {
+		parent = idPtr(int(parentId.Int64))
+	}
+	c, err := institution.NewCategory(institution.Name(name), institution.CategoryKind(kind), parent)
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	c.SetId(parsedId)
+	c.SetTimestamps(createdAt, updatedAt)
+	return c, nil
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+)
+
+func (r *MySqlRepo) UpdateUserEmail(ctx context.Context, u *user.User) (*user.User, error) {
+	query := `UPDATE users SET email = ?, updated_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, u.GetEmail().String(), u.GetUpdatedAt(), u.GetId().Value())
+	return u, err
+}
+
+func (r *MySqlRepo) CreateEmailChange(ctx context.Context, c *user.EmailChange) (*user.EmailChange, error) {
+	query := `
+		INSERT INTO email_changes (user_id, old_email, new_email, status, revertable_until, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	res, err := r.db.ExecContext(ctx, query, c.UserId().Value(), c.OldEmail().String(), c.NewEmail().String(), c.Status().String(), c.RevertableUntil(), c.CreatedAt(), c.UpdatedAt())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := user.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	c.SetId(parsedId)
+	return c, nil
+}
+
+func (r *MySqlRepo) ListEmailChanges(ctx context.Context, userId user.Id) ([]user.EmailChange, error) {
+	query := `
+		SELECT id, user_id, old_email, new_email, status, revertable_until, created_at, updated_at
+		FROM email_changes WHERE user_id = ? ORDER BY created_at DESC, id DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userId.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []user.EmailChange
+	for rows.Next() {
+		c, err := r.scanEmailChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, *c)
+	}
+	return changes, rows.Err()
+}
+
+func (r *MySqlRepo) UpdateEmailChange(ctx context.Context, c *user.EmailChange) error {
+	query := `UPDATE email_changes SET status = ?, updated_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, c.Status().String(), c.UpdatedAt(), c.Id().Value())
+	return err
+}
+
+func (r *MySqlRepo) GetEmailChangeById(ctx context.Context, userId, changeId user.Id) (*user.EmailChange, error) {
+	query := `
+		SELECT id, user_id, old_email, new_email, status, revertable_until, created_at, updated_at
+		FROM email_changes WHERE id = ? AND user_id = ?
+	`
+	c, err := r.scanEmailChange(r.db.QueryRowContext(ctx, query, changeId.Value(), userId.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, user_repo.ErrEmailChangeNotFound
+	}
+	return c, err
+}
+
+func (r *MySqlRepo) CancelPendingEmailChanges(ctx context.Context, userId user.Id) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deleteTokens := `DELETE FROM tokens WHERE user_id = ? AND email_change_id IN (SELECT id FROM email_changes WHERE user_id = ? AND status = ?)`
+	if _, err := tx.ExecContext(ctx, deleteTokens, userId.Value(), userId.Value(), user.EmailChangePending.String()); err != nil {
+		return err
+	}
+	query := `UPDATE email_changes SET status = ?, updated_at = ? WHERE user_id = ? AND status = ?`
+	if _, err := tx.ExecContext(ctx, query, user.EmailChangeCancelled.String(), time.Now(), userId.Value(), user.EmailChangePending.String()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *MySqlRepo) CreateEmailChangeToken(ctx context.Context, value user.TokenValue, tokenType user.TokenType, userId, changeId user.Id, expiresAt user.DateTime) (*user.Token, error) {
+	query := `INSERT INTO tokens (value, type, user_id, email_change_id, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, r.hashToken(value), tokenType.String(), userId.Value(), changeId.Value(), time.Now(), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	id, _ := res.LastInsertId()
+	parsedId, err := user.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	token, err := user.NewToken(value, tokenType, userId)
+	if err != nil {
+		return nil, err
+	}
+	token.SetId(parsedId)
+	token.SetEmailChangeId(changeId)
+	token.SetExpiresAt(expiresAt)
+	return token, nil
+}
+
+func (r *MySqlRepo) DeleteEmailChangeTokens(ctx context.Context, userId user.Id) error {
+	query := `DELETE FROM tokens WHERE user_id = ? AND type IN (?, ?)`
+	_, err := r.db.ExecContext(ctx, query, userId.Value(), user.EmailChangeConfirmation.String(), user.EmailChangeRevert.String())
+	return err
+}
+
+func (r *MySqlRepo) scanEmailChange(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*user.EmailChange, error) {
+	var (
+		id, uid                    int
+		oldEmail, newEmail, status string
+		revertableUntil            time.Time
+		createdAt, updatedAt       time.Time
+	)
+	if err := scanner.Scan(&id, &uid, &oldEmail, &newEmail, &status, &revertableUntil, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	parsedUserId, err := user.NewId(uid)
+	if err != nil {
+		return nil, err
+	}
+	parsedOld, err := user.NewEmail(oldEmail)
+	if err != nil {
+		return nil, err
+	}
+	parsedNew, err := user.NewEmail(newEmail)
+	if err != nil {
+		return nil, err
+	}
+	parsedStatus, err := user.NewEmailChangeStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	c, err := user.NewEmailChange(parsedUserId, parsedOld, parsedNew, revertableUntil)
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := user.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	c.SetId(parsedId)
+	c.SetStatus(parsedStatus)
+	c.SetCreatedAt(createdAt)
+	c.SetUpdatedAt(updatedAt)
+	return c, nil
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+)
+
+const emailDomainColumns = `id, institution_id, domain, method, mailbox, dns_token, join_policy, status, expires_at, verified_at, created_at, updated_at`
+
+func (r *MySqlRepo) CreateEmailDomain(ctx context.Context, domain *institution.EmailDomain) (*institution.EmailDomain, error) {
+	if domain.Token() == "" {
+		return nil, errors.New("email domain has no token")
+	}
+	// the TXT record value has to be shown again until it is published, the mailbox token only ever goes to the mailbox
+	var mailbox, dnsToken, tokenHash interface{}
+	if domain.Method() == institution.VerifyByMailbox {
+		mailbox, tokenHash = domain.Mailbox(), r.hashSecret(domain.Token())
+	} else {
+		dnsToken = domain.Token()
+	}
+	query := `INSERT INTO institution_email_domains (institution_id, domain, method, mailbox, dns_token, token_hash, join_policy, status, expires_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, domain.InstitutionId().Value(), domain.Domain().String(), domain.Method().String(), mailbox, dnsToken, tokenHash,
+		domain.Policy().String(), domain.Status().String(), domain.ExpiresAt(), domain.CreatedAt(), domain.UpdatedAt())
+	if isDuplicateKey(err) {
+		return nil, institute_repo.ErrEmailDomainExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	domain.SetId(parsedId)
+	return domain, nil
+}
+
+func (r *MySqlRepo) GetEmailDomainById(ctx context.Context, institutionId, domainId institution.Id) (*institution.EmailDomain, error) {
+	query := `SELECT ` + emailDomainColumns + ` FROM institution_email_domains WHERE id = ? AND institution_id = ?`
+	domain, err := r.scanEmailDomain(r.db.QueryRowContext(ctx, query, domainId.Value(), institutionId.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrEmailDomainNotFound
+	}
+	return domain, err
+}
+
+func (r *MySqlRepo) GetEmailDomainByToken(ctx context.Context, token string) (*institution.EmailDomain, error) {
+	query := `SELECT ` + emailDomainColumns + ` FROM institution_email_domains WHERE token_hash = ?`
+	domain, err := r.scanEmailDomain(r.db.QueryRowContext(ctx, query, r.hashSecret(token)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrEmailDomainNotFound
+	}
+	return domain, err
+}
+
+func (r *MySqlRepo) GetVerifiedEmailDomain(ctx context.Context, domain institution.DomainName) (*institution.EmailDomain, error) {
+	query := `SELECT ` + emailDomainColumns + ` FROM institution_email_domains WHERE verified_domain = ?`
+	d, err := r.scanEmailDomain(r.db.QueryRowContext(ctx, query, domain.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrEmailDomainNotFound
+	}
+	return d, err
+}
+
+func (r *MySqlRepo) ListEmailDomains(ctx context.Context, institutionId institution.Id) ([]institution.EmailDomain, error) {
+	query := `SELECT ` + emailDomainColumns + ` FROM institution_email_domains WHERE institution_id = ? ORDER BY domain, id`
+	rows, err := r.db.QueryContext(ctx, query, institutionId.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	domains := []institution.EmailDomain{}
+	for rows.Next() {
+		domain, err := r.scanEmailDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, *domain)
+	}
+	return domains, rows.Err()
+}
+
+func (r *MySqlRepo) UpdateEmailDomain(ctx context.Context, domain *institution.EmailDomain) error {
+	var verifiedDomain interface{}
+	if domain.IsVerified() {
+		verifiedDomain = domain.Domain().String()
+	}
+	query := `UPDATE institution_email_domains SET join_policy = ?, status = ?, verified_domain = ?, expires_at = ?, verified_at = ?, updated_at = ? WHERE id = ? AND institution_id = ?`
+	res, err := r.db.ExecContext(ctx, query, domain.Policy().String(), domain.Status().String(), verifiedDomain, domain.ExpiresAt(), domain.VerifiedAt(), domain.UpdatedAt(),
+		domain.Id().Value(), domain.InstitutionId().Value())
+	if isDuplicateKey(err) {
+		return institute_repo.ErrEmailDomainClaimed
+	}
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrEmailDomainNotFound)
+}
+
+func (r *MySqlRepo) DeleteEmailDomain(ctx context.Context, institutionId, domainId institution.Id) error {
+	query := `DELETE FROM institution_email_domains WHERE id = ? AND institution_id = ?`
+	res, err := r.db.ExecContext(ctx, query, domainId.Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrEmailDomainNotFound)
+}
+
+// LinkStaffUser only changes a staff still waiting to join, a suspended or blacklisted one stays as is
+func (r *MySqlRepo) LinkStaffUser(ctx context.Context, institutionId institution.Id, staff *institution.Staff) error {
+	if staff.UserId() == nil {
+		return errors.New("staff has no account to link")
+	}
+	query := `UPDATE institution_staff SET user_id = ?, status = ?, updated_at = ? WHERE id = ? AND institution_id = ? AND status = ? AND deleted_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, staff.UserId().Value(), staff.Status().String(), time.Now(), staff.Id().Value(), institutionId.Value(), institution.InActive.String())
+	if isDuplicateKey(err) {
+		return institute_repo.ErrStaffExists
+	}
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrStaffNotFound)
+}
+
+func (r *MySqlRepo) scanEmailDomain(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*institution.EmailDomain, error) {
+	var (
+		id, institutionId              int
+		domain, method, policy, status string
+		mailbox, dnsToken              sql.NullString
+		createdAt, updatedAt           time.Time
+		expiresAt, verifiedAt          sql.NullTime
+	)
+	if err := scanner.Scan(&id, &institutionId, &domain, &method, &mailbox, &dnsToken, &policy, &status, &expiresAt, &verifiedAt, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	d, err := institution.NewEmailDomain(institution.Id(institutionId), institution.DomainName(domain), institution.DomainVerificationMethod(method), mailbox.String, institution.DomainJoinPolicy(policy))
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(parsedId)
+	d.SetStatus(institution.EmailDomainStatus(status))
+	d.SetTimestamps(createdAt, updatedAt)
+	if dnsToken.Valid {
+		if err := d.SetToken(dnsToken.String); err != nil {
+			return nil, err
+		}
+	}
+	var expires *time.Time
+	if expiresAt.Valid {
+		expires = &expiresAt.Time
+	}
+	d.SetExpiresAt(expires)
+	if verifiedAt.Valid {
+		d.SetVerifiedAt(verifiedAt.Time)
+	}
+	return d, nil
+}
@@ -0,0 +1,763 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+)
+
+const (
+	institutionColumns = `id, name, description, email, created_at, updated_at`
+	staffColumns       = `id, user_id, name, email, status, status_reason, status_changed_at, role, created_at, updated_at, deleted_at`
+	groupColumns       = `id, name, description, created_at, updated_at`
+	courseColumns      = `id, name, description, category_id, created_at, updated_at`
+)
+
+// isDuplicateKey reports whether the statement failed on a unique key
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+func (r *MySqlRepo) CreateInstitution(ctx context.Context, inst *institution.Institution) (*institution.Institution, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO institutions (name, description, email, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+	res, err := tx.ExecContext(ctx, query, inst.Name().String(), inst.Description().String(), inst.Email().String(), inst.CreatedAt(), inst.UpdatedAt())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range inst.Staff() {
+		if _, err := insertStaff(ctx, tx, parsedId, s); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	inst.SetId(parsedId)
+	return inst, nil
+}
+
+func (r *MySqlRepo) GetInstitutionById(ctx context.Context, id institution.Id) (*institution.Institution, error) {
+	query := `SELECT ` + institutionColumns + ` FROM institutions WHERE id = ?`
+	inst, err := r.scanInstitution(r.db.QueryRowContext(ctx, query, id.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrInstitutionNotFound
+	}
+	return inst, err
+}
+
+func (r *MySqlRepo) UpdateInstitution(ctx context.Context, inst *institution.Institution) error {
+	query := `UPDATE institutions SET name = ?, description = ?, email = ?, updated_at = ? WHERE id = ?`
+	res, err := r.db.ExecContext(ctx, query, inst.Name().String(), inst.Description().String(), inst.Email().String(), inst.UpdatedAt(), inst.Id().Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrInstitutionNotFound)
+}
+
+// DeleteInstitution removes the institution with its staff, groups and courses, its api keys stop working
+func (r *MySqlRepo) DeleteInstitution(ctx context.Context, id institution.Id) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM institutions WHERE id = ?`, id.Value())
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res, institute_repo.ErrInstitutionNotFound); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE api_keys SET revoked_at = ? WHERE institution_id = ? AND revoked_at IS NULL`, time.Now(), id.Value()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Staff
+
+func (r *MySqlRepo) AddStaffToInstitution(ctx context.Context, institutionId institution.Id, staff institution.Staff) (*institution.Staff, error) {
+	id, err := insertStaff(ctx, r.db, institutionId, staff)
+	if err != nil {
+		return nil, err
+	}
+	staff.SetId(id)
+	return &staff, nil
+}
+
+func insertStaff(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}, institutionId institution.Id, s institution.Staff) (institution.Id, error) {
+	var userId interface{}
+	if s.UserId() != nil {
+		userId = s.UserId().Value()
+	}
+	query := `INSERT INTO institution_staff (institution_id, user_id, name, email, status, role, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := db.ExecContext(ctx, query, institutionId.Value(), userId, s.Name().String(), s.Email().String(), s.Status().String(), s.Role().String(), s.CreatedAt(), s.UpdatedAt())
+	if isDuplicateKey(err) {
+		return 0, institute_repo.ErrStaffExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return institution.NewId(int(id))
+}
+
+func (r *MySqlRepo) GetStaffById(ctx context.Context, institutionId, staffId institution.Id) (*institution.Staff, error) {
+	query := `SELECT ` + staffColumns + ` FROM institution_staff WHERE id = ? AND institution_id = ? AND deleted_at IS NULL`
+	s, err := r.scanStaff(r.db.QueryRowContext(ctx, query, staffId.Value(), institutionId.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrStaffNotFound
+	}
+	return s, err
+}
+
+func (r *MySqlRepo) GetStaffByUserId(ctx context.Context, institutionId, userId institution.Id) (*institution.Staff, error) {
+	query := `SELECT ` + staffColumns + ` FROM institution_staff WHERE user_id = ? AND institution_id = ? AND deleted_at IS NULL`
+	s, err := r.scanStaff(r.db.QueryRowContext(ctx, query, userId.Value(), institutionId.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrStaffNotFound
+	}
+	return s, err
+}
+
+func (r *MySqlRepo) GetStaffByEmail(ctx context.Context, institutionId institution.Id, email institution.Email) (*institution.Staff, error) {
+	query := `SELECT ` + staffColumns + ` FROM institution_staff WHERE email = ? AND institution_id = ? AND deleted_at IS NULL`
+	s, err := r.scanStaff(r.db.QueryRowContext(ctx, query, email.String(), institutionId.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrStaffNotFound
+	}
+	return s, err
+}
+
+func (r *MySqlRepo) UpdateStaff(ctx context.Context, institutionId institution.Id, staff *institution.Staff) error {
+	var reason interface{}
+	if staff.StatusReason() != "" {
+		reason = staff.StatusReason()
+	}
+	query := `UPDATE institution_staff SET name = ?, role = ?, status = ?, status_reason = ?, status_changed_at = ?, updated_at = ? WHERE id = ? AND institution_id = ? AND deleted_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, staff.Name().String(), staff.Role().String(), staff.Status().String(), reason, staff.StatusChangedAt(), time.Now(), staff.Id().Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrStaffNotFound)
+}
+
+// RemoveStaffFromInstitution soft deletes the staff, their group memberships are kept for a restore
+func (r *MySqlRepo) RemoveStaffFromInstitution(ctx context.Context, institutionId, staffId institution.Id) error {
+	query := `UPDATE institution_staff SET deleted_at = ?, updated_at = ? WHERE id = ? AND institution_id = ? AND deleted_at IS NULL`
+	now := time.Now()
+	res, err := r.db.ExecContext(ctx, query, now, now, staffId.Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrStaffNotFound)
+}
+
+func (r *MySqlRepo) GetRemovedStaffById(ctx context.Context, institutionId, staffId institution.Id) (*institution.Staff, error) {
+	query := `SELECT ` + staffColumns + ` FROM institution_staff WHERE id = ? AND institution_id = ? AND deleted_at IS NOT NULL`
+	s, err := r.scanStaff(r.db.QueryRowContext(ctx, query, staffId.Value(), institutionId.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrStaffNotFound
+	}
+	return s, err
+}
+
+// RestoreStaff undoes the soft delete, the staff comes back with the groups and categories they were in.
+// It fails with ErrStaffExists when the email or user was added to the institution again since the removal.
+func (r *MySqlRepo) RestoreStaff(ctx context.Context, institutionId institution.Id, staff *institution.Staff) error {
+	query := `UPDATE institution_staff SET deleted_at = NULL, updated_at = ? WHERE id = ? AND institution_id = ? AND deleted_at IS NOT NULL`
+	res, err := r.db.ExecContext(ctx, query, staff.UpdatedAt(), staff.Id().Value(), institutionId.Value())
+	if isDuplicateKey(err) {
+		return institute_repo.ErrStaffExists
+	}
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrStaffNotFound)
+}
+
+func (r *MySqlRepo) ListStaff(ctx context.Context, institutionId institution.Id, filter institution.StaffFilter) ([]institution.Staff, int, error) {
+	whereClause := ` WHERE institution_id = ?`
+	args := []interface{}{institutionId.Value()}
+	if filter.Removed {
+		whereClause += ` AND deleted_at IS NOT NULL`
+	} else {
+		whereClause += ` AND deleted_at IS NULL`
+	}
+	if filter.Status != nil {
+		whereClause += ` AND status = ?`
+		args = append(args, filter.Status.String())
+	}
+	if filter.Role != nil {
+		whereClause += ` AND role = ?`
+		args = append(args, filter.Role.String())
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM institution_staff`+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args := withPage(`SELECT `+staffColumns+` FROM institution_staff`+whereClause+` ORDER BY name, id`, args, filter.Page)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	staff := []institution.Staff{}
+	for rows.Next() {
+		s, err := r.scanStaff(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		staff = append(staff, *s)
+	}
+	return staff, total, rows.Err()
+}
+
+// withPage limits the query to the page, the args are extended with the limit and offset
+func withPage(query string, args []interface{}, page institution.Page) (string, []interface{}) {
+	if page.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, page.Limit, page.Offset)
+	}
+	return query, args
+}
+
+// Membership
+
+func (r *MySqlRepo) IsInstitutionAdmin(ctx context.Context, institutionId, userId institution.Id) (bool, error) {
+	return r.isActiveAdmin(ctx, institutionId.Value(), userId.Value())
+}
+
+func (r *MySqlRepo) CanManageInstitution(ctx context.Context, userId, institutionId user.Id) (bool, error) {
+	return r.isActiveAdmin(ctx, institutionId.Value(), userId.Value())
+}
+
+func (r *MySqlRepo) isActiveAdmin(ctx context.Context, institutionId, userId int) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM institution_staff WHERE institution_id = ? AND user_id = ? AND role IN (?, ?) AND status = ? AND deleted_at IS NULL)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, institutionId, userId, institution.AdminRole.String(), institution.OwnerRole.String(), institution.Active.String()).Scan(&exists)
+	return exists, err
+}
+
+// ListMembershipsByUser returns every institution the user is staff of, suspended and blacklisted included
+func (r *MySqlRepo) ListMembershipsByUser(ctx context.Context, userId institution.Id) ([]institution.Membership, error) {
+	query := `SELECT i.id, i.name, i.description, s.id, s.role, s.status FROM institution_staff s
+		JOIN institutions i ON i.id = s.institution_id
+		WHERE s.user_id = ? AND s.deleted_at IS NULL ORDER BY i.name, i.id`
+	rows, err := r.db.QueryContext(ctx, query, userId.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []institution.Membership{}
+	for rows.Next() {
+		var (
+			institutionId, staffId int
+			name, description      string
+			role, status           string
+		)
+		if err := rows.Scan(&institutionId, &name, &description, &staffId, &role, &status); err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, institution.Membership{
+			InstitutionId:          institution.Id(institutionId),
+			InstitutionName:        institution.Name(name),
+			InstitutionDescription: institution.Description(description),
+			StaffId:                institution.Id(staffId),
+			Role:                   institution.StaffRole(role),
+			Status:                 institution.StaffStatus(status),
+		})
+	}
+	return memberships, rows.Err()
+}
+
+func (r *MySqlRepo) ListStaffRecordsOfPerson(ctx context.Context, userId institution.Id, email institution.Email) ([]institution.StaffRecord, error) {
+	query := `SELECT institution_id, ` + staffColumns + ` FROM institution_staff WHERE user_id = ? OR email = ? ORDER BY institution_id, id`
+	rows, err := r.db.QueryContext(ctx, query, userId.Value(), email.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []institution.StaffRecord{}
+	for rows.Next() {
+		var institutionId int
+		s, err := r.scanStaff(leadingColumns{rows, []interface{}{&institutionId}})
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, institution.StaffRecord{InstitutionId: institution.Id(institutionId), Staff: *s})
+	}
+	return records, rows.Err()
+}
+
+// leadingColumns scans the columns selected ahead of the ones a scanX func expects into dest
+type leadingColumns struct {
+	rows *sql.Rows
+	dest []interface{}
+}
+
+func (l leadingColumns) Scan(dest ...interface{}) error {
+	return l.rows.Scan(append(l.dest, dest...)...)
+}
+
+// Group
+
+func (r *MySqlRepo) CreateGroup(ctx context.Context, institutionId institution.Id, group *institution.Group) (*institution.Group, error) {
+	query := `INSERT INTO institution_groups (institution_id, name, description, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, institutionId.Value(), group.Name().String(), group.Description().String(), group.CreatedAt(), group.UpdatedAt())
+	if isDuplicateKey(err) {
+		return nil, institute_repo.ErrGroupExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	group.SetId(parsedId)
+	return group, nil
+}
+
+// GetGroupById returns the group with its staff and the courses it can access
+func (r *MySqlRepo) GetGroupById(ctx context.Context, institutionId, groupId institution.Id) (*institution.Group, error) {
+	query := `SELECT ` + groupColumns + ` FROM institution_groups WHERE id = ? AND institution_id = ?`
+	group, err := r.scanGroup(r.db.QueryRowContext(ctx, query, groupId.Value(), institutionId.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrGroupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	staffQuery := `SELECT ` + prefixColumns("s", staffColumns) + ` FROM institution_staff s
+		JOIN group_staff gs ON gs.staff_id = s.id
+		WHERE gs.group_id = ? AND s.deleted_at IS NULL ORDER BY s.name, s.id`
+	rows, err := r.db.QueryContext(ctx, staffQuery, groupId.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	staff := []institution.Staff{}
+	for rows.Next() {
+		s, err := r.scanStaff(rows)
+		if err != nil {
+			return nil, err
+		}
+		staff = append(staff, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	courses, err := r.ListAccessibleCoursesForGroup(ctx, institutionId, groupId)
+	if err != nil {
+		return nil, err
+	}
+	group.SetMembers(staff, courses)
+	return group, nil
+}
+
+func (r *MySqlRepo) UpdateGroup(ctx context.Context, institutionId institution.Id, group *institution.Group) error {
+	query := `UPDATE institution_groups SET name = ?, description = ?, updated_at = ? WHERE id = ? AND institution_id = ?`
+	res, err := r.db.ExecContext(ctx, query, group.Name().String(), group.Description().String(), group.UpdatedAt(), group.Id().Value(), institutionId.Value())
+	if isDuplicateKey(err) {
+		return institute_repo.ErrGroupExists
+	}
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrGroupNotFound)
+}
+
+func (r *MySqlRepo) DeleteGroup(ctx context.Context, institutionId, groupId institution.Id) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM institution_groups WHERE id = ? AND institution_id = ?`, groupId.Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrGroupNotFound)
+}
+
+// AddStaffToGroup is idempotent, both the group and the staff must belong to the institution
+func (r *MySqlRepo) AddStaffToGroup(ctx context.Context, institutionId, groupId, staffId institution.Id) error {
+	if err := r.groupExists(ctx, institutionId, groupId); err != nil {
+		return err
+	}
+	if _, err := r.GetStaffById(ctx, institutionId, staffId); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `INSERT IGNORE INTO group_staff (group_id, staff_id, created_at) VALUES (?, ?, ?)`, groupId.Value(), staffId.Value(), time.Now())
+	return err
+}
+
+func (r *MySqlRepo) RemoveStaffFromGroup(ctx context.Context, institutionId, groupId, staffId institution.Id) error {
+	query := `DELETE gs FROM group_staff gs JOIN institution_groups g ON g.id = gs.group_id WHERE gs.group_id = ? AND gs.staff_id = ? AND g.institution_id = ?`
+	res, err := r.db.ExecContext(ctx, query, groupId.Value(), staffId.Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrStaffNotFound)
+}
+
+func (r *MySqlRepo) ListGroups(ctx context.Context, institutionId institution.Id, page institution.Page) ([]institution.Group, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM institution_groups WHERE institution_id = ?`, institutionId.Value()).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	query, args := withPage(`SELECT `+groupColumns+` FROM institution_groups WHERE institution_id = ? ORDER BY name, id`, []interface{}{institutionId.Value()}, page)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	groups := []institution.Group{}
+	for rows.Next() {
+		g, err := r.scanGroup(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		groups = append(groups, *g)
+	}
+	return groups, total, rows.Err()
+}
+
+func (r *MySqlRepo) groupExists(ctx context.Context, institutionId, groupId institution.Id) error {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM institution_groups WHERE id = ? AND institution_id = ?)`
+	if err := r.db.QueryRowContext(ctx, query, groupId.Value(), institutionId.Value()).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return institute_repo.ErrGroupNotFound
+	}
+	return nil
+}
+
+// Course
+
+func (r *MySqlRepo) CreateCourse(ctx context.Context, institutionId institution.Id, course *institution.Course) (*institution.Course, error) {
+	query := `INSERT INTO institution_courses (institution_id, name, description, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, institutionId.Value(), course.Name().String(), course.Description().String(), course.CreatedAt(), course.UpdatedAt())
+	if isDuplicateKey(err) {
+		return nil, institute_repo.ErrCourseExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	course.SetId(parsedId)
+	return course, nil
+}
+
+func (r *MySqlRepo) GetCourseById(ctx context.Context, institutionId, courseId institution.Id) (*institution.Course, error) {
+	query := `SELECT ` + courseColumns + ` FROM institution_courses WHERE id = ? AND institution_id = ?`
+	course, err := r.scanCourse(r.db.QueryRowContext(ctx, query, courseId.Value(), institutionId.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrCourseNotFound
+	}
+	return course, err
+}
+
+func (r *MySqlRepo) UpdateCourse(ctx context.Context, institutionId institution.Id, course *institution.Course) error {
+	query := `UPDATE institution_courses SET name = ?, description = ?, updated_at = ? WHERE id = ? AND institution_id = ?`
+	res, err := r.db.ExecContext(ctx, query, course.Name().String(), course.Description().String(), course.UpdatedAt(), course.Id().Value(), institutionId.Value())
+	if isDuplicateKey(err) {
+		return institute_repo.ErrCourseExists
+	}
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrCourseNotFound)
+}
+
+func (r *MySqlRepo) DeleteCourse(ctx context.Context, institutionId, courseId institution.Id) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM institution_courses WHERE id = ? AND institution_id = ?`, courseId.Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrCourseNotFound)
+}
+
+func (r *MySqlRepo) ListCourses(ctx context.Context, institutionId institution.Id, filter institution.CourseFilter) ([]institution.Course, int, error) {
+	whereClause := ` WHERE institution_id = ?`
+	args := []interface{}{institutionId.Value()}
+	if filter.CategoryIds != nil {
+		if len(filter.CategoryIds) == 0 {
+			return []institution.Course{}, 0, nil
+		}
+		in, ids := idList(filter.CategoryIds)
+		whereClause += ` AND category_id IN (` + in + `)`
+		args = append(args, ids...)
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM institution_courses`+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	query, args := withPage(`SELECT `+courseColumns+` FROM institution_courses`+whereClause+` ORDER BY name, id`, args, filter.Page)
+	courses, err := r.queryCourses(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return courses, total, nil
+}
+
+func (r *MySqlRepo) queryCourses(ctx context.Context, query string, args ...interface{}) ([]institution.Course, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := []institution.Course{}
+	for rows.Next() {
+		c, err := r.scanCourse(rows)
+		if err != nil {
+			return nil, err
+		}
+		courses = append(courses, *c)
+	}
+	return courses, rows.Err()
+}
+
+// Course Access (Group <-> Course)
+
+// AddAccessibleCourseToGroup is idempotent, both the group and the course must belong to the institution
+func (r *MySqlRepo) AddAccessibleCourseToGroup(ctx context.Context, institutionId, groupId, courseId institution.Id) error {
+	if err := r.groupExists(ctx, institutionId, groupId); err != nil {
+		return err
+	}
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM institution_courses WHERE id = ? AND institution_id = ?)`
+	if err := r.db.QueryRowContext(ctx, query, courseId.Value(), institutionId.Value()).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return institute_repo.ErrCourseNotFound
+	}
+	_, err := r.db.ExecContext(ctx, `INSERT IGNORE INTO group_courses (group_id, course_id, created_at) VALUES (?, ?, ?)`, groupId.Value(), courseId.Value(), time.Now())
+	return err
+}
+
+func (r *MySqlRepo) RemoveAccessibleCourseFromGroup(ctx context.Context, institutionId, groupId, courseId institution.Id) error {
+	query := `DELETE gc FROM group_courses gc JOIN institution_groups g ON g.id = gc.group_id WHERE gc.group_id = ? AND gc.course_id = ? AND g.institution_id = ?`
+	res, err := r.db.ExecContext(ctx, query, groupId.Value(), courseId.Value(), institutionId.Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrCourseNotFound)
+}
+
+func (r *MySqlRepo) ListAccessibleCoursesForGroup(ctx context.Context, institutionId, groupId institution.Id) ([]institution.Course, error) {
+	query := `SELECT ` + prefixColumns("c", courseColumns) + ` FROM institution_courses c
+		JOIN group_courses gc ON gc.course_id = c.id
+		WHERE gc.group_id = ? AND c.institution_id = ? ORDER BY c.name, c.id`
+	return r.queryCourses(ctx, query, groupId.Value(), institutionId.Value())
+}
+
+func (r *MySqlRepo) ListAccessibleCoursesForStaff(ctx context.Context, institutionId, staffId institution.Id, categoryIds []institution.Id) ([]institution.Course, error) {
+	query := `SELECT ` + prefixColumns("c", courseColumns) + ` FROM institution_courses c
+		WHERE c.institution_id = ? AND c.id IN (
+			SELECT gc.course_id FROM group_courses gc JOIN group_staff gs ON gs.group_id = gc.group_id WHERE gs.staff_id = ?
+		)`
+	args := []interface{}{institutionId.Value(), staffId.Value()}
+	if categoryIds != nil {
+		if len(categoryIds) == 0 {
+			return []institution.Course{}, nil
+		}
+		in, ids := idList(categoryIds)
+		query += ` AND c.category_id IN (` + in + `)`
+		args = append(args, ids...)
+	}
+	return r.queryCourses(ctx, query+` ORDER BY c.name, c.id`, args...)
+}
+
+func (r *MySqlRepo) CanStaffAccessCourse(ctx context.Context, institutionId, staffId, courseId institution.Id) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM group_courses gc
+		JOIN group_staff gs ON gs.group_id = gc.group_id
+		JOIN institution_courses c ON c.id = gc.course_id
+		WHERE gs.staff_id = ? AND gc.course_id = ? AND c.institution_id = ?)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, staffId.Value(), courseId.Value(), institutionId.Value()).Scan(&exists)
+	return exists, err
+}
+
+// expectAffected returns notFound when the statement changed nothing
+func expectAffected(res sql.Result, notFound error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// prefixColumns qualifies a column list with a table alias
+func prefixColumns(alias, columns string) string {
+	parts := strings.Split(columns, ", ")
+	for i, p := range parts {
+		parts[i] = alias + "." + p
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r *MySqlRepo) scanInstitution(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*institution.Institution, error) {
+	var (
+		id                       int
+		name, description, email string
+		createdAt, updatedAt     time.Time
+	)
+	if err := scanner.Scan(&id, &name, &description, &email, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	inst, err := institution.NewInstitution(institution.Name(name), institution.Description(description), institution.Email(email))
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	inst.SetId(parsedId)
+	inst.SetTimestamps(createdAt, updatedAt)
+	return inst, nil
+}
+
+func (r *MySqlRepo) scanStaff(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*institution.Staff, error) {
+	var (
+		id                        int
+		userId                    sql.NullInt64
+		name, email, status, role string
+		statusReason              sql.NullString
+		statusChangedAt           sql.NullTime
+		createdAt, updatedAt      time.Time
+		deletedAt                 sql.NullTime
+	)
+	if err := scanner.Scan(&id, &userId, &name, &email, &status, &statusReason, &statusChangedAt, &role, &createdAt, &updatedAt, &deletedAt); err != nil {
+		return nil, err
+	}
+	s, err := institution.NewStaff(institution.Name(name), institution.Email(email), institution.StaffStatus(status))
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	s.SetId(parsedId)
+	s.SetRole(institution.StaffRole(role))
+	s.SetTimestamps(createdAt, updatedAt)
+	var changedAt *time.Time
+	if statusChangedAt.Valid {
+		changedAt = &statusChangedAt.Time
+	}
+	s.SetStatusDetails(statusReason.String, changedAt)
+	if userId.Valid {
+		s.LinkUser(institution.Id(userId.Int64))
+	}
+	if deletedAt.Valid {
+		s.MarkDeleted(deletedAt.Time)
+	}
+	return s, nil
+}
+
+func (r *MySqlRepo) scanGroup(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*institution.Group, error) {
+	var (
+		id                   int
+		name, description    string
+		createdAt, updatedAt time.Time
+	)
+	if err := scanner.Scan(&id, &name, &description, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	g, err := institution.NewGroup(institution.Name(name), institution.Description(description))
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	g.SetId(parsedId)
+	g.SetTimestamps(createdAt, updatedAt)
+	return g, nil
+}
+
+func (r *MySqlRepo) scanCourse(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*institution.Course, error) {
+	var (
+		id                   int
+		name, description    string
+		categoryId           sql.NullInt64
+		createdAt, updatedAt time.Time
+	)
+	if err := scanner.Scan(&id, &name, &description, &categoryId, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	c, err := institution.NewCourse(institution.Name(name), institution.Description(description))
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	c.SetId(parsedId)
+	if categoryId.Valid {
+		c.PlaceIn(idPtr(int(categoryId.Int64)))
+	}
+	c.SetTimestamps(createdAt, updatedAt)
+	return c, nil
+}
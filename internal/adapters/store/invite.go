@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+)
+
+const inviteColumns = `id, institution_id, staff_id, name, email, status, invited_by, expires_at, accepted_at, revoked_at, created_at, updated_at`
+
+func (r *MySqlRepo) CreateInvite(ctx context.Context, invite *institution.Invite) (*institution.Invite, error) {
+	if invite.Token() == "" {
+		return nil, errors.New("invite has no token")
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// only the latest invite of a staff can be accepted
+	revoke := `UPDATE institution_invites SET status = ?, revoked_at = ?, updated_at = ? WHERE staff_id = ? AND status = ?`
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, revoke, institution.InviteRevoked.String(), now, now, invite.StaffId().Value(), institution.InvitePending.String()); err != nil {
+		return nil, err
+	}
+	query := `INSERT INTO institution_invites (institution_id, staff_id, name, email, token_hash, status, invited_by, expires_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := tx.ExecContext(ctx, query, invite.InstitutionId().Value(), invite.StaffId().Value(), invite.Name().String(), invite.Email().String(), r.hashSecret(invite.Token()),
+		institution.InvitePending.String(), invite.InvitedBy().Value(), invite.ExpiresAt(), invite.CreatedAt(), invite.UpdatedAt())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	invite.SetId(parsedId)
+	return invite, nil
+}
+
+func (r *MySqlRepo) GetInviteById(ctx context.Context, institutionId, inviteId institution.Id) (*institution.Invite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM institution_invites WHERE id = ? AND institution_id = ?`
+	invite, err := r.scanInvite(r.db.QueryRowContext(ctx, query, inviteId.Value(), institutionId.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrInviteNotFound
+	}
+	return invite, err
+}
+
+func (r *MySqlRepo) GetInviteByToken(ctx context.Context, token string) (*institution.Invite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM institution_invites WHERE token_hash = ?`
+	invite, err := r.scanInvite(r.db.QueryRowContext(ctx, query, r.hashSecret(token)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrInviteNotFound
+	}
+	return invite, err
+}
+
+func (r *MySqlRepo) ListInvites(ctx context.Context, institutionId institution.Id) ([]institution.Invite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM institution_invites WHERE institution_id = ? ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, institutionId.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []institution.Invite{}
+	for rows.Next() {
+		invite, err := r.scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, *invite)
+	}
+	return invites, rows.Err()
+}
+
+func (r *MySqlRepo) ListInvitesToPerson(ctx context.Context, email institution.Email, staffIds []institution.Id) ([]institution.Invite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM institution_invites WHERE email = ?`
+	args := []interface{}{email.String()}
+	if len(staffIds) > 0 {
+		in, ids := idList(staffIds)
+		query += ` OR staff_id IN (` + in + `)`
+		args = append(args, ids...)
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []institution.Invite{}
+	for rows.Next() {
+		invite, err := r.scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, *invite)
+	}
+	return invites, rows.Err()
+}
+
+func (r *MySqlRepo) UpdateInvite(ctx context.Context, invite *institution.Invite) error {
+	query := `UPDATE institution_invites SET status = ?, expires_at = ?, accepted_at = ?, revoked_at = ?, updated_at = ? WHERE id = ? AND institution_id = ?`
+	args := []interface{}{invite.Status().String(), invite.ExpiresAt(), invite.AcceptedAt(), invite.RevokedAt(), invite.UpdatedAt(), invite.Id().Value(), invite.InstitutionId().Value()}
+	if invite.Token() != "" {
+		query = `UPDATE institution_invites SET token_hash = ?, status = ?, expires_at = ?, accepted_at = ?, revoked_at = ?, updated_at = ? WHERE id = ? AND institution_id = ?`
+		args = append([]interface{}{r.hashSecret(invite.Token())}, args...)
+	}
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrInviteNotFound)
+}
+
+func (r *MySqlRepo) AcceptInvite(ctx context.Context, invite *institution.Invite, userId institution.Id) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the status check keeps two requests with the same token from both accepting it
+	query := `UPDATE institution_invites SET status = ?, accepted_at = ?, updated_at = ? WHERE id = ? AND status = ?`
+	res, err := tx.ExecContext(ctx, query, institution.InviteAccepted.String(), invite.AcceptedAt(), invite.UpdatedAt(), invite.Id().Value(), institution.InvitePending.String())
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res, institute_repo.ErrInviteNotFound); err != nil {
+		return err
+	}
+	// only a staff still waiting on the invite is activated, a suspended or blacklisted one stays as is
+	staffQuery := `UPDATE institution_staff SET user_id = ?, status = ?, updated_at = ? WHERE id = ? AND institution_id = ? AND status = ? AND deleted_at IS NULL`
+	res, err = tx.ExecContext(ctx, staffQuery, userId.Value(), institution.Active.String(), time.Now(), invite.StaffId().Value(), invite.InstitutionId().Value(), institution.InActive.String())
+	if isDuplicateKey(err) {
+		return institute_repo.ErrStaffExists
+	}
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res, institute_repo.ErrStaffNotFound); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *MySqlRepo) scanInvite(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*institution.Invite, error) {
+	var (
+		id, institutionId, staffId, invitedBy int
+		name, email, status                   string
+		expiresAt, createdAt, updatedAt       time.Time
+		acceptedAt, revokedAt                 sql.NullTime
+	)
+	if err := scanner.Scan(&id, &institutionId, &staffId, &name, &email, &status, &invitedBy, &expiresAt, &acceptedAt, &revokedAt, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	invite, err := institution.NewInvite(institution.Id(institutionId), institution.Id(staffId), institution.Name(name), institution.Email(email), institution.Id(invitedBy))
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	invite.SetId(parsedId)
+	invite.SetStatus(institution.InviteStatus(status))
+	invite.SetExpiresAt(expiresAt)
+	invite.SetTimestamps(createdAt, updatedAt)
+	if acceptedAt.Valid {
+		invite.SetAcceptedAt(acceptedAt.Time)
+	}
+	if revokedAt.Valid {
+		invite.SetRevokedAt(revokedAt.Time)
+	}
+	return invite, nil
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+)
+
+func (r *MySqlRepo) GetLoginAttempt(ctx context.Context, key user.LoginAttemptKey) (*user.LoginAttempt, error) {
+	query := `SELECT failures, last_failed_at, locked_until FROM login_attempts WHERE attempt_key = ?`
+	attempt, err := r.scanLoginAttempt(key, r.db.QueryRowContext(ctx, query, key.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.NewLoginAttempt(key), nil
+	}
+	return attempt, err
+}
+
+// RecordLoginFailure makes sure the row exists and then locks it, so concurrent failures on the same key are
+// counted one after another instead of overwriting each other.
+func (r *MySqlRepo) RecordLoginFailure(ctx context.Context, key user.LoginAttemptKey, now time.Time, policy user.LockoutPolicy) (*user.LoginAttempt, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	// locking a missing row only takes a gap lock, which lets two first failures both insert
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO login_attempts (attempt_key, failures, created_at, updated_at) VALUES (?, 0, ?, ?)
+		ON DUPLICATE KEY UPDATE attempt_key = attempt_key
+	`, key.String(), now, now)
+	if err != nil {
+		return nil, false, err
+	}
+	query := `SELECT failures, last_failed_at, locked_until FROM login_attempts WHERE attempt_key = ? FOR UPDATE`
+	attempt, err := r.scanLoginAttempt(key, tx.QueryRowContext(ctx, query, key.String()))
+	if err != nil {
+		return nil, false, err
+	}
+	locked := attempt.RecordFailure(now, policy)
+
+	query = `UPDATE login_attempts SET failures = ?, last_failed_at = ?, locked_until = ?, updated_at = ? WHERE attempt_key = ?`
+	if _, err := tx.ExecContext(ctx, query, attempt.Failures(), attempt.LastFailedAt(), attempt.LockedUntil(), now, key.String()); err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return attempt, locked, nil
+}
+
+func (r *MySqlRepo) DeleteLoginAttempt(ctx context.Context, key user.LoginAttemptKey) error {
+	query := `DELETE FROM login_attempts WHERE attempt_key = ?`
+	_, err := r.db.ExecContext(ctx, query, key.String())
+	return err
+}
+
+func (r *MySqlRepo) scanLoginAttempt(key user.LoginAttemptKey, scanner interface {
+	Scan(dest ...interface{}) error
+}) (*user.LoginAttempt, error) {
+	var (
+		failures     int
+		lastFailedAt sql.NullTime
+		lockedUntil  sql.NullTime
+	)
+	if err := scanner.Scan(&failures, &lastFailedAt, &lockedUntil); err != nil {
+		return nil, err
+	}
+	attempt := user.NewLoginAttempt(key)
+	attempt.SetFailures(failures)
+	if lastFailedAt.Valid {
+		attempt.SetLastFailedAt(lastFailedAt.Time)
+	}
+	if lockedUntil.Valid {
+		attempt.SetLockedUntil(lockedUntil.Time)
+	}
+	return attempt, nil
+}
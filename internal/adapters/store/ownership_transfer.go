@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+)
+
+const ownershipTransferColumns = `id, institution_id, from_staff_id, to_staff_id, status, from_confirmed_at, to_confirmed_at, expires_at, completed_at, cancelled_at, created_at, updated_at`
+
+func (r *MySqlRepo) CreateOwnershipTransfer(ctx context.Context, transfer *institution.OwnershipTransfer) (*institution.OwnershipTransfer, error) {
+	if transfer.FromToken() == "" || transfer.ToToken() == "" {
+		return nil, errors.New("ownership transfer has no tokens")
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// only the latest transfer of an institution can be confirmed
+	cancel := `UPDATE institution_ownership_transfers SET status = ?, cancelled_at = ?, updated_at = ? WHERE institution_id = ? AND status = ?`
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, cancel, institution.TransferCancelled.String(), now, now, transfer.InstitutionId().Value(), institution.TransferPending.String()); err != nil {
+		return nil, err
+	}
+	query := `INSERT INTO institution_ownership_transfers (institution_id, from_staff_id, to_staff_id, from_token_hash, to_token_hash, status, expires_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := tx.ExecContext(ctx, query, transfer.InstitutionId().Value(), transfer.FromStaffId().Value(), transfer.ToStaffId().Value(), r.hashSecret(transfer.FromToken()), r.hashSecret(transfer.ToToken()),
+		institution.TransferPending.String(), transfer.ExpiresAt(), transfer.CreatedAt(), transfer.UpdatedAt())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	transfer.SetId(parsedId)
+	return transfer, nil
+}
+
+func (r *MySqlRepo) GetPendingOwnershipTransfer(ctx context.Context, institutionId institution.Id) (*institution.OwnershipTransfer, error) {
+	query := `SELECT ` + ownershipTransferColumns + ` FROM institution_ownership_transfers WHERE institution_id = ? AND status = ? ORDER BY id DESC LIMIT 1`
+	transfer, err := r.scanOwnershipTransfer(r.db.QueryRowContext(ctx, query, institutionId.Value(), institution.TransferPending.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrTransferNotFound
+	}
+	return transfer, err
+}
+
+func (r *MySqlRepo) GetOwnershipTransferByToken(ctx context.Context, token string) (*institution.OwnershipTransfer, institution.Id, error) {
+	query := `SELECT ` + ownershipTransferColumns + `, IF(from_token_hash = ?, from_staff_id, to_staff_id) FROM institution_ownership_transfers WHERE from_token_hash = ? OR to_token_hash = ?`
+	hash := r.hashSecret(token)
+	var partyId int
+	transfer, err := r.scanOwnershipTransfer(r.db.QueryRowContext(ctx, query, hash, hash, hash), &partyId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, institute_repo.ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return transfer, institution.Id(partyId), nil
+}
+
+func (r *MySqlRepo) UpdateOwnershipTransfer(ctx context.Context, transfer *institution.OwnershipTransfer) error {
+	query := `UPDATE institution_ownership_transfers SET status = ?, from_confirmed_at = ?, to_confirmed_at = ?, cancelled_at = ?, updated_at = ? WHERE id = ? AND institution_id = ? AND status = ?`
+	res, err := r.db.ExecContext(ctx, query, transfer.Status().String(), transfer.FromConfirmedAt(), transfer.ToConfirmedAt(), transfer.CancelledAt(), transfer.UpdatedAt(),
+		transfer.Id().Value(), transfer.InstitutionId().Value(), institution.TransferPending.String())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrTransferNotFound)
+}
+
+func (r *MySqlRepo) CompleteOwnershipTransfer(ctx context.Context, transfer *institution.OwnershipTransfer) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the status check keeps two confirmations arriving together from both completing it
+	query := `UPDATE institution_ownership_transfers SET status = ?, from_confirmed_at = ?, to_confirmed_at = ?, completed_at = ?, updated_at = ? WHERE id = ? AND status = ?`
+	res, err := tx.ExecContext(ctx, query, institution.TransferCompleted.String(), transfer.FromConfirmedAt(), transfer.ToConfirmedAt(), transfer.CompletedAt(), transfer.UpdatedAt(),
+		transfer.Id().Value(), institution.TransferPending.String())
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res, institute_repo.ErrTransferNotFound); err != nil {
+		return err
+	}
+	// the owner must still be the owner, and the new owner still active, when the transfer completes
+	now := time.Now()
+	roleQuery := `UPDATE institution_staff SET role = ?, updated_at = ? WHERE id = ? AND institution_id = ? AND role = ? AND deleted_at IS NULL`
+	res, err = tx.ExecContext(ctx, roleQuery, institution.AdminRole.String(), now, transfer.FromStaffId().Value(), transfer.InstitutionId().Value(), institution.OwnerRole.String())
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res, institute_repo.ErrStaffNotFound); err != nil {
+		return err
+	}
+	newOwnerQuery := `UPDATE institution_staff SET role = ?, updated_at = ? WHERE id = ? AND institution_id = ? AND status = ? AND deleted_at IS NULL`
+	res, err = tx.ExecContext(ctx, newOwnerQuery, institution.OwnerRole.String(), now, transfer.ToStaffId().Value(), transfer.InstitutionId().Value(), institution.Active.String())
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res, institute_repo.ErrStaffNotFound); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// scanOwnershipTransfer reads the ownershipTransferColumns, then any extra columns selected after them into extra
+func (r *MySqlRepo) scanOwnershipTransfer(scanner interface {
+	Scan(dest ...interface{}) error
+}, extra ...interface{}) (*institution.OwnershipTransfer, error) {
+	var (
+		id, institutionId, fromStaffId, toStaffId int
+		status                                    string
+		expiresAt, createdAt, updatedAt           time.Time
+		fromConfirmedAt, toConfirmedAt            sql.NullTime
+		completedAt, cancelledAt                  sql.NullTime
+	)
+	dest := append([]interface{}{&id, &institutionId, &fromStaffId, &toStaffId, &status, &fromConfirmedAt, &toConfirmedAt, &expiresAt, &completedAt, &cancelledAt, &createdAt, &updatedAt}, extra...)
+	if err := scanner.Scan(dest...); err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	transfer := institution.LoadOwnershipTransfer(institution.Id(institutionId), institution.Id(fromStaffId), institution.Id(toStaffId))
+	transfer.SetId(parsedId)
+	transfer.SetStatus(institution.OwnershipTransferStatus(status))
+	transfer.SetExpiresAt(expiresAt)
+	var fromConfirmed, toConfirmed *time.Time
+	if fromConfirmedAt.Valid {
+		fromConfirmed = &fromConfirmedAt.Time
+	}
+	if toConfirmedAt.Valid {
+		toConfirmed = &toConfirmedAt.Time
+	}
+	transfer.SetConfirmedAt(fromConfirmed, toConfirmed)
+	transfer.SetTimestamps(createdAt, updatedAt)
+	if completedAt.Valid {
+		transfer.SetCompletedAt(completedAt.Time)
+	}
+	if cancelledAt.Valid {
+		transfer.SetCancelledAt(cancelledAt.Time)
+	}
+	return transfer, nil
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/assessment"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+)
+
+// storedPreferences is how preferences are kept in the json column
+type storedPreferences struct {
+	NotificationChannels []string `json:"notificationChannels"`
+	DefaultQuestionType  string   `json:"defaultQuestionType"`
+	DefaultAiDifficulty  string   `json:"defaultAiDifficulty"`
+}
+
+func (r *MySqlRepo) GetProfile(ctx context.Context, userId user.Id) (*user.Profile, error) {
+	query := `SELECT display_name, bio, avatar_key, avatar_url, timezone, locale, preferences, updated_at FROM user_profiles WHERE user_id = ?`
+	var (
+		displayName, bio, avatarKey, avatarUrl, timezone, locale string
+		rawPreferences                                           sql.NullString
+		updatedAt                                                time.Time
+	)
+	err := r.db.QueryRowContext(ctx, query, userId.Value()).Scan(&displayName, &bio, &avatarKey, &avatarUrl, &timezone, &locale, &rawPreferences, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, user_repo.ErrProfileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	p, err := user.NewProfile(userId)
+	if err != nil {
+		return nil, err
+	}
+	p.SetDisplayName(displayName)
+	p.SetBio(bio)
+	p.SetAvatar(avatarKey, avatarUrl)
+	p.SetTimezone(timezone)
+	p.SetLocale(locale)
+	if rawPreferences.Valid {
+		var stored storedPreferences
+		if err := json.Unmarshal([]byte(rawPreferences.String), &stored); err != nil {
+			return nil, err
+		}
+		// values that are no longer known keep their defaults rather than failing the profile
+		preferences := p.Preferences()
+		preferences.NotificationChannels = nil
+		for _, c := range stored.NotificationChannels {
+			if channel, err := user.NewNotificationChannel(c); err == nil {
+				preferences.NotificationChannels = append(preferences.NotificationChannels, channel)
+			}
+		}
+		if t, err := assessment.NewQuestionType(stored.DefaultQuestionType); err == nil {
+			preferences.DefaultQuestionType = t
+		}
+		if d, err := assessment.NewDifficulty(stored.DefaultAiDifficulty); err == nil {
+			preferences.DefaultAiDifficulty = d
+		}
+		p.SetPreferences(preferences)
+	}
+	p.SetUpdatedAt(updatedAt)
+	return p, nil
+}
+
+func (r *MySqlRepo) SaveProfile(ctx context.Context, p *user.Profile) error {
+	preferences := p.Preferences()
+	stored := storedPreferences{
+		NotificationChannels: make([]string, len(preferences.NotificationChannels)),
+		DefaultQuestionType:  preferences.DefaultQuestionType.String(),
+		DefaultAiDifficulty:  preferences.DefaultAiDifficulty.String(),
+	}
+	for i, c := range preferences.NotificationChannels {
+		stored.NotificationChannels[i] = c.String()
+	}
+	rawPreferences, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO user_profiles (user_id, display_name, bio, avatar_key, avatar_url, timezone, locale, preferences, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE display_name = VALUES(display_name), bio = VALUES(bio), avatar_key = VALUES(avatar_key), avatar_url = VALUES(avatar_url),
+		timezone = VALUES(timezone), locale = VALUES(locale), preferences = VALUES(preferences), updated_at = VALUES(updated_at)`
+	_, err = r.db.ExecContext(ctx, query, p.UserId().Value(), p.DisplayName(), p.Bio(), p.AvatarKey(), p.AvatarUrl(), p.Timezone(), p.Locale(), string(rawPreferences), p.UpdatedAt())
+	return err
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+)
+
+const rosterImportColumns = `id, institution_id, created_by, file_name, status, total_rows, results, failure, created_at, updated_at, finished_at`
+
+// storedRosterRow is how a row result is kept in the results column
+type storedRosterRow struct {
+	Line    int    `json:"line"`
+	Email   string `json:"email"`
+	Action  string `json:"action"`
+	Invited bool   `json:"invited,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (r *MySqlRepo) CreateRosterImport(ctx context.Context, ri *institution.RosterImport) (*institution.RosterImport, error) {
+	results, err := marshalRosterResults(ri.Results())
+	if err != nil {
+		return nil, err
+	}
+	query := `INSERT INTO roster_imports (institution_id, created_by, file_name, status, total_rows, results, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, ri.InstitutionId().Value(), ri.CreatedBy().Value(), ri.FileName(), ri.Status().String(), ri.TotalRows(), results, ri.CreatedAt(), ri.UpdatedAt())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	ri.SetId(parsedId)
+	return ri, nil
+}
+
+func (r *MySqlRepo) GetRosterImportById(ctx context.Context, institutionId, importId institution.Id) (*institution.RosterImport, error) {
+	query := `SELECT ` + rosterImportColumns + ` FROM roster_imports WHERE id = ? AND institution_id = ?`
+	ri, err := r.scanRosterImport(r.db.QueryRowContext(ctx, query, importId.Value(), institutionId.Value()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, institute_repo.ErrImportNotFound
+	}
+	return ri, err
+}
+
+func (r *MySqlRepo) UpdateRosterImport(ctx context.Context, ri *institution.RosterImport) error {
+	results, err := marshalRosterResults(ri.Results())
+	if err != nil {
+		return err
+	}
+	var failure interface{}
+	if ri.Failure() != "" {
+		failure = ri.Failure()
+	}
+	query := `UPDATE roster_imports SET status = ?, results = ?, failure = ?, updated_at = ?, finished_at = ? WHERE id = ? AND institution_id = ?`
+	res, err := r.db.ExecContext(ctx, query, ri.Status().String(), results, failure, ri.UpdatedAt(), ri.FinishedAt(), ri.Id().Value(), ri.InstitutionId().Value())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, institute_repo.ErrImportNotFound)
+}
+
+func marshalRosterResults(results []institution.RosterRowResult) (string, error) {
+	stored := make([]storedRosterRow, len(results))
+	for i, result := range results {
+		stored[i] = storedRosterRow{
+			Line:    result.Line,
+			Email:   result.Email,
+			Action:  result.Action.String(),
+			Invited: result.Invited,
+			Error:   result.Error,
+		}
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (r *MySqlRepo) scanRosterImport(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*institution.RosterImport, error) {
+	var (
+		id, institutionId, createdBy, totalRows int
+		fileName, status                        string
+		rawResults                              []byte
+		failure                                 sql.NullString
+		createdAt, updatedAt                    time.Time
+		finishedAt                              sql.NullTime
+	)
+	if err := scanner.Scan(&id, &institutionId, &createdBy, &fileName, &status, &totalRows, &rawResults, &failure, &createdAt, &updatedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	ri, err := institution.NewRosterImport(institution.Id(institutionId), institution.Id(createdBy), fileName, totalRows)
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := institution.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	var stored []storedRosterRow
+	if err := json.Unmarshal(rawResults, &stored); err != nil {
+		return nil, err
+	}
+	results := make([]institution.RosterRowResult, len(stored))
+	for i, row := range stored {
+		results[i] = institution.RosterRowResult{
+			Line:    row.Line,
+			Email:   row.Email,
+			Action:  institution.RosterAction(row.Action),
+			Invited: row.Invited,
+			Error:   row.Error,
+		}
+	}
+	ri.SetId(parsedId)
+	ri.SetStatus(institution.RosterImportStatus(status))
+	ri.SetResults(results)
+	ri.SetFailure(failure.String)
+	ri.SetTimestamps(createdAt, updatedAt)
+	if finishedAt.Valid {
+		ri.SetFinishedAt(finishedAt.Time)
+	}
+	return ri, nil
+}
@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+)
+
+func (r *MySqlRepo) CreateSession(ctx context.Context, s *user.Session) (*user.Session, error) {
+	query := `INSERT INTO sessions (user_id, user_agent, ip, created_at, last_seen_at) VALUES (?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, s.UserId().Value(), s.UserAgent(), s.Ip(), s.CreatedAt(), s.LastSeenAt())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	parsedId, err := user.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	s.SetId(parsedId)
+	return s, nil
+}
+
+func (r *MySqlRepo) GetSessionById(ctx context.Context, sessionId user.Id) (*user.Session, error) {
+	query := `SELECT id, user_id, user_agent, ip, created_at, last_seen_at, revoked_at FROM sessions WHERE id = ?`
+	row := r.db.QueryRowContext(ctx, query, sessionId.Value())
+	s, err := r.scanSession(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, user_repo.ErrSessionNotFound
+	}
+	return s, err
+}
+
+func (r *MySqlRepo) ListActiveSessions(ctx context.Context, userId user.Id) ([]user.Session, error) {
+	query := `SELECT id, user_id, user_agent, ip, created_at, last_seen_at, revoked_at FROM sessions WHERE user_id = ? AND revoked_at IS NULL ORDER BY last_seen_at DESC`
+	rows, err := r.db.QueryContext(ctx, query, userId.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []user.Session
+	for rows.Next() {
+		s, err := r.scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeSession marks the session revoked and removes the refresh tokens in its family
+func (r *MySqlRepo) RevokeSession(ctx context.Context, userId, sessionId user.Id) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE sessions SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`, time.Now(), sessionId.Value(), userId.Value())
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return user_repo.ErrSessionNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tokens WHERE session_id = ?`, sessionId.Value()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *MySqlRepo) TouchSessions(ctx context.Context, lastSeen map[user.Id]user.DateTime) error {
+	if len(lastSeen) == 0 {
+		return nil
+	}
+	// UPDATE sessions SET last_seen_at = CASE id WHEN ? THEN ? ... END WHERE id IN (...)
+	var (
+		cases        strings.Builder
+		placeholders []string
+		caseArgs     []interface{}
+		idArgs       []interface{}
+	)
+	for id, seenAt := range lastSeen {
+		cases.WriteString(" WHEN ? THEN ?")
+		caseArgs = append(caseArgs, id.Value(), seenAt)
+		placeholders = append(placeholders, "?")
+		idArgs = append(idArgs, id.Value())
+	}
+	query := `UPDATE sessions SET last_seen_at = CASE id` + cases.String() + ` ELSE last_seen_at END WHERE id IN (` + strings.Join(placeholders, ", ") + `)`
+	_, err := r.db.ExecContext(ctx, query, append(caseArgs, idArgs...)...)
+	return err
+}
+
+func (r *MySqlRepo) CreateRefreshToken(ctx context.Context, value user.TokenValue, userId, sessionId user.Id, expiresAt user.DateTime) (*user.Token, error) {
+	query := `INSERT INTO tokens (value, type, user_id, session_id, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, r.hashToken(value), user.RefreshToken.String(), userId.Value(), sessionId.Value(), time.Now(), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	id, _ := res.LastInsertId()
+	parsedId, err := user.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	token, err := user.NewToken(value, user.RefreshToken, userId)
+	if err != nil {
+		return nil, err
+	}
+	token.SetId(parsedId)
+	token.SetSessionId(sessionId)
+	token.SetExpiresAt(expiresAt)
+	return token, nil
+}
+
+func (r *MySqlRepo) UseRefreshToken(ctx context.Context, id user.Id) error {
+	// the condition makes marking atomic, of two concurrent uses only one gets the row
+	query := `UPDATE tokens SET used_at = ? WHERE id = ? AND type = ? AND used_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, time.Now(), id.Value(), user.RefreshToken.String())
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, user_repo.ErrRefreshTokenUsed)
+}
+
+func (r *MySqlRepo) GetRefreshToken(ctx context.Context, value user.TokenValue) (*user.Token, error) {
+	query := `SELECT id, user_id, value, session_id, created_at, expires_at FROM tokens WHERE type = ? AND value = ?`
+	row := r.db.QueryRowContext(ctx, query, user.RefreshToken.String(), r.hashToken(value))
+
+	var (
+		tid, uid             int
+		storedHash           string
+		sid                  sql.NullInt64
+		createdAt, expiresAt sql.NullTime
+	)
+	if err := row.Scan(&tid, &uid, &storedHash, &sid, &createdAt, &expiresAt); err != nil {
+		return nil, err
+	}
+	if !r.tokenHashMatches(storedHash, value) {
+		return nil, sql.ErrNoRows
+	}
+	tokenId, err := user.NewId(tid)
+	if err != nil {
+		return nil, err
+	}
+	userId, err := user.NewId(uid)
+	if err != nil {
+		return nil, err
+	}
+	t := user.Token{}
+	t.SetId(tokenId)
+	t.SetUserId(userId)
+	t.SetValue(value)
+	t.SetType(user.RefreshToken.String())
+	t.SetCreatedAt(createdAt.Time)
+	t.SetExpiresAt(expiresAt.Time)
+	if sid.Valid {
+		t.SetSessionId(user.Id(sid.Int64))
+	}
+	return &t, nil
+}
+
+func (r *MySqlRepo) CreateDeviceBoundToken(ctx context.Context, token *user.Token) (*user.Token, error) {
+	query := `INSERT INTO tokens (value, type, user_id, device_nonce_hash, confirmation_code_hash, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, r.hashToken(token.Value()), token.Type().String(), token.UserId().Value(), token.DeviceNonceHash(), token.ConfirmationCodeHash(), time.Now(), token.ExpiresAt())
+	if err != nil {
+		return nil, err
+	}
+	id, _ := res.LastInsertId()
+	parsedId, err := user.NewId(int(id))
+	if err != nil {
+		return nil, err
+	}
+	token.SetId(parsedId)
+	return token, nil
+}
+
+func (r *MySqlRepo) scanSession(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*user.Session, error) {
+	var (
+		id, userId    int
+		userAgent, ip string
+		createdAt     time.Time
+		lastSeenAt    time.Time
+		revokedAt     sql.NullTime
+	)
+	if err := scanner.Scan(&id, &userId, &userAgent, &ip, &createdAt, &lastSeenAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	uid, err := user.NewId(userId)
+	if err != nil {
+		return nil, err
+	}
+	s, err := user.NewSession(uid, userAgent, ip)
+	if err != nil {
+		return nil, err
+	}
+	sid, err := user.NewId(id)
+	if err != nil {
+		return nil, err
+	}
+	s.SetId(sid)
+	s.SetCreatedAt(createdAt)
+	s.SetLastSeenAt(lastSeenAt)
+	if revokedAt.Valid {
+		s.Revoke(revokedAt.Time)
+	}
+	return s, nil
+}
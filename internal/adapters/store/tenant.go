@@ -0,0 +1,472 @@
+package store
+
+import (
+	"context"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+)
+
+// tenantScopedRepo refuses to touch the data of an institution other than the one the context is scoped to,
+// so a handler passing the wrong institution id fails instead of leaking or changing another tenant's data.
+// Without a tenant in the context, e.g. creating an institution or accepting an invite, calls go straight through.
+type tenantScopedRepo struct {
+	institute_repo.InstitutionRepository
+}
+
+// NewTenantScopedRepository wraps the repository so every call is checked against the tenant in its context
+func NewTenantScopedRepository(repo institute_repo.InstitutionRepository) institute_repo.InstitutionRepository {
+	return &tenantScopedRepo{repo}
+}
+
+// Institution
+
+func (r *tenantScopedRepo) GetInstitutionById(ctx context.Context, id institution.Id) (*institution.Institution, error) {
+	if err := institute_repo.CheckTenant(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetInstitutionById(ctx, id)
+}
+
+func (r *tenantScopedRepo) UpdateInstitution(ctx context.Context, inst *institution.Institution) error {
+	if err := institute_repo.CheckTenant(ctx, inst.Id()); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.UpdateInstitution(ctx, inst)
+}
+
+func (r *tenantScopedRepo) DeleteInstitution(ctx context.Context, id institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, id); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.DeleteInstitution(ctx, id)
+}
+
+// Staff
+
+func (r *tenantScopedRepo) AddStaffToInstitution(ctx context.Context, institutionId institution.Id, staff institution.Staff) (*institution.Staff, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.AddStaffToInstitution(ctx, institutionId, staff)
+}
+
+func (r *tenantScopedRepo) GetStaffById(ctx context.Context, institutionId, staffId institution.Id) (*institution.Staff, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetStaffById(ctx, institutionId, staffId)
+}
+
+func (r *tenantScopedRepo) GetStaffByEmail(ctx context.Context, institutionId institution.Id, email institution.Email) (*institution.Staff, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetStaffByEmail(ctx, institutionId, email)
+}
+
+func (r *tenantScopedRepo) UpdateStaff(ctx context.Context, institutionId institution.Id, staff *institution.Staff) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.UpdateStaff(ctx, institutionId, staff)
+}
+
+func (r *tenantScopedRepo) RemoveStaffFromInstitution(ctx context.Context, institutionId, staffId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.RemoveStaffFromInstitution(ctx, institutionId, staffId)
+}
+
+func (r *tenantScopedRepo) GetRemovedStaffById(ctx context.Context, institutionId, staffId institution.Id) (*institution.Staff, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetRemovedStaffById(ctx, institutionId, staffId)
+}
+
+func (r *tenantScopedRepo) RestoreStaff(ctx context.Context, institutionId institution.Id, staff *institution.Staff) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.RestoreStaff(ctx, institutionId, staff)
+}
+
+func (r *tenantScopedRepo) ListStaff(ctx context.Context, institutionId institution.Id, filter institution.StaffFilter) ([]institution.Staff, int, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, 0, err
+	}
+	return r.InstitutionRepository.ListStaff(ctx, institutionId, filter)
+}
+
+// Membership
+
+func (r *tenantScopedRepo) IsInstitutionAdmin(ctx context.Context, institutionId, userId institution.Id) (bool, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return false, err
+	}
+	return r.InstitutionRepository.IsInstitutionAdmin(ctx, institutionId, userId)
+}
+
+func (r *tenantScopedRepo) GetStaffByUserId(ctx context.Context, institutionId, userId institution.Id) (*institution.Staff, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetStaffByUserId(ctx, institutionId, userId)
+}
+
+// Invites
+
+func (r *tenantScopedRepo) CreateInvite(ctx context.Context, invite *institution.Invite) (*institution.Invite, error) {
+	if err := institute_repo.CheckTenant(ctx, invite.InstitutionId()); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.CreateInvite(ctx, invite)
+}
+
+func (r *tenantScopedRepo) GetInviteById(ctx context.Context, institutionId, inviteId institution.Id) (*institution.Invite, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetInviteById(ctx, institutionId, inviteId)
+}
+
+// GetInviteByToken is looked up before the institution is known, an invite of another tenant is reported as not found
+func (r *tenantScopedRepo) GetInviteByToken(ctx context.Context, token string) (*institution.Invite, error) {
+	invite, err := r.InstitutionRepository.GetInviteByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := institute_repo.CheckTenant(ctx, invite.InstitutionId()); err != nil {
+		return nil, institute_repo.ErrInviteNotFound
+	}
+	return invite, nil
+}
+
+func (r *tenantScopedRepo) ListInvites(ctx context.Context, institutionId institution.Id) ([]institution.Invite, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.ListInvites(ctx, institutionId)
+}
+
+func (r *tenantScopedRepo) UpdateInvite(ctx context.Context, invite *institution.Invite) error {
+	if err := institute_repo.CheckTenant(ctx, invite.InstitutionId()); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.UpdateInvite(ctx, invite)
+}
+
+func (r *tenantScopedRepo) AcceptInvite(ctx context.Context, invite *institution.Invite, userId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, invite.InstitutionId()); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.AcceptInvite(ctx, invite, userId)
+}
+
+// Ownership transfers
+
+func (r *tenantScopedRepo) CreateOwnershipTransfer(ctx context.Context, transfer *institution.OwnershipTransfer) (*institution.OwnershipTransfer, error) {
+	if err := institute_repo.CheckTenant(ctx, transfer.InstitutionId()); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.CreateOwnershipTransfer(ctx, transfer)
+}
+
+func (r *tenantScopedRepo) GetPendingOwnershipTransfer(ctx context.Context, institutionId institution.Id) (*institution.OwnershipTransfer, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetPendingOwnershipTransfer(ctx, institutionId)
+}
+
+// GetOwnershipTransferByToken is looked up before the institution is known, a transfer of another tenant is reported as not found
+func (r *tenantScopedRepo) GetOwnershipTransferByToken(ctx context.Context, token string) (*institution.OwnershipTransfer, institution.Id, error) {
+	transfer, partyId, err := r.InstitutionRepository.GetOwnershipTransferByToken(ctx, token)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := institute_repo.CheckTenant(ctx, transfer.InstitutionId()); err != nil {
+		return nil, 0, institute_repo.ErrTransferNotFound
+	}
+	return transfer, partyId, nil
+}
+
+func (r *tenantScopedRepo) UpdateOwnershipTransfer(ctx context.Context, transfer *institution.OwnershipTransfer) error {
+	if err := institute_repo.CheckTenant(ctx, transfer.InstitutionId()); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.UpdateOwnershipTransfer(ctx, transfer)
+}
+
+func (r *tenantScopedRepo) CompleteOwnershipTransfer(ctx context.Context, transfer *institution.OwnershipTransfer) error {
+	if err := institute_repo.CheckTenant(ctx, transfer.InstitutionId()); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.CompleteOwnershipTransfer(ctx, transfer)
+}
+
+// Email domains
+
+func (r *tenantScopedRepo) CreateEmailDomain(ctx context.Context, domain *institution.EmailDomain) (*institution.EmailDomain, error) {
+	if err := institute_repo.CheckTenant(ctx, domain.InstitutionId()); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.CreateEmailDomain(ctx, domain)
+}
+
+func (r *tenantScopedRepo) GetEmailDomainById(ctx context.Context, institutionId, domainId institution.Id) (*institution.EmailDomain, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetEmailDomainById(ctx, institutionId, domainId)
+}
+
+// GetEmailDomainByToken is looked up before the institution is known, a domain of another tenant is reported as not found
+func (r *tenantScopedRepo) GetEmailDomainByToken(ctx context.Context, token string) (*institution.EmailDomain, error) {
+	domain, err := r.InstitutionRepository.GetEmailDomainByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := institute_repo.CheckTenant(ctx, domain.InstitutionId()); err != nil {
+		return nil, institute_repo.ErrEmailDomainNotFound
+	}
+	return domain, nil
+}
+
+func (r *tenantScopedRepo) ListEmailDomains(ctx context.Context, institutionId institution.Id) ([]institution.EmailDomain, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.ListEmailDomains(ctx, institutionId)
+}
+
+func (r *tenantScopedRepo) UpdateEmailDomain(ctx context.Context, domain *institution.EmailDomain) error {
+	if err := institute_repo.CheckTenant(ctx, domain.InstitutionId()); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.UpdateEmailDomain(ctx, domain)
+}
+
+func (r *tenantScopedRepo) DeleteEmailDomain(ctx context.Context, institutionId, domainId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.DeleteEmailDomain(ctx, institutionId, domainId)
+}
+
+// Roster imports
+
+func (r *tenantScopedRepo) CreateRosterImport(ctx context.Context, ri *institution.RosterImport) (*institution.RosterImport, error) {
+	if err := institute_repo.CheckTenant(ctx, ri.InstitutionId()); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.CreateRosterImport(ctx, ri)
+}
+
+func (r *tenantScopedRepo) GetRosterImportById(ctx context.Context, institutionId, importId institution.Id) (*institution.RosterImport, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetRosterImportById(ctx, institutionId, importId)
+}
+
+func (r *tenantScopedRepo) UpdateRosterImport(ctx context.Context, ri *institution.RosterImport) error {
+	if err := institute_repo.CheckTenant(ctx, ri.InstitutionId()); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.UpdateRosterImport(ctx, ri)
+}
+
+// Group
+
+func (r *tenantScopedRepo) CreateGroup(ctx context.Context, institutionId institution.Id, group *institution.Group) (*institution.Group, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.CreateGroup(ctx, institutionId, group)
+}
+
+func (r *tenantScopedRepo) GetGroupById(ctx context.Context, institutionId, groupId institution.Id) (*institution.Group, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetGroupById(ctx, institutionId, groupId)
+}
+
+func (r *tenantScopedRepo) UpdateGroup(ctx context.Context, institutionId institution.Id, group *institution.Group) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.UpdateGroup(ctx, institutionId, group)
+}
+
+func (r *tenantScopedRepo) DeleteGroup(ctx context.Context, institutionId, groupId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.DeleteGroup(ctx, institutionId, groupId)
+}
+
+func (r *tenantScopedRepo) AddStaffToGroup(ctx context.Context, institutionId, groupId, staffId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.AddStaffToGroup(ctx, institutionId, groupId, staffId)
+}
+
+func (r *tenantScopedRepo) RemoveStaffFromGroup(ctx context.Context, institutionId, groupId, staffId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.RemoveStaffFromGroup(ctx, institutionId, groupId, staffId)
+}
+
+func (r *tenantScopedRepo) ListGroups(ctx context.Context, institutionId institution.Id, page institution.Page) ([]institution.Group, int, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, 0, err
+	}
+	return r.InstitutionRepository.ListGroups(ctx, institutionId, page)
+}
+
+// Course
+
+func (r *tenantScopedRepo) CreateCourse(ctx context.Context, institutionId institution.Id, course *institution.Course) (*institution.Course, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.CreateCourse(ctx, institutionId, course)
+}
+
+func (r *tenantScopedRepo) GetCourseById(ctx context.Context, institutionId, courseId institution.Id) (*institution.Course, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetCourseById(ctx, institutionId, courseId)
+}
+
+func (r *tenantScopedRepo) UpdateCourse(ctx context.Context, institutionId institution.Id, course *institution.Course) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.UpdateCourse(ctx, institutionId, course)
+}
+
+func (r *tenantScopedRepo) DeleteCourse(ctx context.Context, institutionId, courseId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.DeleteCourse(ctx, institutionId, courseId)
+}
+
+func (r *tenantScopedRepo) ListCourses(ctx context.Context, institutionId institution.Id, filter institution.CourseFilter) ([]institution.Course, int, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, 0, err
+	}
+	return r.InstitutionRepository.ListCourses(ctx, institutionId, filter)
+}
+
+func (r *tenantScopedRepo) SetCourseCategory(ctx context.Context, institutionId institution.Id, course *institution.Course) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.SetCourseCategory(ctx, institutionId, course)
+}
+
+// Category
+
+func (r *tenantScopedRepo) CreateCategory(ctx context.Context, institutionId institution.Id, category *institution.Category) (*institution.Category, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.CreateCategory(ctx, institutionId, category)
+}
+
+func (r *tenantScopedRepo) ListCategories(ctx context.Context, institutionId institution.Id) ([]institution.Category, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.ListCategories(ctx, institutionId)
+}
+
+func (r *tenantScopedRepo) UpdateCategory(ctx context.Context, institutionId institution.Id, category *institution.Category) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.UpdateCategory(ctx, institutionId, category)
+}
+
+func (r *tenantScopedRepo) DeleteCategory(ctx context.Context, institutionId, categoryId institution.Id, newParentId *institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.DeleteCategory(ctx, institutionId, categoryId, newParentId)
+}
+
+func (r *tenantScopedRepo) AddStaffToCategory(ctx context.Context, institutionId, categoryId, staffId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.AddStaffToCategory(ctx, institutionId, categoryId, staffId)
+}
+
+func (r *tenantScopedRepo) RemoveStaffFromCategory(ctx context.Context, institutionId, categoryId, staffId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.RemoveStaffFromCategory(ctx, institutionId, categoryId, staffId)
+}
+
+func (r *tenantScopedRepo) ListStaffInCategories(ctx context.Context, institutionId institution.Id, categoryIds []institution.Id) ([]institution.Staff, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.ListStaffInCategories(ctx, institutionId, categoryIds)
+}
+
+// Course Access
+
+func (r *tenantScopedRepo) AddAccessibleCourseToGroup(ctx context.Context, institutionId, groupId, courseId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.AddAccessibleCourseToGroup(ctx, institutionId, groupId, courseId)
+}
+
+func (r *tenantScopedRepo) RemoveAccessibleCourseFromGroup(ctx context.Context, institutionId, groupId, courseId institution.Id) error {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return err
+	}
+	return r.InstitutionRepository.RemoveAccessibleCourseFromGroup(ctx, institutionId, groupId, courseId)
+}
+
+func (r *tenantScopedRepo) ListAccessibleCoursesForGroup(ctx context.Context, institutionId, groupId institution.Id) ([]institution.Course, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.ListAccessibleCoursesForGroup(ctx, institutionId, groupId)
+}
+
+func (r *tenantScopedRepo) ListAccessibleCoursesForStaff(ctx context.Context, institutionId, staffId institution.Id, categoryIds []institution.Id) ([]institution.Course, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.ListAccessibleCoursesForStaff(ctx, institutionId, staffId, categoryIds)
+}
+
+func (r *tenantScopedRepo) CanStaffAccessCourse(ctx context.Context, institutionId, staffId, courseId institution.Id) (bool, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return false, err
+	}
+	return r.InstitutionRepository.CanStaffAccessCourse(ctx, institutionId, staffId, courseId)
+}
+
+// Analytics
+
+func (r *tenantScopedRepo) GetStaffActivity(ctx context.Context, institutionId institution.Id) (*institution.StaffActivity, error) {
+	if err := institute_repo.CheckTenant(ctx, institutionId); err != nil {
+		return nil, err
+	}
+	return r.InstitutionRepository.GetStaffActivity(ctx, institutionId)
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+)
+
+// fakeInstitutionRepo records the calls that get through, any method not overridden panics on the nil interface
+type fakeInstitutionRepo struct {
+	institute_repo.InstitutionRepository
+	calls  int
+	invite *institution.Invite
+}
+
+func (f *fakeInstitutionRepo) GetInstitutionById(ctx context.Context, id institution.Id) (*institution.Institution, error) {
+	f.calls++
+	return nil, nil
+}
+
+func (f *fakeInstitutionRepo) GetStaffById(ctx context.Context, institutionId, staffId institution.Id) (*institution.Staff, error) {
+	f.calls++
+	return nil, nil
+}
+
+func (f *fakeInstitutionRepo) UpdateGroup(ctx context.Context, institutionId institution.Id, group *institution.Group) error {
+	f.calls++
+	return nil
+}
+
+func (f *fakeInstitutionRepo) ListInvites(ctx context.Context, institutionId institution.Id) ([]institution.Invite, error) {
+	f.calls++
+	return nil, nil
+}
+
+func (f *fakeInstitutionRepo) UpdateInvite(ctx context.Context, invite *institution.Invite) error {
+	f.calls++
+	return nil
+}
+
+func (f *fakeInstitutionRepo) GetInviteByToken(ctx context.Context, token string) (*institution.Invite, error) {
+	f.calls++
+	return f.invite, nil
+}
+
+func tenantContext(t *testing.T, institutionId institution.Id) context.Context {
+	t.Helper()
+	staff, err := institution.NewStaff(institution.Name("Ada"), institution.Email("ada@example.com"), institution.Active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staff.SetId(1)
+	staff.LinkUser(1)
+	tenant, err := institution.NewTenant(institutionId, staff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return institute_repo.WithTenant(context.Background(), tenant)
+}
+
+func newTestInvite(t *testing.T, institutionId institution.Id) *institution.Invite {
+	t.Helper()
+	invite, err := institution.NewInvite(institutionId, 1, institution.Name("Ada"), institution.Email("ada@example.com"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return invite
+}
+
+func TestTenantScopedRepo(t *testing.T) {
+	const tenantId, otherId = institution.Id(1), institution.Id(2)
+
+	calls := map[string]func(repo institute_repo.InstitutionRepository, ctx context.Context, institutionId institution.Id) error{
+		"GetInstitutionById": func(repo institute_repo.InstitutionRepository, ctx context.Context, institutionId institution.Id) error {
+			_, err := repo.GetInstitutionById(ctx, institutionId)
+			return err
+		},
+		"GetStaffById": func(repo institute_repo.InstitutionRepository, ctx context.Context, institutionId institution.Id) error {
+			_, err := repo.GetStaffById(ctx, institutionId, 10)
+			return err
+		},
+		"UpdateGroup": func(repo institute_repo.InstitutionRepository, ctx context.Context, institutionId institution.Id) error {
+			return repo.UpdateGroup(ctx, institutionId, &institution.Group{})
+		},
+		"ListInvites": func(repo institute_repo.InstitutionRepository, ctx context.Context, institutionId institution.Id) error {
+			_, err := repo.ListInvites(ctx, institutionId)
+			return err
+		},
+		"UpdateInvite": func(repo institute_repo.InstitutionRepository, ctx context.Context, institutionId institution.Id) error {
+			return repo.UpdateInvite(ctx, newTestInvite(t, institutionId))
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name+" refuses another institution", func(t *testing.T) {
+			inner := &fakeInstitutionRepo{}
+			err := call(NewTenantScopedRepository(inner), tenantContext(t, tenantId), otherId)
+			if !errors.Is(err, institute_repo.ErrCrossTenantAccess) {
+				t.Errorf("err = %v, want ErrCrossTenantAccess", err)
+			}
+			if inner.calls != 0 {
+				t.Errorf("the call reached the repository %d times", inner.calls)
+			}
+		})
+		t.Run(name+" allows the tenant", func(t *testing.T) {
+			inner := &fakeInstitutionRepo{}
+			if err := call(NewTenantScopedRepository(inner), tenantContext(t, tenantId), tenantId); err != nil {
+				t.Errorf("err = %v, want nil", err)
+			}
+			if inner.calls != 1 {
+				t.Errorf("the call reached the repository %d times, want 1", inner.calls)
+			}
+		})
+		t.Run(name+" passes through without a tenant", func(t *testing.T) {
+			inner := &fakeInstitutionRepo{}
+			if err := call(NewTenantScopedRepository(inner), context.Background(), otherId); err != nil {
+				t.Errorf("err = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestTenantScopedRepoHidesInvitesOfOtherInstitutions(t *testing.T) {
+	inner := &fakeInstitutionRepo{invite: newTestInvite(t, 2)}
+	_, err := NewTenantScopedRepository(inner).GetInviteByToken(tenantContext(t, 1), "token")
+	if !errors.Is(err, institute_repo.ErrInviteNotFound) {
+		t.Errorf("err = %v, want ErrInviteNotFound", err)
+	}
+}
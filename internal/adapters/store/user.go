@@ -3,9 +3,12 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
 )
 
 func (r *MySqlRepo) CreateUser(ctx context.Context, u *user.User) (*user.User, error) {
@@ -28,13 +31,12 @@ func (r *MySqlRepo) CreateUser(ctx context.Context, u *user.User) (*user.User, e
 	}
 
 	u.SetId(parsedId)
-	r.logger.Info(u, "INSERT USER ID")
-
 	return u, nil
 }
 func (r *MySqlRepo) UpdateUserPassword(ctx context.Context, u *user.User) (*user.User, error) {
 	query := `UPDATE users SET password = ?, updated_at = ? WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, u.PasswordHash(), u.GetUpdatedAt(), u.GetId().Value())
+	// a cleared password is stored as an empty string, which no password compares equal to
+	_, err := r.db.ExecContext(ctx, query, string(u.PasswordHash()), u.GetUpdatedAt(), u.GetId().Value())
 	return u, err
 }
 func (r *MySqlRepo) VerifyUser(ctx context.Context, u *user.User) (*user.User, error) {
@@ -57,7 +59,7 @@ func (r *MySqlRepo) ChangeUserStatus(ctx context.Context, userId user.Id, status
 func (r *MySqlRepo) CreateToken(ctx context.Context, value user.TokenValue, tokenType user.TokenType, userId user.Id, expiresAt user.DateTime) (token *user.Token, err error) {
 	query := `INSERT INTO tokens (value, type, user_id, created_at, expires_at) VALUES (?, ?, ?, ?, ?)`
 	now := time.Now()
-	res, err := r.db.ExecContext(ctx, query, value.String(), tokenType.String(), userId, now, expiresAt)
+	res, err := r.db.ExecContext(ctx, query, r.hashToken(value), tokenType.String(), userId, now, expiresAt)
 	if err != nil {
 		return nil, err
 	}
@@ -83,32 +85,39 @@ func (r *MySqlRepo) DeleteToken(ctx context.Context, id user.Id) error {
 	return err
 }
 func (r *MySqlRepo) GetToken(ctx context.Context, userId user.Id, value user.TokenValue) (*user.Token, error) {
-	query := `SELECT id, user_id, value, type, created_at, expires_at FROM tokens WHERE user_id = ? AND value = ?`
-	row := r.db.QueryRowContext(ctx, query, userId.Value(), string(value))
+	query := `SELECT id, user_id, value, type, device_nonce_hash, confirmation_code_hash, email_change_id, created_at, expires_at FROM tokens WHERE user_id = ? AND value = ?`
+	row := r.db.QueryRowContext(ctx, query, userId.Value(), r.hashToken(value))
 
 	t := user.Token{}
 	var createdAt, expiresAt sql.NullTime
 	var uid, tid int
 	var val, typ string
+	var deviceNonceHash, confirmationCodeHash sql.NullString
+	var emailChangeId sql.NullInt64
 
-	err := row.Scan(&tid, &uid, &val, &typ, &createdAt, &expiresAt)
+	err := row.Scan(&tid, &uid, &val, &typ, &deviceNonceHash, &confirmationCodeHash, &emailChangeId, &createdAt, &expiresAt)
 	if err != nil {
 		return nil, err
 	}
+	if !r.tokenHashMatches(val, value) {
+		return nil, sql.ErrNoRows
+	}
 	tokenId, err := user.NewId(tid)
 	if err != nil {
 		return nil, err
 	}
-	r.logger.Info("token_id", tid)
-
 	t.SetId(tokenId)
 	t.SetUserId(userId)
 	t.SetValue(value)
 	t.SetCreatedAt(createdAt.Time)
 	t.SetExpiresAt(expiresAt.Time)
 	t.SetType(typ)
+	t.SetDeviceNonceHash(deviceNonceHash.String)
+	t.SetConfirmationCodeHash(confirmationCodeHash.String)
+	if emailChangeId.Valid {
+		t.SetEmailChangeId(user.Id(emailChangeId.Int64))
+	}
 
-	r.logger.Info("token_herre", t)
 	return &t, nil
 }
 
@@ -120,18 +129,49 @@ func (r *MySqlRepo) GetUserById(ctx context.Context, userId user.Id) (*user.User
 func (r *MySqlRepo) GetUserByEmail(ctx context.Context, email user.Email) (*user.User, error) {
 	query := `SELECT id, name, email, status, password, created_at, updated_at, verified_at, deleted_at FROM users WHERE email = ?`
 	row := r.db.QueryRowContext(ctx, query, email.String())
-	return r.scanUser(row)
+	u, err := r.scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, user_repo.ErrUserNotFound
+	}
+	return u, err
 }
 
 func (r *MySqlRepo) GetUsers(ctx context.Context, filter *user.UserFilter) ([]user.User, int, error) {
 	baseQuery := `FROM users`
-	var whereClause string
+	var conditions []string
 	var args []interface{}
 
-	if filter != nil && filter.Status != nil {
-		whereClause = ` WHERE status = ?`
+	if filter == nil {
+		filter = &user.UserFilter{}
+	}
+	if filter.Status != nil {
+		conditions = append(conditions, `status = ?`)
 		args = append(args, filter.Status.String())
 	}
+	if filter.IsVerified != nil {
+		if *filter.IsVerified {
+			conditions = append(conditions, `verified_at IS NOT NULL`)
+		} else {
+			conditions = append(conditions, `verified_at IS NULL`)
+		}
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := escapeLike(search) + "%"
+		conditions = append(conditions, `(name LIKE ? OR email LIKE ?)`)
+		args = append(args, pattern, pattern)
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, `created_at >= ?`)
+		args = append(args, *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, `created_at < ?`)
+		args = append(args, *filter.CreatedBefore)
+	}
+	var whereClause string
+	if len(conditions) > 0 {
+		whereClause = ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
 
 	// Count query
 	countQuery := `SELECT COUNT(*) ` + baseQuery + whereClause
@@ -141,7 +181,11 @@ func (r *MySqlRepo) GetUsers(ctx context.Context, filter *user.UserFilter) ([]us
 	}
 
 	// Data query
-	selectQuery := `SELECT id, name, email, status, password, created_at, updated_at, verified_at, deleted_at ` + baseQuery + whereClause
+	selectQuery := `SELECT id, name, email, status, password, created_at, updated_at, verified_at, deleted_at ` + baseQuery + whereClause + ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		selectQuery += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
 	rows, err := r.db.QueryContext(ctx, selectQuery, args...)
 	if err != nil {
 		return nil, 0, err
@@ -157,7 +201,31 @@ func (r *MySqlRepo) GetUsers(ctx context.Context, filter *user.UserFilter) ([]us
 		users = append(users, *u)
 	}
 
-	return users, total, nil
+	return users, total, rows.Err()
+}
+
+func (r *MySqlRepo) GetUserRoles(ctx context.Context, userId user.Id) ([]user.Role, error) {
+	query := `SELECT roles.name FROM userRoles JOIN roles ON roles.id = userRoles.roleId WHERE userRoles.userId = ? AND userRoles.isActive = TRUE`
+	rows, err := r.db.QueryContext(ctx, query, userId.Value())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []user.Role
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		roles = append(roles, user.Role(name))
+	}
+	return roles, rows.Err()
+}
+
+// escapeLike escapes the LIKE wildcards so user input is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
 }
 
 func (r *MySqlRepo) scanUser(scanner interface {
@@ -200,11 +268,14 @@ func (r *MySqlRepo) scanUser(scanner interface {
 	pwdhash := []byte(password)
 	u.SetPasswordHash(pwdhash)
 	//verifiedAt
-	r.logger.Info(verifiedAt.Valid, "OOOOO)___________________")
-
 	if verifiedAt.Valid {
 		u.SetVerifiedAt(verifiedAt.Time)
 	}
+	if deletedAt.Valid {
+		u.SetDeletedAt(deletedAt.Time)
+	}
+	u.SetCreatedAt(createdAt)
+	u.SetUpdatedAt(updatedAt)
 
 	return u, nil
 }
@@ -0,0 +1,254 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/audit"
+	audit_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/audit"
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/logger"
+)
+
+// what an entry's target id refers to
+const (
+	TargetUser         = "user"
+	TargetSession      = "session"
+	TargetApiKey       = "api_key"
+	TargetInstitution  = "institution"
+	TargetGroup        = "group"
+	TargetCourse       = "course"
+	TargetCategory     = "category"
+	TargetStaff        = "staff"
+	TargetPlan         = "plan"
+	TargetSubscription = "subscription"
+	TargetTransaction  = "transaction"
+)
+
+// ErrImpersonated is returned by actions an impersonating admin may not take on the user's behalf
+var ErrImpersonated = errors.New("not allowed while impersonating a user")
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type (
+	// RequestMetadata describes who made the request being audited, the http layer puts it on the context
+	RequestMetadata struct {
+		ActorId int
+		// ImpersonatorId is the admin acting as the actor, requests made while impersonating are always audited
+		ImpersonatorId int
+		Ip             string
+		UserAgent      string
+		RequestId      string
+	}
+	// Event is what a service records, the request metadata is added from the context
+	Event struct {
+		ActorId       int // overrides the actor on the context when set
+		Action        string
+		TargetType    string
+		TargetId      int
+		InstitutionId *int
+		Before        map[string]any
+		After         map[string]any
+		// Redacted names fields holding personal data that changed, e.g. name and email, their values are never recorded
+		Redacted []string
+	}
+	Entry struct {
+		Id             int
+		ActorId        int
+		ImpersonatorId int
+		Action         string
+		TargetType     string
+		TargetId       string
+		InstitutionId  *int
+		Ip             string
+		UserAgent      string
+		RequestId      string
+		Changes        map[string]audit.Change
+		CreatedAt      time.Time
+	}
+	Filter struct {
+		ActorId        *int
+		ImpersonatorId *int
+		Action         string
+		TargetType     string
+		TargetId       string
+		InstitutionId  *int
+		From           *time.Time
+		To             *time.Time
+		Page           int // starts at 1
+		PageSize       int
+	}
+	GetEntriesResponse struct {
+		Entries  []Entry
+		Total    int
+		Page     int
+		PageSize int
+	}
+)
+
+type requestMetadataKey struct{}
+
+// WithRequestMetadata attaches the metadata to the context, it is shared so the actor can be set once the caller is known
+func WithRequestMetadata(ctx context.Context, meta *RequestMetadata) context.Context {
+	return context.WithValue(ctx, requestMetadataKey{}, meta)
+}
+
+// RequestMetadataFromContext returns the metadata for the request, empty when there is none e.g. in a background job
+func RequestMetadataFromContext(ctx context.Context) RequestMetadata {
+	if meta, ok := ctx.Value(requestMetadataKey{}).(*RequestMetadata); ok && meta != nil {
+		return *meta
+	}
+	return RequestMetadata{}
+}
+
+// SetActor records who is making the request
+func SetActor(ctx context.Context, actorId int) {
+	if meta, ok := ctx.Value(requestMetadataKey{}).(*RequestMetadata); ok && meta != nil {
+		meta.ActorId = actorId
+	}
+}
+
+// SetImpersonator records the admin making the request as the actor
+func SetImpersonator(ctx context.Context, impersonatorId int) {
+	if meta, ok := ctx.Value(requestMetadataKey{}).(*RequestMetadata); ok && meta != nil {
+		meta.ImpersonatorId = impersonatorId
+	}
+}
+
+// IsImpersonated reports whether an admin is making the request as the user
+func IsImpersonated(ctx context.Context) bool {
+	return RequestMetadataFromContext(ctx).ImpersonatorId != 0
+}
+
+type AuditLogService struct {
+	repo   audit_repo.AuditLogRepository
+	logger logger.Logger
+}
+
+// Constructor
+func NewAuditLogService(repo audit_repo.AuditLogRepository, logger logger.Logger) *AuditLogService {
+	return &AuditLogService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Record appends the event to the log. The action it describes has already happened, so a failure to record
+// is logged rather than returned. A nil service records nothing.
+func (s *AuditLogService) Record(ctx context.Context, event Event) {
+	if s == nil {
+		return
+	}
+	meta := RequestMetadataFromContext(ctx)
+	actorId := meta.ActorId
+	if event.ActorId != 0 {
+		actorId = event.ActorId
+	}
+	var targetId string
+	if event.TargetId != 0 {
+		targetId = strconv.Itoa(event.TargetId)
+	}
+	entry, err := audit.NewEntry(audit.EntryParams{
+		ActorId:        actorId,
+		ImpersonatorId: meta.ImpersonatorId,
+		Action:         event.Action,
+		TargetType:     event.TargetType,
+		TargetId:       targetId,
+		InstitutionId:  event.InstitutionId,
+		Ip:             meta.Ip,
+		UserAgent:      meta.UserAgent,
+		RequestId:      meta.RequestId,
+		Changes:        audit.Redact(audit.Diff(event.Before, event.After), event.Redacted...),
+	})
+	if err == nil {
+		_, err = s.repo.AppendAuditEntry(ctx, entry)
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).Error("error recording audit entry", event.Action, err)
+	}
+}
+
+// GetEntries returns the newest entries matching the filter
+func (s *AuditLogService) GetEntries(ctx context.Context, filter *Filter) (*GetEntriesResponse, error) {
+	if filter == nil {
+		filter = &Filter{}
+	}
+	page, pageSize := filter.Page, filter.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	data, total, err := s.repo.GetAuditEntries(ctx, audit.Filter{
+		ActorId:        filter.ActorId,
+		ImpersonatorId: filter.ImpersonatorId,
+		Action:         filter.Action,
+		TargetType:     filter.TargetType,
+		TargetId:       filter.TargetId,
+		InstitutionId:  filter.InstitutionId,
+		From:           filter.From,
+		To:             filter.To,
+		Limit:          pageSize,
+		Offset:         (page - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving audit entries: %w", err)
+	}
+	entries := make([]Entry, len(data))
+	for i, e := range data {
+		entries[i] = mapToServiceEntry(&e)
+	}
+	return &GetEntriesResponse{
+		Entries:  entries,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// EntriesAboutUser returns every entry about the user and their staff records, oldest first, for exporting their data.
+// The ip and user agent are the actor's, they are only kept on the entries the user made themselves.
+func (s *AuditLogService) EntriesAboutUser(ctx context.Context, userId int, staffIds []int) ([]Entry, error) {
+	if s == nil {
+		return []Entry{}, nil
+	}
+	data, err := s.repo.ListAuditEntriesAboutUser(ctx, userId, staffIds)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving audit entries: %w", err)
+	}
+	entries := make([]Entry, len(data))
+	for i, e := range data {
+		entries[i] = mapToServiceEntry(&e)
+		ownEntry := (e.ActorId() == userId && e.ImpersonatorId() == 0) || e.ImpersonatorId() == userId
+		if !ownEntry {
+			entries[i].Ip, entries[i].UserAgent = "", ""
+		}
+	}
+	return entries, nil
+}
+
+func mapToServiceEntry(e *audit.Entry) Entry {
+	return Entry{
+		Id:             e.Id(),
+		ActorId:        e.ActorId(),
+		ImpersonatorId: e.ImpersonatorId(),
+		Action:         e.Action(),
+		TargetType:     e.TargetType(),
+		TargetId:       e.TargetId(),
+		InstitutionId:  e.InstitutionId(),
+		Ip:             e.Ip(),
+		UserAgent:      e.UserAgent(),
+		RequestId:      e.RequestId(),
+		Changes:        e.Changes(),
+		CreatedAt:      e.CreatedAt(),
+	}
+}
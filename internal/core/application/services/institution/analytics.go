@@ -0,0 +1,115 @@
+package institution
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+)
+
+// analyticsCacheTTL is how long computed analytics are served before they are read again
+const analyticsCacheTTL = 5 * time.Minute
+
+var ErrUnknownAnalyticsMetric = stderrors.New("the analytics metric is not recognized")
+
+// AnalyticsMetrics are the metrics that can be exported one at a time. Staff activity is the only one with data behind it,
+// assessments per group and course, ai generations against plan limits, attempts and average scores over time and the top
+// question types are blocked until assessments, attempts and ai usage are stored, there is nothing to read them from yet.
+var AnalyticsMetrics = []string{"staff"}
+
+type (
+	GetAnalyticsRequest struct {
+		ActorId, InstitutionId int
+	}
+	InstitutionAnalytics struct {
+		GeneratedAt time.Time // the analytics are cached, so they can be a few minutes behind
+		Staff       StaffActivity
+	}
+	StaffActivity struct {
+		Active, Inactive, Suspended, Blacklisted int
+	}
+)
+
+// analyticsCache keeps computed analytics in memory, keyed by institution, for analyticsCacheTTL
+type analyticsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[institution.Id]cachedAnalytics
+}
+
+type cachedAnalytics struct {
+	analytics *InstitutionAnalytics
+	expiresAt time.Time
+}
+
+func newAnalyticsCache(ttl time.Duration) *analyticsCache {
+	return &analyticsCache{ttl: ttl, entries: make(map[institution.Id]cachedAnalytics)}
+}
+
+func (c *analyticsCache) get(key institution.Id, now time.Time) (*InstitutionAnalytics, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.analytics, true
+}
+
+func (c *analyticsCache) set(key institution.Id, analytics *InstitutionAnalytics, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// expired entries are dropped as new ones come in so institutions no longer asking do not stay in memory
+	for k, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedAnalytics{analytics: analytics, expiresAt: now.Add(c.ttl)}
+}
+
+// GetAnalytics returns the dashboard of the institution, served from the cache while it is fresh
+func (s *InstitutionManagementService) GetAnalytics(ctx context.Context, req GetAnalyticsRequest) (*InstitutionAnalytics, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if analytics, ok := s.analytics.get(instituteId, now); ok {
+		return analytics, nil
+	}
+	staff, err := s.instituteRepo.GetStaffActivity(ctx, instituteId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving staff activity: %w", err)
+	}
+	analytics := &InstitutionAnalytics{
+		GeneratedAt: now,
+		Staff: StaffActivity{
+			Active:      staff.Active,
+			Inactive:    staff.Inactive,
+			Suspended:   staff.Suspended,
+			Blacklisted: staff.Blacklisted,
+		},
+	}
+	s.analytics.set(instituteId, analytics, now)
+	return analytics, nil
+}
+
+// Table returns one metric as rows, the first being the header, for exporting as csv
+func (a *InstitutionAnalytics) Table(metric string) ([][]string, error) {
+	switch metric {
+	case "staff":
+		return [][]string{
+			{"status", "staff"},
+			{institution.Active.String(), strconv.Itoa(a.Staff.Active)},
+			{institution.InActive.String(), strconv.Itoa(a.Staff.Inactive)},
+			{institution.Suspended.String(), strconv.Itoa(a.Staff.Suspended)},
+			{institution.Blacklisted.String(), strconv.Itoa(a.Staff.Blacklisted)},
+		}, nil
+	}
+	return nil, ErrUnknownAnalyticsMetric
+}
@@ -0,0 +1,492 @@
+package institution
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+	errors "github.com/kaasikodes/assessmate_backend/internal/shared"
+)
+
+var (
+	ErrCategoryHasChildren = stderrors.New("the category has sub categories, move them first or delete it with reparent")
+	ErrCategoryExists      = stderrors.New("a category with this name already exists at this level")
+)
+
+type (
+	// CreateCategoryRequest, a faculty has no parent
+	CreateCategoryRequest struct {
+		ActorId, InstitutionId int
+		Name, Kind             string
+		ParentId               *int
+	}
+	RenameCategoryRequest struct {
+		ActorId, InstitutionId, CategoryId int
+		Name                               string
+	}
+	// MoveCategoryRequest, a nil ParentId moves the category to the top level
+	MoveCategoryRequest struct {
+		ActorId, InstitutionId, CategoryId int
+		ParentId                           *int
+	}
+	CategoryStaffRequest struct {
+		ActorId, InstitutionId, CategoryId, StaffId int
+	}
+	// SetCourseCategoryRequest, a nil CategoryId takes the course out of its category
+	SetCourseCategoryRequest struct {
+		ActorId, InstitutionId, CourseId int
+		CategoryId                       *int
+	}
+
+	Category struct {
+		Id        int
+		ParentId  *int
+		Kind      string
+		Name      string
+		CreatedAt string
+		UpdatedAt string
+	}
+	// CategoryNode is a category with the categories under it
+	CategoryNode struct {
+		Category
+		Children []CategoryNode
+	}
+)
+
+// CreateCategory adds a faculty at the top level or a department under another category
+func (s *InstitutionManagementService) CreateCategory(ctx context.Context, req CreateCategoryRequest) (*Category, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	var valErrs errors.ValidationErrors
+	name, err := institution.NewName(req.Name)
+	if err != nil {
+		valErrs.Add("name", err.Error())
+	}
+	kind, err := institution.NewCategoryKind(req.Kind)
+	if err != nil {
+		valErrs.Add("kind", err.Error())
+	}
+	parentId, err := optionalId(req.ParentId)
+	if err != nil {
+		valErrs.Add("parentId", err.Error())
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
+	}
+
+	tree, err := s.categoryTree(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	if parentId != nil && tree.Get(*parentId) == nil {
+		return nil, institute_repo.ErrCategoryNotFound
+	}
+	if err := tree.CanPlace(kind, parentId, 0, 1); err != nil {
+		return nil, err
+	}
+	if tree.HasSibling(parentId, name, 0) {
+		return nil, ErrCategoryExists
+	}
+	category, err := institution.NewCategory(name, kind, parentId)
+	if err != nil {
+		return nil, err
+	}
+	created, err := s.instituteRepo.CreateCategory(ctx, instituteId, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "category.create",
+		TargetType:    auditlog.TargetCategory,
+		TargetId:      created.Id().Value(),
+		InstitutionId: &req.InstitutionId,
+		After:         categoryAuditFields(created),
+	})
+	mapped := mapToServiceCategory(created)
+	return &mapped, nil
+}
+
+// GetCategoryTree returns the top level categories with everything under them
+func (s *InstitutionManagementService) GetCategoryTree(ctx context.Context, actorId, institutionId int) ([]CategoryNode, error) {
+	instituteId, err := s.requireMember(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := s.categoryTree(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	return categoryNodes(tree, 0), nil
+}
+
+// RenameCategory, the name must stay unique among the categories next to it
+func (s *InstitutionManagementService) RenameCategory(ctx context.Context, req RenameCategoryRequest) (*Category, error) {
+	instituteId, tree, category, err := s.adminCategory(ctx, req.ActorId, req.InstitutionId, req.CategoryId)
+	if err != nil {
+		return nil, err
+	}
+	name, err := institution.NewName(req.Name)
+	if err != nil {
+		var valErrs errors.ValidationErrors
+		valErrs.Add("name", err.Error())
+		return nil, &valErrs
+	}
+	if tree.HasSibling(category.ParentId(), name, category.Id()) {
+		return nil, ErrCategoryExists
+	}
+	before := categoryAuditFields(category)
+	if err := category.Rename(name); err != nil {
+		return nil, err
+	}
+	if err := s.instituteRepo.UpdateCategory(ctx, instituteId, category); err != nil {
+		return nil, fmt.Errorf("failed to rename category: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "category.rename",
+		TargetType:    auditlog.TargetCategory,
+		TargetId:      req.CategoryId,
+		InstitutionId: &req.InstitutionId,
+		Before:        before,
+		After:         categoryAuditFields(category),
+	})
+	mapped := mapToServiceCategory(category)
+	return &mapped, nil
+}
+
+// MoveCategory places the category, with everything under it, under another category or at the top level
+func (s *InstitutionManagementService) MoveCategory(ctx context.Context, req MoveCategoryRequest) (*Category, error) {
+	instituteId, tree, category, err := s.adminCategory(ctx, req.ActorId, req.InstitutionId, req.CategoryId)
+	if err != nil {
+		return nil, err
+	}
+	parentId, err := optionalId(req.ParentId)
+	if err != nil {
+		var valErrs errors.ValidationErrors
+		valErrs.Add("parentId", err.Error())
+		return nil, &valErrs
+	}
+	if parentId != nil && tree.Get(*parentId) == nil {
+		return nil, institute_repo.ErrCategoryNotFound
+	}
+	if err := tree.CanPlace(category.Kind(), parentId, category.Id(), tree.Height(category.Id())); err != nil {
+		return nil, err
+	}
+	if tree.HasSibling(parentId, category.Name(), category.Id()) {
+		return nil, ErrCategoryExists
+	}
+	before := categoryAuditFields(category)
+	category.MoveTo(parentId)
+	if err := s.instituteRepo.UpdateCategory(ctx, instituteId, category); err != nil {
+		return nil, fmt.Errorf("failed to move category: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "category.move",
+		TargetType:    auditlog.TargetCategory,
+		TargetId:      req.CategoryId,
+		InstitutionId: &req.InstitutionId,
+		Before:        before,
+		After:         categoryAuditFields(category),
+	})
+	mapped := mapToServiceCategory(category)
+	return &mapped, nil
+}
+
+// DeleteCategory removes a category, its courses and staff move up to its parent.
+// A category with sub categories is only deleted with reparent, which moves them up to its parent as well.
+func (s *InstitutionManagementService) DeleteCategory(ctx context.Context, actorId, institutionId, categoryId int, reparent bool) error {
+	instituteId, tree, category, err := s.adminCategory(ctx, actorId, institutionId, categoryId)
+	if err != nil {
+		return err
+	}
+	children := tree.Children(category.Id())
+	if len(children) > 0 {
+		if !reparent {
+			return ErrCategoryHasChildren
+		}
+		for _, child := range children {
+			if tree.HasSibling(category.ParentId(), tree.Get(child).Name(), category.Id()) {
+				return fmt.Errorf("%w: %s", ErrCategoryExists, tree.Get(child).Name().String())
+			}
+		}
+	}
+	if err := s.instituteRepo.DeleteCategory(ctx, instituteId, category.Id(), category.ParentId()); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	after := map[string]any{"reparented": len(children)}
+	if category.ParentId() != nil {
+		after["parentId"] = category.ParentId().Value()
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "category.delete",
+		TargetType:    auditlog.TargetCategory,
+		TargetId:      categoryId,
+		InstitutionId: &institutionId,
+		Before:        categoryAuditFields(category),
+		After:         after,
+	})
+	return nil
+}
+
+// AddStaffToCategory scopes the staff to the category, a staff can be in several
+func (s *InstitutionManagementService) AddStaffToCategory(ctx context.Context, req CategoryStaffRequest) error {
+	instituteId, categoryId, staffId, err := s.parseCategoryStaff(ctx, req)
+	if err != nil {
+		return err
+	}
+	if err := s.instituteRepo.AddStaffToCategory(ctx, instituteId, categoryId, staffId); err != nil {
+		return fmt.Errorf("failed to add staff to category: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "category.staff.add",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      req.StaffId,
+		InstitutionId: &req.InstitutionId,
+		After:         map[string]any{"categoryId": req.CategoryId},
+	})
+	return nil
+}
+
+func (s *InstitutionManagementService) RemoveStaffFromCategory(ctx context.Context, req CategoryStaffRequest) error {
+	instituteId, categoryId, staffId, err := s.parseCategoryStaff(ctx, req)
+	if err != nil {
+		return err
+	}
+	if err := s.instituteRepo.RemoveStaffFromCategory(ctx, instituteId, categoryId, staffId); err != nil {
+		return fmt.Errorf("failed to remove staff from category: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "category.staff.remove",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      req.StaffId,
+		InstitutionId: &req.InstitutionId,
+		Before:        map[string]any{"categoryId": req.CategoryId},
+	})
+	return nil
+}
+
+// GetCategoryStaff lists the staff in the category or any category under it
+func (s *InstitutionManagementService) GetCategoryStaff(ctx context.Context, actorId, institutionId, categoryId int) (*StaffResponse, error) {
+	instituteId, subtree, err := s.categorySubtree(ctx, actorId, institutionId, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.instituteRepo.ListStaffInCategories(ctx, instituteId, subtree)
+	if err != nil {
+		return nil, err
+	}
+	staff := make([]Staff, len(data))
+	for i := range data {
+		staff[i] = mapToServiceStaff(&data[i])
+	}
+	return &StaffResponse{Staff: staff, Total: len(staff)}, nil
+}
+
+// GetCategoryCourses lists the courses in the category or any category under it
+func (s *InstitutionManagementService) GetCategoryCourses(ctx context.Context, actorId, institutionId, categoryId int) (*CourseResponse, error) {
+	instituteId, subtree, err := s.categorySubtree(ctx, actorId, institutionId, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	data, total, err := s.instituteRepo.ListCourses(ctx, instituteId, institution.CourseFilter{CategoryIds: subtree})
+	if err != nil {
+		return nil, err
+	}
+	return &CourseResponse{Courses: mapToServiceCourses(data), Total: total}, nil
+}
+
+// SetCourseCategory places the course in a category, or takes it out of its category
+func (s *InstitutionManagementService) SetCourseCategory(ctx context.Context, req SetCourseCategoryRequest) (*Course, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	var valErrs errors.ValidationErrors
+	courseId, err := institution.NewId(req.CourseId)
+	if err != nil {
+		valErrs.Add("courseId", err.Error())
+	}
+	categoryId, err := optionalId(req.CategoryId)
+	if err != nil {
+		valErrs.Add("categoryId", err.Error())
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
+	}
+	course, err := s.instituteRepo.GetCourseById(ctx, instituteId, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if categoryId != nil {
+		tree, err := s.categoryTree(ctx, instituteId)
+		if err != nil {
+			return nil, err
+		}
+		if tree.Get(*categoryId) == nil {
+			return nil, institute_repo.ErrCategoryNotFound
+		}
+	}
+	before := map[string]any{"categoryId": optionalIdValue(course.CategoryId())}
+	course.PlaceIn(categoryId)
+	if err := s.instituteRepo.SetCourseCategory(ctx, instituteId, course); err != nil {
+		return nil, fmt.Errorf("failed to set course category: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "course.category.set",
+		TargetType:    auditlog.TargetCourse,
+		TargetId:      req.CourseId,
+		InstitutionId: &req.InstitutionId,
+		Before:        before,
+		After:         map[string]any{"categoryId": optionalIdValue(categoryId)},
+	})
+	mapped := mapToServiceCourse(course)
+	return &mapped, nil
+}
+
+func (s *InstitutionManagementService) categoryTree(ctx context.Context, instituteId institution.Id) (*institution.CategoryTree, error) {
+	categories, err := s.instituteRepo.ListCategories(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	return institution.NewCategoryTree(categories), nil
+}
+
+// adminCategory loads the tree and the category an admin is changing
+func (s *InstitutionManagementService) adminCategory(ctx context.Context, actorId, institutionId, categoryId int) (institution.Id, *institution.CategoryTree, *institution.Category, error) {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	parsedCategoryId, err := institution.NewId(categoryId)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("error parsing categoryId: %w", err)
+	}
+	tree, err := s.categoryTree(ctx, instituteId)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	category := tree.Get(parsedCategoryId)
+	if category == nil {
+		return 0, nil, nil, institute_repo.ErrCategoryNotFound
+	}
+	return instituteId, tree, category, nil
+}
+
+func (s *InstitutionManagementService) categorySubtree(ctx context.Context, actorId, institutionId, categoryId int) (institution.Id, []institution.Id, error) {
+	instituteId, err := s.requireMember(ctx, actorId, institutionId)
+	if err != nil {
+		return 0, nil, err
+	}
+	subtree, err := s.subtreeOf(ctx, instituteId, categoryId)
+	if err != nil {
+		return 0, nil, err
+	}
+	return instituteId, subtree, nil
+}
+
+// categoryFilter returns the ids a listing filtered by the category matches against,
+// the category and every category under it, or nil when the listing is not filtered
+func (s *InstitutionManagementService) categoryFilter(ctx context.Context, instituteId institution.Id, categoryId *int) ([]institution.Id, error) {
+	if categoryId == nil {
+		return nil, nil
+	}
+	return s.subtreeOf(ctx, instituteId, *categoryId)
+}
+
+func (s *InstitutionManagementService) subtreeOf(ctx context.Context, instituteId institution.Id, categoryId int) ([]institution.Id, error) {
+	parsedCategoryId, err := institution.NewId(categoryId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing categoryId: %w", err)
+	}
+	tree, err := s.categoryTree(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	subtree := tree.Subtree(parsedCategoryId)
+	if subtree == nil {
+		return nil, institute_repo.ErrCategoryNotFound
+	}
+	return subtree, nil
+}
+
+// parseCategoryStaff validates the ids of a category membership change made by an admin
+func (s *InstitutionManagementService) parseCategoryStaff(ctx context.Context, req CategoryStaffRequest) (instituteId, categoryId, staffId institution.Id, err error) {
+	var valErrs errors.ValidationErrors
+	categoryId, err = institution.NewId(req.CategoryId)
+	if err != nil {
+		valErrs.Add("categoryId", err.Error())
+	}
+	staffId, err = institution.NewId(req.StaffId)
+	if err != nil {
+		valErrs.Add("staffId", err.Error())
+	}
+	if valErrs.HasErrors() {
+		return 0, 0, 0, &valErrs
+	}
+	instituteId, err = s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	return instituteId, categoryId, staffId, err
+}
+
+func optionalId(id *int) (*institution.Id, error) {
+	if id == nil {
+		return nil, nil
+	}
+	parsed, err := institution.NewId(*id)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func optionalIdValue(id *institution.Id) any {
+	if id == nil {
+		return nil
+	}
+	return id.Value()
+}
+
+func categoryNodes(tree *institution.CategoryTree, parent institution.Id) []CategoryNode {
+	children := tree.Children(parent)
+	nodes := make([]CategoryNode, len(children))
+	for i, id := range children {
+		nodes[i] = CategoryNode{
+			Category: mapToServiceCategory(tree.Get(id)),
+			Children: categoryNodes(tree, id),
+		}
+	}
+	return nodes
+}
+
+func categoryAuditFields(c *institution.Category) map[string]any {
+	return map[string]any{
+		"name":     c.Name().String(),
+		"kind":     c.Kind().String(),
+		"parentId": optionalIdValue(c.ParentId()),
+	}
+}
+
+func mapToServiceCategory(c *institution.Category) Category {
+	category := Category{
+		Id:        c.Id().Value(),
+		Kind:      c.Kind().String(),
+		Name:      c.Name().String(),
+		CreatedAt: c.CreatedAt().String(),
+		UpdatedAt: c.UpdatedAt().String(),
+	}
+	if c.ParentId() != nil {
+		parentId := c.ParentId().Value()
+		category.ParentId = &parentId
+	}
+	return category
+}
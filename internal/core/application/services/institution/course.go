@@ -0,0 +1,342 @@
+package institution
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	errors "github.com/kaasikodes/assessmate_backend/internal/shared"
+)
+
+// ErrCourseAccessDenied is returned when none of the staff's groups can access the course
+var ErrCourseAccessDenied = stderrors.New("none of your groups can access this course")
+
+type (
+	CreateCourseRequest struct {
+		ActorId, InstitutionId int
+		Name, Description      string
+	}
+	// UpdateCourseRequest changes the fields that are set
+	UpdateCourseRequest struct {
+		ActorId, InstitutionId, CourseId int
+		Name, Description                *string
+	}
+	GroupCourseRequest struct {
+		ActorId, InstitutionId, GroupId, CourseId int
+	}
+	// ListCoursesRequest, a CategoryId keeps the courses in the category or any category under it
+	ListCoursesRequest struct {
+		ActorId, InstitutionId int
+		CategoryId             *int
+		Pagination             Pagination
+	}
+	// CourseResponse, Page and PageSize are only set on the listings that are paged
+	CourseResponse struct {
+		Courses  []Course
+		Total    int
+		Page     int
+		PageSize int
+	}
+)
+
+// CreateCourse
+func (s *InstitutionManagementService) CreateCourse(ctx context.Context, req CreateCourseRequest) (*Course, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	var valErrs errors.ValidationErrors
+	name, err := institution.NewName(req.Name)
+	if err != nil {
+		valErrs.Add("name", err.Error())
+	}
+	desc, err := institution.NewDescription(req.Description)
+	if err != nil {
+		valErrs.Add("description", err.Error())
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
+	}
+	course, err := institution.NewCourse(name, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.instituteRepo.CreateCourse(ctx, instituteId, course)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create course: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "course.create",
+		TargetType:    auditlog.TargetCourse,
+		TargetId:      created.Id().Value(),
+		InstitutionId: &req.InstitutionId,
+		After:         courseAuditFields(created),
+	})
+	mapped := mapToServiceCourse(created)
+	return &mapped, nil
+}
+
+// GetCourse
+func (s *InstitutionManagementService) GetCourse(ctx context.Context, actorId, institutionId, courseId int) (*Course, error) {
+	instituteId, err := s.requireMember(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	parsedCourseId, err := institution.NewId(courseId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing courseId: %w", err)
+	}
+	course, err := s.instituteRepo.GetCourseById(ctx, instituteId, parsedCourseId)
+	if err != nil {
+		return nil, err
+	}
+	mapped := mapToServiceCourse(course)
+	return &mapped, nil
+}
+
+// GetCourses lists a page of the courses of the institution
+func (s *InstitutionManagementService) GetCourses(ctx context.Context, req ListCoursesRequest) (*CourseResponse, error) {
+	instituteId, err := s.requireMember(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	var filter institution.CourseFilter
+	if filter.CategoryIds, err = s.categoryFilter(ctx, instituteId, req.CategoryId); err != nil {
+		return nil, err
+	}
+	pagination, page := req.Pagination.normalise()
+	filter.Page = page
+	data, total, err := s.instituteRepo.ListCourses(ctx, instituteId, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &CourseResponse{Courses: mapToServiceCourses(data), Total: total, Page: pagination.Page, PageSize: pagination.PageSize}, nil
+}
+
+// UpdateCourse
+func (s *InstitutionManagementService) UpdateCourse(ctx context.Context, req UpdateCourseRequest) (*Course, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	courseId, err := institution.NewId(req.CourseId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing courseId: %w", err)
+	}
+	course, err := s.instituteRepo.GetCourseById(ctx, instituteId, courseId)
+	if err != nil {
+		return nil, err
+	}
+	before := courseAuditFields(course)
+
+	var valErrs errors.ValidationErrors
+	if req.Name != nil {
+		if name, err := institution.NewName(*req.Name); err != nil {
+			valErrs.Add("name", err.Error())
+		} else if err := course.UpdateName(name); err != nil {
+			valErrs.Add("name", err.Error())
+		}
+	}
+	if req.Description != nil {
+		if desc, err := institution.NewDescription(*req.Description); err != nil {
+			valErrs.Add("description", err.Error())
+		} else if err := course.UpdateDescription(desc); err != nil {
+			valErrs.Add("description", err.Error())
+		}
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
+	}
+
+	if err := s.instituteRepo.UpdateCourse(ctx, instituteId, course); err != nil {
+		return nil, fmt.Errorf("failed to update course: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "course.update",
+		TargetType:    auditlog.TargetCourse,
+		TargetId:      req.CourseId,
+		InstitutionId: &req.InstitutionId,
+		Before:        before,
+		After:         courseAuditFields(course),
+	})
+	updated := mapToServiceCourse(course)
+	return &updated, nil
+}
+
+// DeleteCourse, the groups that could access it lose that access
+func (s *InstitutionManagementService) DeleteCourse(ctx context.Context, actorId, institutionId, courseId int) error {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return err
+	}
+	parsedCourseId, err := institution.NewId(courseId)
+	if err != nil {
+		return fmt.Errorf("error parsing courseId: %w", err)
+	}
+	course, err := s.instituteRepo.GetCourseById(ctx, instituteId, parsedCourseId)
+	if err != nil {
+		return err
+	}
+	if err := s.instituteRepo.DeleteCourse(ctx, instituteId, parsedCourseId); err != nil {
+		return fmt.Errorf("failed to delete course: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "course.delete",
+		TargetType:    auditlog.TargetCourse,
+		TargetId:      courseId,
+		InstitutionId: &institutionId,
+		Before:        courseAuditFields(course),
+	})
+	return nil
+}
+
+// GrantGroupCourseAccess lets the staff of the group create assessments in the course
+func (s *InstitutionManagementService) GrantGroupCourseAccess(ctx context.Context, req GroupCourseRequest) error {
+	instituteId, groupId, courseId, err := s.parseGroupCourse(ctx, req)
+	if err != nil {
+		return err
+	}
+	if err := s.instituteRepo.AddAccessibleCourseToGroup(ctx, instituteId, groupId, courseId); err != nil {
+		return fmt.Errorf("failed to grant course access: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "group.course.grant",
+		TargetType:    auditlog.TargetGroup,
+		TargetId:      req.GroupId,
+		InstitutionId: &req.InstitutionId,
+		After:         map[string]any{"courseId": req.CourseId},
+	})
+	return nil
+}
+
+// RevokeGroupCourseAccess, assessments already created in the course are kept
+func (s *InstitutionManagementService) RevokeGroupCourseAccess(ctx context.Context, req GroupCourseRequest) error {
+	instituteId, groupId, courseId, err := s.parseGroupCourse(ctx, req)
+	if err != nil {
+		return err
+	}
+	if err := s.instituteRepo.RemoveAccessibleCourseFromGroup(ctx, instituteId, groupId, courseId); err != nil {
+		return fmt.Errorf("failed to revoke course access: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "group.course.revoke",
+		TargetType:    auditlog.TargetGroup,
+		TargetId:      req.GroupId,
+		InstitutionId: &req.InstitutionId,
+		Before:        map[string]any{"courseId": req.CourseId},
+	})
+	return nil
+}
+
+// GetGroupCourses lists the courses the group can access
+func (s *InstitutionManagementService) GetGroupCourses(ctx context.Context, actorId, institutionId, groupId int) (*CourseResponse, error) {
+	instituteId, err := s.requireMember(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	parsedGroupId, err := institution.NewId(groupId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing groupId: %w", err)
+	}
+	// the group is looked up so an unknown group is not found rather than without courses
+	if _, err := s.instituteRepo.GetGroupById(ctx, instituteId, parsedGroupId); err != nil {
+		return nil, err
+	}
+	data, err := s.instituteRepo.ListAccessibleCoursesForGroup(ctx, instituteId, parsedGroupId)
+	if err != nil {
+		return nil, err
+	}
+	return &CourseResponse{Courses: mapToServiceCourses(data), Total: len(data)}, nil
+}
+
+// GetAssessableCourses lists the courses the user may create assessments in,
+// every course for an admin and the courses their groups can access for other staff.
+// A categoryId keeps the courses in the category or any category under it.
+func (s *InstitutionManagementService) GetAssessableCourses(ctx context.Context, actorId, institutionId int, categoryId *int) (*CourseResponse, error) {
+	instituteId, staff, err := s.memberStaff(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	categoryIds, err := s.categoryFilter(ctx, instituteId, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	var data []institution.Course
+	if staff.IsAdmin() {
+		data, _, err = s.instituteRepo.ListCourses(ctx, instituteId, institution.CourseFilter{CategoryIds: categoryIds})
+	} else {
+		data, err = s.instituteRepo.ListAccessibleCoursesForStaff(ctx, instituteId, staff.Id(), categoryIds)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &CourseResponse{Courses: mapToServiceCourses(data), Total: len(data)}, nil
+}
+
+// RequireCourseAccess is checked before a user creates an assessment in a course of the institution:
+// admins may use any of its courses, other staff only the courses one of their groups can access
+func (s *InstitutionManagementService) RequireCourseAccess(ctx context.Context, actorId, institutionId, courseId int) error {
+	instituteId, staff, err := s.memberStaff(ctx, actorId, institutionId)
+	if err != nil {
+		return err
+	}
+	parsedCourseId, err := institution.NewId(courseId)
+	if err != nil {
+		return fmt.Errorf("error parsing courseId: %w", err)
+	}
+	if _, err := s.instituteRepo.GetCourseById(ctx, instituteId, parsedCourseId); err != nil {
+		return err
+	}
+	if staff.IsAdmin() {
+		return nil
+	}
+	canAccess, err := s.instituteRepo.CanStaffAccessCourse(ctx, instituteId, staff.Id(), parsedCourseId)
+	if err != nil {
+		return fmt.Errorf("error checking course access: %w", err)
+	}
+	if !canAccess {
+		return ErrCourseAccessDenied
+	}
+	return nil
+}
+
+// parseGroupCourse validates the ids of a course access change made by an admin
+func (s *InstitutionManagementService) parseGroupCourse(ctx context.Context, req GroupCourseRequest) (instituteId, groupId, courseId institution.Id, err error) {
+	var valErrs errors.ValidationErrors
+	groupId, err = institution.NewId(req.GroupId)
+	if err != nil {
+		valErrs.Add("groupId", err.Error())
+	}
+	courseId, err = institution.NewId(req.CourseId)
+	if err != nil {
+		valErrs.Add("courseId", err.Error())
+	}
+	if valErrs.HasErrors() {
+		return 0, 0, 0, &valErrs
+	}
+	instituteId, err = s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	return instituteId, groupId, courseId, err
+}
+
+func courseAuditFields(c *institution.Course) map[string]any {
+	return map[string]any{
+		"name":        c.Name().String(),
+		"description": c.Description().String(),
+	}
+}
+
+func mapToServiceCourses(data []institution.Course) []Course {
+	courses := make([]Course, len(data))
+	for i := range data {
+		courses[i] = mapToServiceCourse(&data[i])
+	}
+	return courses
+}
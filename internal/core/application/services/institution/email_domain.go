@@ -0,0 +1,291 @@
+package institution
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+	errors "github.com/kaasikodes/assessmate_backend/internal/shared"
+)
+
+var ErrInvalidDomainConfirmation = stderrors.New("the domain confirmation link is invalid or has expired")
+
+type (
+	// ClaimEmailDomainRequest, Mailbox is only used to verify by mailbox
+	ClaimEmailDomainRequest struct {
+		ActorId, InstitutionId int
+		Domain, Method         string
+		Mailbox                string
+		Policy                 string // pending when empty
+		ConfirmUrl             string // the link in the mailbox email, the token is added to it
+	}
+	UpdateEmailDomainRequest struct {
+		ActorId, InstitutionId, DomainId int
+		Policy                           string
+	}
+	EmailDomain struct {
+		Id      int
+		Domain  string
+		Method  string
+		Mailbox string // where the confirmation link was sent
+		Policy  string
+		Status  string
+		// TxtRecordName and TxtRecordValue are the DNS record to publish, until the domain is verified
+		TxtRecordName  string
+		TxtRecordValue string
+		ExpiresAt      *time.Time
+		VerifiedAt     *time.Time
+		CreatedAt      time.Time
+	}
+)
+
+// ClaimEmailDomain starts verifying that the institution controls the domain,
+// by a TXT record to publish or a link sent to an admin mailbox of the domain.
+func (s *InstitutionManagementService) ClaimEmailDomain(ctx context.Context, req ClaimEmailDomainRequest) (*EmailDomain, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	var valErrs errors.ValidationErrors
+	domainName, err := institution.NewDomainName(req.Domain)
+	if err != nil {
+		valErrs.Add("domain", err.Error())
+	}
+	method, err := institution.NewDomainVerificationMethod(req.Method)
+	if err != nil {
+		valErrs.Add("method", err.Error())
+	}
+	policy := institution.JoinAsPending
+	if req.Policy != "" {
+		if policy, err = institution.NewDomainJoinPolicy(req.Policy); err != nil {
+			valErrs.Add("policy", err.Error())
+		}
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
+	}
+	domain, err := institution.NewEmailDomain(instituteId, domainName, method, req.Mailbox, policy)
+	if err != nil {
+		valErrs.Add("mailbox", err.Error())
+		return nil, &valErrs
+	}
+	token := s.randomIdGenerator.Create("assessmate-verification=", 32)
+	if method == institution.VerifyByMailbox {
+		token = s.randomIdGenerator.Create("domain_", 32)
+	}
+	if err := domain.SetToken(token); err != nil {
+		return nil, err
+	}
+	inst, err := s.instituteRepo.GetInstitutionById(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	domain, err = s.instituteRepo.CreateEmailDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if method == institution.VerifyByMailbox {
+		s.sendInBackground(ctx, []email.Notification{domainConfirmNotification(inst, domain, req.ConfirmUrl)})
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "institution.domain.claim",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      req.InstitutionId,
+		InstitutionId: &req.InstitutionId,
+		After:         emailDomainAuditFields(domain),
+	})
+	mapped := mapToServiceEmailDomain(domain)
+	return &mapped, nil
+}
+
+// ListEmailDomains returns the domains the institution claimed
+func (s *InstitutionManagementService) ListEmailDomains(ctx context.Context, actorId, institutionId int) ([]EmailDomain, error) {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.instituteRepo.ListEmailDomains(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	domains := make([]EmailDomain, len(data))
+	for i := range data {
+		domains[i] = mapToServiceEmailDomain(&data[i])
+	}
+	return domains, nil
+}
+
+// VerifyEmailDomain looks up the TXT record of a domain claimed by DNS, the domain is verified once it is published
+func (s *InstitutionManagementService) VerifyEmailDomain(ctx context.Context, actorId, institutionId, domainId int) (*EmailDomain, error) {
+	instituteId, domain, err := s.adminEmailDomain(ctx, actorId, institutionId, domainId)
+	if err != nil {
+		return nil, err
+	}
+	if domain.Method() != institution.VerifyByDns {
+		return nil, stderrors.New("the domain is verified with the link sent to its admin mailbox")
+	}
+	if s.txtResolver == nil {
+		return nil, stderrors.New("verifying domains by DNS is not available")
+	}
+	records, err := s.txtResolver.LookupTXT(ctx, domain.TxtRecordName())
+	if err != nil {
+		return nil, fmt.Errorf("error looking up the TXT record: %w", err)
+	}
+	if err := domain.VerifyTxtRecords(records, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.instituteRepo.UpdateEmailDomain(ctx, domain); err != nil {
+		return nil, err
+	}
+	s.recordDomainVerified(ctx, actorId, instituteId, domain)
+	mapped := mapToServiceEmailDomain(domain)
+	return &mapped, nil
+}
+
+// ConfirmEmailDomain verifies a domain claimed by mailbox, the token in the link sent to the mailbox identifies it
+func (s *InstitutionManagementService) ConfirmEmailDomain(ctx context.Context, token string) (*EmailDomain, error) {
+	domain, err := s.instituteRepo.GetEmailDomainByToken(ctx, token)
+	if stderrors.Is(err, institute_repo.ErrEmailDomainNotFound) {
+		return nil, ErrInvalidDomainConfirmation
+	}
+	if err != nil {
+		return nil, err
+	}
+	if domain.IsVerified() {
+		return nil, ErrInvalidDomainConfirmation
+	}
+	if err := domain.ConfirmMailbox(time.Now()); err != nil {
+		return nil, ErrInvalidDomainConfirmation
+	}
+	if err := s.instituteRepo.UpdateEmailDomain(ctx, domain); err != nil {
+		return nil, err
+	}
+	// whoever reads the mailbox may not have an account, so there is no actor
+	s.recordDomainVerified(ctx, 0, domain.InstitutionId(), domain)
+	mapped := mapToServiceEmailDomain(domain)
+	return &mapped, nil
+}
+
+// UpdateEmailDomain changes whether people who sign up with the domain join as active or inactive staff
+func (s *InstitutionManagementService) UpdateEmailDomain(ctx context.Context, req UpdateEmailDomainRequest) (*EmailDomain, error) {
+	_, domain, err := s.adminEmailDomain(ctx, req.ActorId, req.InstitutionId, req.DomainId)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := institution.NewDomainJoinPolicy(req.Policy)
+	if err != nil {
+		var valErrs errors.ValidationErrors
+		valErrs.Add("policy", err.Error())
+		return nil, &valErrs
+	}
+	before := emailDomainAuditFields(domain)
+	domain.ChangePolicy(policy)
+	if err := s.instituteRepo.UpdateEmailDomain(ctx, domain); err != nil {
+		return nil, fmt.Errorf("failed to update email domain: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "institution.domain.update",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      req.InstitutionId,
+		InstitutionId: &req.InstitutionId,
+		Before:        before,
+		After:         emailDomainAuditFields(domain),
+	})
+	mapped := mapToServiceEmailDomain(domain)
+	return &mapped, nil
+}
+
+// RemoveEmailDomain stops people joining by the domain, the staff who already joined stay
+func (s *InstitutionManagementService) RemoveEmailDomain(ctx context.Context, actorId, institutionId, domainId int) error {
+	instituteId, domain, err := s.adminEmailDomain(ctx, actorId, institutionId, domainId)
+	if err != nil {
+		return err
+	}
+	if err := s.instituteRepo.DeleteEmailDomain(ctx, instituteId, domain.Id()); err != nil {
+		return fmt.Errorf("failed to remove email domain: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "institution.domain.remove",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      institutionId,
+		InstitutionId: &institutionId,
+		Before:        emailDomainAuditFields(domain),
+	})
+	return nil
+}
+
+func (s *InstitutionManagementService) adminEmailDomain(ctx context.Context, actorId, institutionId, domainId int) (institution.Id, *institution.EmailDomain, error) {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return 0, nil, err
+	}
+	parsedDomainId, err := institution.NewId(domainId)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error parsing domainId: %w", err)
+	}
+	domain, err := s.instituteRepo.GetEmailDomainById(ctx, instituteId, parsedDomainId)
+	if err != nil {
+		return 0, nil, err
+	}
+	return instituteId, domain, nil
+}
+
+func (s *InstitutionManagementService) recordDomainVerified(ctx context.Context, actorId int, instituteId institution.Id, domain *institution.EmailDomain) {
+	institutionId := instituteId.Value()
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "institution.domain.verify",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      institutionId,
+		InstitutionId: &institutionId,
+		Before:        map[string]any{"domain": domain.Domain().String(), "status": institution.DomainPending.String()},
+		After:         map[string]any{"domain": domain.Domain().String(), "status": domain.Status().String()},
+	})
+}
+
+func domainConfirmNotification(inst *institution.Institution, domain *institution.EmailDomain, confirmUrl string) email.Notification {
+	link := confirmUrl + "?" + url.Values{"token": {domain.Token()}}.Encode()
+	content := fmt.Sprintf("%s wants anyone signing up to Assessmate with an @%s email to join it. If you run %s, use this link to confirm, it expires on %s: %s",
+		inst.Name().String(), domain.Domain().String(), domain.Domain().String(), domain.ExpiresAt().Format(time.RFC1123), link)
+	return email.Notification{
+		Email:   domain.MailboxAddress().String(),
+		Title:   fmt.Sprintf("Confirm %s for %s", domain.Domain().String(), inst.Name().String()),
+		Content: content,
+	}
+}
+
+func emailDomainAuditFields(d *institution.EmailDomain) map[string]any {
+	return map[string]any{
+		"domain": d.Domain().String(),
+		"method": d.Method().String(),
+		"policy": d.Policy().String(),
+	}
+}
+
+func mapToServiceEmailDomain(d *institution.EmailDomain) EmailDomain {
+	domain := EmailDomain{
+		Id:         d.Id().Value(),
+		Domain:     d.Domain().String(),
+		Method:     d.Method().String(),
+		Mailbox:    d.MailboxAddress().String(),
+		Policy:     d.Policy().String(),
+		Status:     d.Status().String(),
+		ExpiresAt:  d.ExpiresAt(),
+		VerifiedAt: d.VerifiedAt(),
+		CreatedAt:  d.CreatedAt(),
+	}
+	if d.Method() == institution.VerifyByDns && !d.IsVerified() {
+		domain.TxtRecordName = d.TxtRecordName()
+		domain.TxtRecordValue = d.Token()
+	}
+	return domain
+}
@@ -2,12 +2,18 @@ package institution
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"time"
 
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
 	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	dnsresolver "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/dns-resolver"
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/document"
 	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
 	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/logger"
+	randomidgenerator "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/random-id-generator"
 	errors "github.com/kaasikodes/assessmate_backend/internal/shared"
 )
 
@@ -15,22 +21,43 @@ import (
 
 type (
 	InviteStaffRequest struct {
-		Staff         []StaffInvite
-		InstitutionId int
+		Staff                  []StaffInvite
+		ActorId, InstitutionId int
+		AcceptUrl              string // the link in the email, the token is added to it
 	}
 	StaffInvite struct {
 		Name, Email string
+		Role        string // member when empty, only used for people who are not staff yet
 	}
 
+	// CreateInstitutionRequest, the creator becomes the owner of the institution
 	CreateInstitutionRequest struct {
 		Name, Email, Description string
+		CreatorId                int
+		CreatorName              string
+		CreatorEmail             string
+	}
+	// UpdateInstitutionRequest changes the fields that are set
+	UpdateInstitutionRequest struct {
+		ActorId, InstitutionId   int
+		Name, Description, Email *string
+	}
+	AddStaffRequest struct {
+		ActorId, InstitutionId int
+		Name, Email            string
+		Role                   string // member when empty
 	}
 	AddStaffToGroupRequest struct {
-		InstitutionId, GroupId, StaffId int
+		ActorId, InstitutionId, GroupId, StaffId int
 	}
 	CreateGroupRequest struct {
-		Name, Description string
-		InstitutionId     int
+		Name, Description      string
+		ActorId, InstitutionId int
+	}
+	// UpdateGroupRequest changes the fields that are set
+	UpdateGroupRequest struct {
+		ActorId, InstitutionId, GroupId int
+		Name, Description               *string
 	}
 
 	CreateGroupResponse struct {
@@ -43,71 +70,161 @@ type (
 		Name        string
 		Description string
 		Email       string
-		CreatedAt   string
-		UpdatedAt   string
-	}
-	Staff struct {
-		Id        int
-		Name      string
-		Email     string
-		Status    string
+		// Owner is responsible for the institution and its billing, it changes with an ownership transfer
+		Owner     *Staff
 		CreatedAt string
 		UpdatedAt string
 	}
+	Staff struct {
+		Id     int
+		UserId *int // nil until the staff has an account
+		Name   string
+		Email  string
+		Status string
+		// StatusReason is why the staff was suspended or blacklisted
+		StatusReason    string
+		StatusChangedAt *string
+		Role            string
+		// RestorableUntil is set for a removed staff, RestoreStaff works until then
+		RestorableUntil *string
+		CreatedAt       string
+		UpdatedAt       string
+	}
 	Group struct {
-		Id        int
-		Name      string
-		CreatedAt string
-		UpdatedAt string
+		Id          int
+		Name        string
+		Description string
+		CreatedAt   string
+		UpdatedAt   string
+	}
+	Course struct {
+		Id          int
+		Name        string
+		Description string
+		CategoryId  *int // the department the course is in
+		CreatedAt   string
+		UpdatedAt   string
+	}
+	// GroupDetail is a group with its staff and the courses it can access
+	GroupDetail struct {
+		Group
+		Staff   []Staff
+		Courses []Course
+	}
+	// Pagination asks for one page of a listing
+	Pagination struct {
+		Page     int // starts at 1
+		PageSize int
 	}
 	StaffResponse struct {
-		Staff []Staff
-		Total int
+		Staff    []Staff
+		Total    int
+		Page     int
+		PageSize int
 	}
 	GroupResponse struct {
-		Groups []Group
-		Total  int
+		Groups   []Group
+		Total    int
+		Page     int
+		PageSize int
+	}
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// normalise fills in the defaults and returns the page to ask the repository for
+func (p Pagination) normalise() (Pagination, institution.Page) {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PageSize < 1 {
+		p.PageSize = defaultPageSize
 	}
+	if p.PageSize > maxPageSize {
+		p.PageSize = maxPageSize
+	}
+	return p, institution.Page{Limit: p.PageSize, Offset: (p.Page - 1) * p.PageSize}
+}
+
+var (
+	ErrNotInstitutionAdmin  = stderrors.New("institution admin role required")
+	ErrNotInstitutionMember = stderrors.New("you are not a member of this institution")
+	ErrCannotRemoveSelf     = stderrors.New("admins cannot remove themselves from the institution")
 )
 
 type InstitutionManagementService struct {
-	instituteRepo institute_repo.InstitutionRepository
-	emailClient   email.EmailClient
+	instituteRepo     institute_repo.InstitutionRepository
+	emailClient       email.EmailClient
+	logger            logger.Logger
+	randomIdGenerator randomidgenerator.RandomIdGenerator
+	accounts          AccountProvisioner
+	spreadsheets      document.SpreadsheetReader
+	audit             *auditlog.AuditLogService
+	txtResolver       dnsresolver.TxtResolver
+	analytics         *analyticsCache
 }
 
 // Constructor
-func NewInstitutionManagementService(repo institute_repo.InstitutionRepository) *InstitutionManagementService {
+func NewInstitutionManagementService(repo institute_repo.InstitutionRepository, emailClient email.EmailClient, logger logger.Logger, randomIdGenerator randomidgenerator.RandomIdGenerator, accounts AccountProvisioner, spreadsheets document.SpreadsheetReader, audit *auditlog.AuditLogService, txtResolver dnsresolver.TxtResolver) *InstitutionManagementService {
 	return &InstitutionManagementService{
-		instituteRepo: repo,
+		instituteRepo:     repo,
+		emailClient:       emailClient,
+		logger:            logger,
+		randomIdGenerator: randomIdGenerator,
+		accounts:          accounts,
+		spreadsheets:      spreadsheets,
+		audit:             audit,
+		txtResolver:       txtResolver,
+		analytics:         newAnalyticsCache(analyticsCacheTTL),
 	}
 }
 
 // CreateInstitution handles the creation of a new institution.
 func (s *InstitutionManagementService) CreateInstitution(ctx context.Context, req CreateInstitutionRequest) (*CreateInstitutionResponse, error) {
+	var valErrs errors.ValidationErrors
+
 	name, err := institution.NewName(req.Name)
 	if err != nil {
-		return nil, fmt.Errorf("invalid institution name: %w", err)
+		valErrs.Add("name", err.Error())
 	}
-
 	desc, err := institution.NewDescription(req.Description)
 	if err != nil {
-		return nil, fmt.Errorf("invalid description: %w", err)
+		valErrs.Add("description", err.Error())
 	}
-
 	email, err := institution.NewEmail(req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("invalid email: %w", err)
+		valErrs.Add("email", err.Error())
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
 	}
 
 	inst, err := institution.NewInstitution(name, desc, email)
 	if err != nil {
 		return nil, err
 	}
+	creator, err := newCreatorStaff(req)
+	if err != nil {
+		return nil, err
+	}
+	inst.AddStaff(*creator)
 
 	created, err := s.instituteRepo.CreateInstitution(ctx, inst)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create institution: %w", err)
 	}
+	institutionId := created.Id().Value()
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.CreatorId,
+		Action:        "institution.create",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      institutionId,
+		InstitutionId: &institutionId,
+		After:         institutionAuditFields(created),
+	})
 
 	return &CreateInstitutionResponse{
 		Id:          created.Id().Value(),
@@ -119,9 +236,32 @@ func (s *InstitutionManagementService) CreateInstitution(ctx context.Context, re
 	}, nil
 }
 
-// get InstitutionById
-func (s *InstitutionManagementService) GetInstitutionById(ctx context.Context, id int) (*CreateInstitutionResponse, error) {
-	instituteId, err := institution.NewId(id)
+// newCreatorStaff is the active owner staff record of whoever creates the institution
+func newCreatorStaff(req CreateInstitutionRequest) (*institution.Staff, error) {
+	userId, err := institution.NewId(req.CreatorId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing creatorId: %w", err)
+	}
+	name, err := institution.NewName(req.CreatorName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid creator name: %w", err)
+	}
+	email, err := institution.NewEmail(req.CreatorEmail)
+	if err != nil {
+		return nil, fmt.Errorf("invalid creator email: %w", err)
+	}
+	staff, err := institution.NewStaff(name, email, institution.Active)
+	if err != nil {
+		return nil, err
+	}
+	staff.SetRole(institution.OwnerRole)
+	staff.LinkUser(userId)
+	return staff, nil
+}
+
+// get InstitutionById, only its members can see it
+func (s *InstitutionManagementService) GetInstitutionById(ctx context.Context, actorId, id int) (*CreateInstitutionResponse, error) {
+	instituteId, err := s.requireMember(ctx, actorId, id)
 	if err != nil {
 		return nil, err
 	}
@@ -129,55 +269,236 @@ func (s *InstitutionManagementService) GetInstitutionById(ctx context.Context, i
 	if err != nil {
 		return nil, err
 	}
-	return &CreateInstitutionResponse{
+	res := &CreateInstitutionResponse{
 		Id:          data.Id().Value(),
 		Name:        data.Name().String(),
 		Description: data.Description().String(),
 		Email:       data.Email().String(),
 		CreatedAt:   data.CreatedAt().Format(time.RFC1123),
 		UpdatedAt:   data.UpdatedAt().Format(time.RFC1123),
-	}, nil
+	}
+	owner, err := s.institutionOwner(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		mapped := mapToServiceStaff(owner)
+		mapped.StatusReason = ""
+		res.Owner = &mapped
+	}
+	return res, nil
+
+}
+
+// UpdateInstitution
+func (s *InstitutionManagementService) UpdateInstitution(ctx context.Context, req UpdateInstitutionRequest) (*CreateInstitutionResponse, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	inst, err := s.instituteRepo.GetInstitutionById(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	before := institutionAuditFields(inst)
+
+	var valErrs errors.ValidationErrors
+	if req.Name != nil {
+		if name, err := institution.NewName(*req.Name); err != nil {
+			valErrs.Add("name", err.Error())
+		} else if err := inst.UpdateName(name); err != nil {
+			valErrs.Add("name", err.Error())
+		}
+	}
+	if req.Description != nil {
+		if desc, err := institution.NewDescription(*req.Description); err != nil {
+			valErrs.Add("description", err.Error())
+		} else if err := inst.UpdateDescription(desc); err != nil {
+			valErrs.Add("description", err.Error())
+		}
+	}
+	if req.Email != nil {
+		if email, err := institution.NewEmail(*req.Email); err != nil {
+			valErrs.Add("email", err.Error())
+		} else if err := inst.UpdateEmail(email); err != nil {
+			valErrs.Add("email", err.Error())
+		}
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
+	}
 
+	if err := s.instituteRepo.UpdateInstitution(ctx, inst); err != nil {
+		return nil, fmt.Errorf("failed to update institution: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "institution.update",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      req.InstitutionId,
+		InstitutionId: &req.InstitutionId,
+		Before:        before,
+		After:         institutionAuditFields(inst),
+	})
+	return &CreateInstitutionResponse{
+		Id:          inst.Id().Value(),
+		Name:        inst.Name().String(),
+		Description: inst.Description().String(),
+		Email:       inst.Email().String(),
+		CreatedAt:   inst.CreatedAt().Format(time.RFC1123),
+		UpdatedAt:   inst.UpdatedAt().Format(time.RFC1123),
+	}, nil
 }
 
-// get staff
-// TODO Add ability to filter staff by status
-func (s *InstitutionManagementService) GetStaff(ctx context.Context, id int) (*StaffResponse, error) {
+// DeleteInstitution removes the institution with its staff, groups and courses, only its owner can
+func (s *InstitutionManagementService) DeleteInstitution(ctx context.Context, actorId, id int) error {
+	instituteId, _, err := s.requireOwner(ctx, actorId, id)
+	if err != nil {
+		return err
+	}
+	inst, err := s.instituteRepo.GetInstitutionById(ctx, instituteId)
+	if err != nil {
+		return err
+	}
+	if err := s.instituteRepo.DeleteInstitution(ctx, instituteId); err != nil {
+		return fmt.Errorf("failed to delete institution: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "institution.delete",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      id,
+		InstitutionId: &id,
+		Before:        institutionAuditFields(inst),
+	})
+	return nil
+}
 
-	instituteId, err := institution.NewId(id)
+// GetStaff lists the staff of the institution, optionally only those with the status.
+// Only admins can list the removed staff or see why a staff was suspended or blacklisted.
+func (s *InstitutionManagementService) GetStaff(ctx context.Context, actorId, id int, status string, pagination Pagination) (*StaffResponse, error) {
+	instituteId, actor, err := s.memberStaff(ctx, actorId, id)
 	if err != nil {
-		return nil, nil
+		return nil, err
+	}
+	filter, err := parseStaffFilter(status)
+	if err != nil {
+		return nil, err
 	}
-	data, total, err := s.instituteRepo.ListStaff(ctx, instituteId)
+	if filter.Removed && !actor.IsAdmin() {
+		return nil, ErrNotInstitutionAdmin
+	}
+	pagination, filter.Page = pagination.normalise()
+	data, total, err := s.instituteRepo.ListStaff(ctx, instituteId, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	staff := make([]Staff, len(data))
-	for i, d := range data {
-		staff[i].Id = d.Id().Value()
-		staff[i].Name = d.Name().String()
-		staff[i].Email = d.Email().String()
-		staff[i].Status = d.Status().String()
-		staff[i].CreatedAt = d.CreatedAt().String()
-		staff[i].UpdatedAt = d.UpdatedAt().String()
-
+	for i := range data {
+		staff[i] = mapToServiceStaff(&data[i])
+		if !actor.IsAdmin() {
+			staff[i].StatusReason = ""
+		}
 	}
 	return &StaffResponse{
-		Total: total,
-		Staff: staff,
+		Total:    total,
+		Staff:    staff,
+		Page:     pagination.Page,
+		PageSize: pagination.PageSize,
 	}, nil
 
 }
 
+// AddStaff adds a staff to the institution, they stay inactive until they accept an invite
+func (s *InstitutionManagementService) AddStaff(ctx context.Context, req AddStaffRequest) (*Staff, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	var valErrs errors.ValidationErrors
+	name, err := institution.NewName(req.Name)
+	if err != nil {
+		valErrs.Add("name", err.Error())
+	}
+	email, err := institution.NewEmail(req.Email)
+	if err != nil {
+		valErrs.Add("email", err.Error())
+	}
+	role := institution.MemberRole
+	if req.Role != "" {
+		if role, err = institution.NewStaffRole(req.Role); err != nil {
+			valErrs.Add("role", err.Error())
+		}
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
+	}
+
+	staff, err := institution.NewStaff(name, email, institution.InActive)
+	if err != nil {
+		return nil, err
+	}
+	staff.SetRole(role)
+	staff, err = s.instituteRepo.AddStaffToInstitution(ctx, instituteId, *staff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add staff: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "staff.add",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      staff.Id().Value(),
+		InstitutionId: &req.InstitutionId,
+		After:         map[string]any{"role": role.String()},
+		Redacted:      []string{"name", "email"},
+	})
+	created := mapToServiceStaff(staff)
+	return &created, nil
+}
+
+// RemoveStaff soft deletes the staff, admins cannot remove themselves so the institution is never left without one by accident
+func (s *InstitutionManagementService) RemoveStaff(ctx context.Context, actorId, institutionId, staffId int) error {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return err
+	}
+	parsedStaffId, err := institution.NewId(staffId)
+	if err != nil {
+		return fmt.Errorf("error parsing staffId: %w", err)
+	}
+	staff, err := s.instituteRepo.GetStaffById(ctx, instituteId, parsedStaffId)
+	if err != nil {
+		return err
+	}
+	if staff.UserId() != nil && staff.UserId().Value() == actorId {
+		return ErrCannotRemoveSelf
+	}
+	if staff.IsOwner() {
+		return ErrLastOwner
+	}
+	if err := s.instituteRepo.RemoveStaffFromInstitution(ctx, instituteId, parsedStaffId); err != nil {
+		return fmt.Errorf("failed to remove staff: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "staff.remove",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      staffId,
+		InstitutionId: &institutionId,
+		Before:        map[string]any{"role": staff.Role().String()},
+		Redacted:      []string{"name", "email"},
+	})
+	return nil
+}
+
 // create group
 func (s *InstitutionManagementService) CreateGroup(ctx context.Context, req CreateGroupRequest) (*CreateGroupResponse, error) {
 	var valErrs errors.ValidationErrors
 
-	instituteId, err := institution.NewId(req.InstitutionId)
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
 	if err != nil {
-		valErrs.Add("instituteId", err.Error())
-
+		return nil, err
 	}
 
 	name, err := institution.NewName(req.Name)
@@ -191,16 +512,27 @@ func (s *InstitutionManagementService) CreateGroup(ctx context.Context, req Crea
 		valErrs.Add("description", err.Error())
 	}
 
-	group, err := institution.NewGroup(name, desc)
 	if valErrs.HasErrors() {
 		return nil, &valErrs
 
 	}
+	group, err := institution.NewGroup(name, desc)
+	if err != nil {
+		return nil, err
+	}
 
 	created, err := s.instituteRepo.CreateGroup(ctx, instituteId, group)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create institution: %w", err)
+		return nil, fmt.Errorf("failed to create group: %w", err)
 	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "group.create",
+		TargetType:    auditlog.TargetGroup,
+		TargetId:      created.Id().Value(),
+		InstitutionId: &req.InstitutionId,
+		After:         groupAuditFields(created),
+	})
 
 	return &CreateGroupResponse{
 		Id:          created.Id().Value(),
@@ -209,86 +541,347 @@ func (s *InstitutionManagementService) CreateGroup(ctx context.Context, req Crea
 	}, nil
 }
 
-// add staff to group
-func (s *InstitutionManagementService) AddStaffToGroup(ctx context.Context, req AddStaffToGroupRequest) error {
-	var valErrs errors.ValidationErrors
-
-	instituteId, err := institution.NewId(req.InstitutionId)
+// GetGroup returns the group with its staff and accessible courses
+func (s *InstitutionManagementService) GetGroup(ctx context.Context, actorId, institutionId, groupId int) (*GroupDetail, error) {
+	instituteId, err := s.requireMember(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	parsedGroupId, err := institution.NewId(groupId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing groupId: %w", err)
+	}
+	group, err := s.instituteRepo.GetGroupById(ctx, instituteId, parsedGroupId)
 	if err != nil {
-		valErrs.Add("instituteId", err.Error())
+		return nil, err
+	}
 
+	detail := &GroupDetail{
+		Group:   mapToServiceGroup(group),
+		Staff:   make([]Staff, len(group.Staff())),
+		Courses: make([]Course, len(group.AccessibleCourses())),
+	}
+	for i, st := range group.Staff() {
+		detail.Staff[i] = mapToServiceStaff(&st)
+	}
+	for i, c := range group.AccessibleCourses() {
+		detail.Courses[i] = mapToServiceCourse(&c)
+	}
+	return detail, nil
+}
+
+// UpdateGroup
+func (s *InstitutionManagementService) UpdateGroup(ctx context.Context, req UpdateGroupRequest) (*Group, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
 	}
 	groupId, err := institution.NewId(req.GroupId)
 	if err != nil {
-		valErrs.Add("groupId", err.Error())
-
+		return nil, fmt.Errorf("error parsing groupId: %w", err)
 	}
-	staffId, err := institution.NewId(req.StaffId)
+	group, err := s.instituteRepo.GetGroupById(ctx, instituteId, groupId)
 	if err != nil {
-		valErrs.Add("staffId", err.Error())
-
+		return nil, err
 	}
+	before := groupAuditFields(group)
 
+	var valErrs errors.ValidationErrors
+	if req.Name != nil {
+		if name, err := institution.NewName(*req.Name); err != nil {
+			valErrs.Add("name", err.Error())
+		} else if err := group.UpdateName(name); err != nil {
+			valErrs.Add("name", err.Error())
+		}
+	}
+	if req.Description != nil {
+		if desc, err := institution.NewDescription(*req.Description); err != nil {
+			valErrs.Add("description", err.Error())
+		} else if err := group.UpdateDescription(desc); err != nil {
+			valErrs.Add("description", err.Error())
+		}
+	}
 	if valErrs.HasErrors() {
-		return &valErrs
+		return nil, &valErrs
+	}
+
+	if err := s.instituteRepo.UpdateGroup(ctx, instituteId, group); err != nil {
+		return nil, fmt.Errorf("failed to update group: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "group.update",
+		TargetType:    auditlog.TargetGroup,
+		TargetId:      req.GroupId,
+		InstitutionId: &req.InstitutionId,
+		Before:        before,
+		After:         groupAuditFields(group),
+	})
+	updated := mapToServiceGroup(group)
+	return &updated, nil
+}
+
+// DeleteGroup, its staff stay in the institution
+func (s *InstitutionManagementService) DeleteGroup(ctx context.Context, actorId, institutionId, groupId int) error {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return err
+	}
+	parsedGroupId, err := institution.NewId(groupId)
+	if err != nil {
+		return fmt.Errorf("error parsing groupId: %w", err)
+	}
+	group, err := s.instituteRepo.GetGroupById(ctx, instituteId, parsedGroupId)
+	if err != nil {
+		return err
+	}
+	if err := s.instituteRepo.DeleteGroup(ctx, instituteId, parsedGroupId); err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "group.delete",
+		TargetType:    auditlog.TargetGroup,
+		TargetId:      groupId,
+		InstitutionId: &institutionId,
+		Before:        groupAuditFields(group),
+	})
+	return nil
+}
 
+// add staff to group
+func (s *InstitutionManagementService) AddStaffToGroup(ctx context.Context, req AddStaffToGroupRequest) error {
+	instituteId, groupId, staffId, err := s.parseGroupStaff(ctx, req)
+	if err != nil {
+		return err
 	}
 
 	err = s.instituteRepo.AddStaffToGroup(ctx, instituteId, groupId, staffId)
 	if err != nil {
 		return fmt.Errorf("failed to add staff to group: %w", err)
 	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "group.staff.add",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      req.StaffId,
+		InstitutionId: &req.InstitutionId,
+		After:         map[string]any{"groupId": req.GroupId},
+	})
 	return nil
 }
 
-// list groups
-func (s *InstitutionManagementService) GetGroups(ctx context.Context, id int) (*GroupResponse, error) {
+// RemoveStaffFromGroup
+func (s *InstitutionManagementService) RemoveStaffFromGroup(ctx context.Context, req AddStaffToGroupRequest) error {
+	instituteId, groupId, staffId, err := s.parseGroupStaff(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if err := s.instituteRepo.RemoveStaffFromGroup(ctx, instituteId, groupId, staffId); err != nil {
+		return fmt.Errorf("failed to remove staff from group: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "group.staff.remove",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      req.StaffId,
+		InstitutionId: &req.InstitutionId,
+		Before:        map[string]any{"groupId": req.GroupId},
+	})
+	return nil
+}
+
+// parseGroupStaff validates the ids of a group membership change made by an admin
+func (s *InstitutionManagementService) parseGroupStaff(ctx context.Context, req AddStaffToGroupRequest) (instituteId, groupId, staffId institution.Id, err error) {
+	var valErrs errors.ValidationErrors
+
+	groupId, err = institution.NewId(req.GroupId)
+	if err != nil {
+		valErrs.Add("groupId", err.Error())
 
-	instituteId, err := institution.NewId(id)
+	}
+	staffId, err = institution.NewId(req.StaffId)
 	if err != nil {
-		return nil, nil
+		valErrs.Add("staffId", err.Error())
+
+	}
+
+	if valErrs.HasErrors() {
+		return 0, 0, 0, &valErrs
+
+	}
+	instituteId, err = s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	return instituteId, groupId, staffId, err
+}
+
+// list groups
+func (s *InstitutionManagementService) GetGroups(ctx context.Context, actorId, id int, pagination Pagination) (*GroupResponse, error) {
+	instituteId, err := s.requireMember(ctx, actorId, id)
+	if err != nil {
+		return nil, err
 	}
-	data, total, err := s.instituteRepo.ListGroups(ctx, instituteId)
+	pagination, page := pagination.normalise()
+	data, total, err := s.instituteRepo.ListGroups(ctx, instituteId, page)
 	if err != nil {
 		return nil, err
 	}
 
 	groups := make([]Group, len(data))
-	for i, d := range data {
-		groups[i].Id = d.Id().Value()
-		groups[i].Name = d.Name().String()
-		groups[i].CreatedAt = d.CreatedAt().String()
-		groups[i].UpdatedAt = d.UpdatedAt().String()
-
+	for i := range data {
+		groups[i] = mapToServiceGroup(&data[i])
 	}
 	return &GroupResponse{
-		Total:  total,
-		Groups: groups,
+		Total:    total,
+		Groups:   groups,
+		Page:     pagination.Page,
+		PageSize: pagination.PageSize,
 	}, nil
 
 }
 
-//TODO: 3 add accessible course to group
-//TODO: 2 list accessible courses in group
+// ResolveTenant loads the user's membership of the institution a request is scoped to
+func (s *InstitutionManagementService) ResolveTenant(ctx context.Context, actorId, institutionId int) (*institution.Tenant, error) {
+	instituteId, staff, err := s.memberStaff(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	return institution.NewTenant(instituteId, staff)
+}
+
+// requireMember returns the parsed institution id if the user has access to the institution
+func (s *InstitutionManagementService) requireMember(ctx context.Context, actorId, institutionId int) (institution.Id, error) {
+	instituteId, _, err := s.memberStaff(ctx, actorId, institutionId)
+	return instituteId, err
+}
+
+// memberStaff returns the parsed institution id and the user's staff record if the user has access to the institution
+func (s *InstitutionManagementService) memberStaff(ctx context.Context, actorId, institutionId int) (institution.Id, *institution.Staff, error) {
+	instituteId, userId, err := parseMembership(actorId, institutionId)
+	if err != nil {
+		return 0, nil, err
+	}
+	staff, err := s.instituteRepo.GetStaffByUserId(ctx, instituteId, userId)
+	if stderrors.Is(err, institute_repo.ErrStaffNotFound) {
+		return 0, nil, ErrNotInstitutionMember
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("error checking institution membership: %w", err)
+	}
+	if !staff.HasAccess() {
+		return 0, nil, ErrNotInstitutionMember
+	}
+	return instituteId, staff, nil
+}
+
+// requireAdmin returns the parsed institution id if the user is an active admin of the institution
+func (s *InstitutionManagementService) requireAdmin(ctx context.Context, actorId, institutionId int) (institution.Id, error) {
+	instituteId, userId, err := parseMembership(actorId, institutionId)
+	if err != nil {
+		return 0, err
+	}
+	isAdmin, err := s.instituteRepo.IsInstitutionAdmin(ctx, instituteId, userId)
+	if err != nil {
+		return 0, fmt.Errorf("error checking institution role: %w", err)
+	}
+	if !isAdmin {
+		return 0, ErrNotInstitutionAdmin
+	}
+	return instituteId, nil
+}
+
+func parseMembership(actorId, institutionId int) (institution.Id, institution.Id, error) {
+	instituteId, err := institution.NewId(institutionId)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error parsing institutionId: %w", err)
+	}
+	userId, err := institution.NewId(actorId)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error parsing actorId: %w", err)
+	}
+	return instituteId, userId, nil
+}
+
+func institutionAuditFields(inst *institution.Institution) map[string]any {
+	return map[string]any{
+		"name":        inst.Name().String(),
+		"email":       inst.Email().String(),
+		"description": inst.Description().String(),
+	}
+}
+
+func groupAuditFields(g *institution.Group) map[string]any {
+	return map[string]any{
+		"name":        g.Name().String(),
+		"description": g.Description().String(),
+	}
+}
 
-//TODO: 1 invite staff
-// func (s *InstitutionManagementService) InviteStaff(ctx context.Context, req CreateInstitutionRequest) error {
-// 	var err errors.ValidationErrors
+func mapToServiceStaff(s *institution.Staff) Staff {
+	staff := Staff{
+		Id:           s.Id().Value(),
+		Name:         s.Name().String(),
+		Email:        s.Email().String(),
+		Status:       s.Status().String(),
+		StatusReason: s.StatusReason(),
+		Role:         s.Role().String(),
+		CreatedAt:    s.CreatedAt().String(),
+		UpdatedAt:    s.UpdatedAt().String(),
+	}
+	if s.UserId() != nil {
+		userId := s.UserId().Value()
+		staff.UserId = &userId
+	}
+	if s.StatusChangedAt() != nil {
+		changedAt := s.StatusChangedAt().String()
+		staff.StatusChangedAt = &changedAt
+	}
+	if s.RestorableUntil() != nil {
+		until := s.RestorableUntil().String()
+		staff.RestorableUntil = &until
+	}
+	return staff
+}
 
-// 	//  will recieve and run the email sending as a background job
+func mapToServiceGroup(g *institution.Group) Group {
+	return Group{
+		Id:          g.Id().Value(),
+		Name:        g.Name().String(),
+		Description: g.Description().String(),
+		CreatedAt:   g.CreatedAt().String(),
+		UpdatedAt:   g.UpdatedAt().String(),
+	}
+}
 
-// }
+func mapToServiceCourse(c *institution.Course) Course {
+	course := Course{
+		Id:          c.Id().Value(),
+		Name:        c.Name().String(),
+		Description: c.Description().String(),
+		CreatedAt:   c.CreatedAt().String(),
+		UpdatedAt:   c.UpdatedAt().String(),
+	}
+	if c.CategoryId() != nil {
+		categoryId := c.CategoryId().Value()
+		course.CategoryId = &categoryId
+	}
+	return course
+}
+
+// GetAuditTrail returns what happened within the institution, only its admins may see it
+func (s *InstitutionManagementService) GetAuditTrail(ctx context.Context, actorId, institutionId int, filter *auditlog.Filter) (*auditlog.GetEntriesResponse, error) {
+	if _, err := s.requireAdmin(ctx, actorId, institutionId); err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		filter = &auditlog.Filter{}
+	}
+	// the scope always comes from the caller's institution, never from the filter
+	filter.InstitutionId = &institutionId
+	return s.audit.GetEntries(ctx, filter)
+}
 
 // create institution
-// invite staff to institution
-// create staff in institution
-// blacklist users in instititution
-// activate users in instituion
-// change user status in institution
-// audit trail of users in institution
 // lecture material creates by users in institution
-// create categories in institution
-// create groups in institution
-// group users in institution (users in group can only create assessments in this category)
 // be able to a list staff profiles within the institution, assign to groups ...
 // view assessments within the institution(be able to filter by categoryId, staffId(userId))
@@ -0,0 +1,383 @@
+package institution
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+)
+
+// maxInvitesPerRequest keeps one request from sending an unbounded number of emails
+const maxInvitesPerRequest = 100
+
+var ErrInvalidInvite = stderrors.New("invite is invalid or has expired")
+
+// AccountProvisioner finds or creates the account an invited staff signs in with.
+// The invite token proves the invitee owns the email, so an unverified account is verified.
+type AccountProvisioner interface {
+	ProvisionInvitedUser(ctx context.Context, name, email, password string) (userId int, created bool, err error)
+}
+
+type (
+	Invite struct {
+		Id         int
+		StaffId    int
+		Name       string
+		Email      string
+		Status     string
+		ExpiresAt  time.Time
+		AcceptedAt *time.Time
+		RevokedAt  *time.Time
+		CreatedAt  time.Time
+	}
+	// InviteResult is the outcome for one person of a bulk invite, Error is set when they were not invited
+	InviteResult struct {
+		Email  string
+		Invite *Invite
+		Error  string
+	}
+	InviteStaffResponse struct {
+		Results []InviteResult
+		Invited int
+	}
+	// AcceptInviteRequest, Name and Password are only needed when the invitee has no account yet
+	AcceptInviteRequest struct {
+		Token, Name, Password string
+	}
+	AcceptInviteResponse struct {
+		InstitutionId  int
+		StaffId        int
+		UserId         int
+		AccountCreated bool
+	}
+)
+
+// InviteStaff invites people by email, adding those who are not staff yet as inactive staff.
+// Each person succeeds or fails on their own, the emails go out in the background.
+func (s *InstitutionManagementService) InviteStaff(ctx context.Context, req InviteStaffRequest) (*InviteStaffResponse, error) {
+	if len(req.Staff) == 0 {
+		return nil, stderrors.New("at least one person must be invited")
+	}
+	if len(req.Staff) > maxInvitesPerRequest {
+		return nil, fmt.Errorf("at most %d people can be invited at once", maxInvitesPerRequest)
+	}
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	inst, err := s.instituteRepo.GetInstitutionById(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &InviteStaffResponse{Results: make([]InviteResult, len(req.Staff))}
+	var notifications []email.Notification
+	for i, person := range req.Staff {
+		response.Results[i].Email = person.Email
+		invite, err := s.inviteOne(ctx, instituteId, req.ActorId, person)
+		if err != nil {
+			response.Results[i].Error = err.Error()
+			continue
+		}
+		mapped := mapToServiceInvite(invite)
+		response.Results[i].Invite = &mapped
+		response.Invited++
+		notifications = append(notifications, inviteNotification(inst, invite, req.AcceptUrl))
+	}
+	s.sendInBackground(ctx, notifications)
+	return response, nil
+}
+
+func (s *InstitutionManagementService) inviteOne(ctx context.Context, instituteId institution.Id, actorId int, person StaffInvite) (*institution.Invite, error) {
+	name, err := institution.NewName(person.Name)
+	if err != nil {
+		return nil, err
+	}
+	email, err := institution.NewEmail(person.Email)
+	if err != nil {
+		return nil, err
+	}
+	staff, err := s.instituteRepo.GetStaffByEmail(ctx, instituteId, email)
+	if stderrors.Is(err, institute_repo.ErrStaffNotFound) {
+		staff, err = s.addInvitedStaff(ctx, instituteId, actorId, name, email, person.Role)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if staff.UserId() != nil {
+		return nil, stderrors.New("already a member of the institution")
+	}
+	return s.createInvite(ctx, instituteId, actorId, staff)
+}
+
+// createInvite sends the staff a new invite, replacing any pending one
+func (s *InstitutionManagementService) createInvite(ctx context.Context, instituteId institution.Id, actorId int, staff *institution.Staff) (*institution.Invite, error) {
+	if staff.Status() == institution.Blacklisted {
+		return nil, ErrStaffBlacklisted
+	}
+	invite, err := institution.NewInvite(instituteId, staff.Id(), staff.Name(), staff.Email(), institution.Id(actorId))
+	if err != nil {
+		return nil, err
+	}
+	if err := invite.SetToken(s.newInviteToken()); err != nil {
+		return nil, err
+	}
+	invite, err = s.instituteRepo.CreateInvite(ctx, invite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+	institutionId := instituteId.Value()
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "staff.invite",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      staff.Id().Value(),
+		InstitutionId: &institutionId,
+		After:         map[string]any{"inviteId": invite.Id().Value()},
+		Redacted:      []string{"email"},
+	})
+	return invite, nil
+}
+
+func (s *InstitutionManagementService) addInvitedStaff(ctx context.Context, instituteId institution.Id, actorId int, name institution.Name, email institution.Email, role string) (*institution.Staff, error) {
+	staffRole := institution.MemberRole
+	if role != "" {
+		var err error
+		if staffRole, err = institution.NewStaffRole(role); err != nil {
+			return nil, err
+		}
+	}
+	staff, err := institution.NewStaff(name, email, institution.InActive)
+	if err != nil {
+		return nil, err
+	}
+	staff.SetRole(staffRole)
+	staff, err = s.instituteRepo.AddStaffToInstitution(ctx, instituteId, *staff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add staff: %w", err)
+	}
+	institutionId := instituteId.Value()
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "staff.add",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      staff.Id().Value(),
+		InstitutionId: &institutionId,
+		After:         map[string]any{"role": staffRole.String()},
+		Redacted:      []string{"name", "email"},
+	})
+	return staff, nil
+}
+
+// ListInvites returns every invite of the institution, newest first
+func (s *InstitutionManagementService) ListInvites(ctx context.Context, actorId, institutionId int) ([]Invite, error) {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.instituteRepo.ListInvites(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	invites := make([]Invite, len(data))
+	for i := range data {
+		invites[i] = mapToServiceInvite(&data[i])
+	}
+	return invites, nil
+}
+
+// ResendInvite sends a pending or expired invite again with a new token, the previous link stops working
+func (s *InstitutionManagementService) ResendInvite(ctx context.Context, actorId, institutionId, inviteId int, acceptUrl string) (*Invite, error) {
+	instituteId, invite, err := s.getInvite(ctx, actorId, institutionId, inviteId)
+	if err != nil {
+		return nil, err
+	}
+	staff, err := s.instituteRepo.GetStaffById(ctx, instituteId, invite.StaffId())
+	if err != nil {
+		return nil, err
+	}
+	if staff.Status() == institution.Blacklisted {
+		return nil, ErrStaffBlacklisted
+	}
+	if err := invite.Renew(s.newInviteToken()); err != nil {
+		return nil, err
+	}
+	inst, err := s.instituteRepo.GetInstitutionById(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.instituteRepo.UpdateInvite(ctx, invite); err != nil {
+		return nil, fmt.Errorf("failed to update invite: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "staff.invite.resend",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      invite.StaffId().Value(),
+		InstitutionId: &institutionId,
+		After:         map[string]any{"inviteId": inviteId},
+	})
+	s.sendInBackground(ctx, []email.Notification{inviteNotification(inst, invite, acceptUrl)})
+	mapped := mapToServiceInvite(invite)
+	return &mapped, nil
+}
+
+// RevokeInvite stops a pending invite from being accepted, the staff stays inactive
+func (s *InstitutionManagementService) RevokeInvite(ctx context.Context, actorId, institutionId, inviteId int) (*Invite, error) {
+	_, invite, err := s.getInvite(ctx, actorId, institutionId, inviteId)
+	if err != nil {
+		return nil, err
+	}
+	if err := invite.Revoke(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.instituteRepo.UpdateInvite(ctx, invite); err != nil {
+		return nil, fmt.Errorf("failed to update invite: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "staff.invite.revoke",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      invite.StaffId().Value(),
+		InstitutionId: &institutionId,
+		Before:        map[string]any{"inviteId": inviteId, "status": institution.InvitePending.String()},
+		After:         map[string]any{"inviteId": inviteId, "status": institution.InviteRevoked.String()},
+	})
+	mapped := mapToServiceInvite(invite)
+	return &mapped, nil
+}
+
+// AcceptInvite links the invitee's account to their staff record and activates it, the account is created if they have none
+func (s *InstitutionManagementService) AcceptInvite(ctx context.Context, req AcceptInviteRequest) (*AcceptInviteResponse, error) {
+	invite, err := s.instituteRepo.GetInviteByToken(ctx, req.Token)
+	if stderrors.Is(err, institute_repo.ErrInviteNotFound) {
+		return nil, ErrInvalidInvite
+	}
+	if err != nil {
+		return nil, err
+	}
+	if invite.StatusAt(time.Now()) != institution.InvitePending {
+		return nil, ErrInvalidInvite
+	}
+	// a staff suspended or blacklisted since the invite was sent cannot use it
+	staff, err := s.instituteRepo.GetStaffById(ctx, invite.InstitutionId(), invite.StaffId())
+	if stderrors.Is(err, institute_repo.ErrStaffNotFound) {
+		return nil, ErrInvalidInvite
+	}
+	if err != nil {
+		return nil, err
+	}
+	if staff.Status() != institution.InActive {
+		return nil, ErrInvalidInvite
+	}
+
+	name := req.Name
+	if name == "" {
+		name = invite.Name().String()
+	}
+	userId, created, err := s.accounts.ProvisionInvitedUser(ctx, name, invite.Email().String(), req.Password)
+	if err != nil {
+		return nil, err
+	}
+	parsedUserId, err := institution.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	if err := invite.Accept(time.Now()); err != nil {
+		return nil, err
+	}
+	err = s.instituteRepo.AcceptInvite(ctx, invite, parsedUserId)
+	if stderrors.Is(err, institute_repo.ErrInviteNotFound) || stderrors.Is(err, institute_repo.ErrStaffNotFound) {
+		// accepted by a request with the same token, or the staff removed or suspended, in the meantime
+		return nil, ErrInvalidInvite
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invite: %w", err)
+	}
+
+	institutionId := invite.InstitutionId().Value()
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       userId,
+		Action:        "staff.invite.accept",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      invite.StaffId().Value(),
+		InstitutionId: &institutionId,
+		Before:        map[string]any{"status": institution.InActive.String()},
+		After:         map[string]any{"status": institution.Active.String(), "userId": userId},
+	})
+	return &AcceptInviteResponse{
+		InstitutionId:  institutionId,
+		StaffId:        invite.StaffId().Value(),
+		UserId:         userId,
+		AccountCreated: created,
+	}, nil
+}
+
+func (s *InstitutionManagementService) getInvite(ctx context.Context, actorId, institutionId, inviteId int) (institution.Id, *institution.Invite, error) {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return 0, nil, err
+	}
+	parsedInviteId, err := institution.NewId(inviteId)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error parsing inviteId: %w", err)
+	}
+	invite, err := s.instituteRepo.GetInviteById(ctx, instituteId, parsedInviteId)
+	if err != nil {
+		return 0, nil, err
+	}
+	return instituteId, invite, nil
+}
+
+func (s *InstitutionManagementService) newInviteToken() string {
+	return s.randomIdGenerator.Create("invite_", 32)
+}
+
+// sendInBackground sends the emails without holding up the request, failures are only logged
+func (s *InstitutionManagementService) sendInBackground(ctx context.Context, notifications []email.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+	go func() {
+		if err := s.emailClient.SendMultiple(context.Background(), notifications); err != nil {
+			s.logger.WithContext(ctx).Error("error sending invites", err)
+		}
+	}()
+}
+
+func inviteNotification(inst *institution.Institution, invite *institution.Invite, acceptUrl string) email.Notification {
+	link := acceptUrl + "?" + url.Values{"token": {invite.Token()}}.Encode()
+	return email.Notification{
+		Email: invite.Email().String(),
+		Title: fmt.Sprintf("You have been invited to join %s", inst.Name().String()),
+		Content: fmt.Sprintf("Hi %s, you have been invited to join %s on Assessmate. Use this link to accept, it expires on %s: %s",
+			invite.Name().String(), inst.Name().String(), invite.ExpiresAt().Format(time.RFC1123), link),
+	}
+}
+
+func mapToServiceInvite(i *institution.Invite) Invite {
+	invite := Invite{
+		Id:        i.Id().Value(),
+		StaffId:   i.StaffId().Value(),
+		Name:      i.Name().String(),
+		Email:     i.Email().String(),
+		Status:    i.StatusAt(time.Now()).String(),
+		ExpiresAt: i.ExpiresAt(),
+		CreatedAt: i.CreatedAt(),
+	}
+	if i.AcceptedAt() != nil {
+		acceptedAt := *i.AcceptedAt()
+		invite.AcceptedAt = &acceptedAt
+	}
+	if i.RevokedAt() != nil {
+		revokedAt := *i.RevokedAt()
+		invite.RevokedAt = &revokedAt
+	}
+	return invite
+}
@@ -0,0 +1,303 @@
+package institution
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+	errors "github.com/kaasikodes/assessmate_backend/internal/shared"
+)
+
+var (
+	ErrNotInstitutionOwner = stderrors.New("institution owner role required")
+	ErrLastOwner           = stderrors.New("the institution must keep its owner, transfer ownership first")
+	ErrCannotChangeOwnRole = stderrors.New("admins cannot change their own role")
+	ErrInvalidTransfer     = stderrors.New("ownership transfer is invalid or has expired")
+)
+
+type (
+	// ChangeStaffRoleRequest makes a staff an admin or a member, the owner role only moves by transferring ownership
+	ChangeStaffRoleRequest struct {
+		ActorId, InstitutionId, StaffId int
+		Role                            string
+	}
+	StartOwnershipTransferRequest struct {
+		ActorId, InstitutionId int
+		StaffId                int    // the staff who becomes the owner
+		ConfirmUrl             string // the link in the emails, each party's token is added to it
+	}
+	OwnershipTransfer struct {
+		Id            int
+		InstitutionId int
+		FromStaffId   int
+		ToStaffId     int
+		Status        string
+		FromConfirmed bool
+		ToConfirmed   bool
+		ExpiresAt     time.Time
+		CompletedAt   *time.Time
+		CreatedAt     time.Time
+	}
+)
+
+// ChangeStaffRole lets admins share the running of the institution, the owner keeps their role until they transfer ownership
+func (s *InstitutionManagementService) ChangeStaffRole(ctx context.Context, req ChangeStaffRoleRequest) (*Staff, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	var valErrs errors.ValidationErrors
+	staffId, err := institution.NewId(req.StaffId)
+	if err != nil {
+		valErrs.Add("staffId", err.Error())
+	}
+	role, err := institution.NewStaffRole(req.Role)
+	if err != nil {
+		valErrs.Add("role", err.Error())
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
+	}
+
+	staff, err := s.instituteRepo.GetStaffById(ctx, instituteId, staffId)
+	if err != nil {
+		return nil, err
+	}
+	if staff.UserId() != nil && staff.UserId().Value() == req.ActorId {
+		return nil, ErrCannotChangeOwnRole
+	}
+	if staff.IsOwner() {
+		return nil, ErrLastOwner
+	}
+	before := staff.Role()
+	if before == role {
+		mapped := mapToServiceStaff(staff)
+		return &mapped, nil
+	}
+	staff.SetRole(role)
+	if err := s.instituteRepo.UpdateStaff(ctx, instituteId, staff); err != nil {
+		return nil, fmt.Errorf("failed to change staff role: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "staff.role_change",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      req.StaffId,
+		InstitutionId: &req.InstitutionId,
+		Before:        map[string]any{"role": before.String()},
+		After:         map[string]any{"role": role.String()},
+	})
+	mapped := mapToServiceStaff(staff)
+	return &mapped, nil
+}
+
+// StartOwnershipTransfer asks the owner and the staff taking over to each confirm by email.
+// Starting another transfer cancels the pending one.
+func (s *InstitutionManagementService) StartOwnershipTransfer(ctx context.Context, req StartOwnershipTransferRequest) (*OwnershipTransfer, error) {
+	instituteId, owner, err := s.requireOwner(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	staffId, err := institution.NewId(req.StaffId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing staffId: %w", err)
+	}
+	newOwner, err := s.instituteRepo.GetStaffById(ctx, instituteId, staffId)
+	if err != nil {
+		return nil, err
+	}
+	inst, err := s.instituteRepo.GetInstitutionById(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := institution.NewOwnershipTransfer(instituteId, owner, newOwner)
+	if err != nil {
+		return nil, err
+	}
+	if err := transfer.SetTokens(s.newTransferToken(), s.newTransferToken()); err != nil {
+		return nil, err
+	}
+	transfer, err = s.instituteRepo.CreateOwnershipTransfer(ctx, transfer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start ownership transfer: %w", err)
+	}
+	s.sendInBackground(ctx, []email.Notification{
+		transferNotification(inst, transfer, owner, transfer.FromToken(), req.ConfirmUrl,
+			fmt.Sprintf("you asked to make %s the owner of %s. Once you both confirm you become an admin and %s takes over billing.", newOwner.Name().String(), inst.Name().String(), newOwner.Name().String())),
+		transferNotification(inst, transfer, newOwner, transfer.ToToken(), req.ConfirmUrl,
+			fmt.Sprintf("%s wants to make you the owner of %s. Once you both confirm you become the owner and take over billing.", owner.Name().String(), inst.Name().String())),
+	})
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "institution.ownership.transfer_start",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      req.InstitutionId,
+		InstitutionId: &req.InstitutionId,
+		After:         map[string]any{"fromStaffId": owner.Id().Value(), "toStaffId": req.StaffId},
+	})
+	mapped := mapToServiceTransfer(transfer)
+	return &mapped, nil
+}
+
+// GetOwnershipTransfer returns the pending transfer of the institution to its admins
+func (s *InstitutionManagementService) GetOwnershipTransfer(ctx context.Context, actorId, institutionId int) (*OwnershipTransfer, error) {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := s.instituteRepo.GetPendingOwnershipTransfer(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	mapped := mapToServiceTransfer(transfer)
+	return &mapped, nil
+}
+
+// CancelOwnershipTransfer stops the pending transfer, either party can cancel it
+func (s *InstitutionManagementService) CancelOwnershipTransfer(ctx context.Context, actorId, institutionId int) (*OwnershipTransfer, error) {
+	instituteId, actor, err := s.memberStaff(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := s.instituteRepo.GetPendingOwnershipTransfer(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Id() != transfer.FromStaffId() && actor.Id() != transfer.ToStaffId() {
+		return nil, ErrNotInstitutionOwner
+	}
+	if err := transfer.Cancel(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.instituteRepo.UpdateOwnershipTransfer(ctx, transfer); err != nil {
+		return nil, fmt.Errorf("failed to cancel ownership transfer: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "institution.ownership.transfer_cancel",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      institutionId,
+		InstitutionId: &institutionId,
+		Before:        map[string]any{"fromStaffId": transfer.FromStaffId().Value(), "toStaffId": transfer.ToStaffId().Value()},
+	})
+	mapped := mapToServiceTransfer(transfer)
+	return &mapped, nil
+}
+
+// ConfirmOwnershipTransfer records the confirmation of the party the emailed token was sent to.
+// The second confirmation completes the transfer, the owner becomes an admin and the new owner takes over billing.
+func (s *InstitutionManagementService) ConfirmOwnershipTransfer(ctx context.Context, token string) (*OwnershipTransfer, error) {
+	transfer, partyId, err := s.instituteRepo.GetOwnershipTransferByToken(ctx, token)
+	if stderrors.Is(err, institute_repo.ErrTransferNotFound) {
+		return nil, ErrInvalidTransfer
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if transfer.StatusAt(now) != institution.TransferPending {
+		return nil, ErrInvalidTransfer
+	}
+	// a party who lost access since the transfer started cannot confirm it
+	party, err := s.instituteRepo.GetStaffById(ctx, transfer.InstitutionId(), partyId)
+	if stderrors.Is(err, institute_repo.ErrStaffNotFound) {
+		return nil, ErrInvalidTransfer
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !party.HasAccess() || party.UserId() == nil {
+		return nil, ErrInvalidTransfer
+	}
+	if err := transfer.Confirm(partyId, now); err != nil {
+		return nil, err
+	}
+
+	institutionId := transfer.InstitutionId().Value()
+	event := auditlog.Event{
+		ActorId:       party.UserId().Value(),
+		Action:        "institution.ownership.transfer_confirm",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      institutionId,
+		InstitutionId: &institutionId,
+		After:         map[string]any{"staffId": partyId.Value()},
+	}
+	if transfer.IsConfirmed() {
+		if err := transfer.Complete(now); err != nil {
+			return nil, err
+		}
+		err = s.instituteRepo.CompleteOwnershipTransfer(ctx, transfer)
+		event.Action = "institution.ownership.transfer"
+		event.Before = map[string]any{"ownerStaffId": transfer.FromStaffId().Value()}
+		event.After = map[string]any{"ownerStaffId": transfer.ToStaffId().Value()}
+	} else {
+		err = s.instituteRepo.UpdateOwnershipTransfer(ctx, transfer)
+	}
+	if stderrors.Is(err, institute_repo.ErrTransferNotFound) || stderrors.Is(err, institute_repo.ErrStaffNotFound) {
+		// cancelled or completed by another request, or a party removed or suspended, in the meantime
+		return nil, ErrInvalidTransfer
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm ownership transfer: %w", err)
+	}
+	s.audit.Record(ctx, event)
+	mapped := mapToServiceTransfer(transfer)
+	return &mapped, nil
+}
+
+// requireOwner returns the parsed institution id and the owner's staff record if the user is the active owner of the institution
+func (s *InstitutionManagementService) requireOwner(ctx context.Context, actorId, institutionId int) (institution.Id, *institution.Staff, error) {
+	instituteId, staff, err := s.memberStaff(ctx, actorId, institutionId)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !staff.IsOwner() {
+		return 0, nil, ErrNotInstitutionOwner
+	}
+	return instituteId, staff, nil
+}
+
+// institutionOwner is the staff responsible for the institution and its billing, nil if it has none
+func (s *InstitutionManagementService) institutionOwner(ctx context.Context, instituteId institution.Id) (*institution.Staff, error) {
+	role := institution.OwnerRole
+	owners, _, err := s.instituteRepo.ListStaff(ctx, instituteId, institution.StaffFilter{Role: &role})
+	if err != nil || len(owners) == 0 {
+		return nil, err
+	}
+	return &owners[0], nil
+}
+
+func (s *InstitutionManagementService) newTransferToken() string {
+	return s.randomIdGenerator.Create("transfer_", 32)
+}
+
+func transferNotification(inst *institution.Institution, transfer *institution.OwnershipTransfer, to *institution.Staff, token, confirmUrl, message string) email.Notification {
+	link := confirmUrl + "?" + url.Values{"token": {token}}.Encode()
+	return email.Notification{
+		Email: to.Email().String(),
+		Title: fmt.Sprintf("Confirm the ownership transfer of %s", inst.Name().String()),
+		Content: fmt.Sprintf("Hi %s, %s Use this link to confirm, it expires on %s: %s",
+			to.Name().String(), message, transfer.ExpiresAt().Format(time.RFC1123), link),
+	}
+}
+
+func mapToServiceTransfer(o *institution.OwnershipTransfer) OwnershipTransfer {
+	return OwnershipTransfer{
+		Id:            o.Id().Value(),
+		InstitutionId: o.InstitutionId().Value(),
+		FromStaffId:   o.FromStaffId().Value(),
+		ToStaffId:     o.ToStaffId().Value(),
+		Status:        o.StatusAt(time.Now()).String(),
+		FromConfirmed: o.FromConfirmedAt() != nil,
+		ToConfirmed:   o.ToConfirmedAt() != nil,
+		ExpiresAt:     o.ExpiresAt(),
+		CompletedAt:   o.CompletedAt(),
+		CreatedAt:     o.CreatedAt(),
+	}
+}
@@ -0,0 +1,503 @@
+package institution
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/document"
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+	errors "github.com/kaasikodes/assessmate_backend/internal/shared"
+)
+
+const (
+	maxRosterRows = 5000
+	// rosterInlineRows is the most rows imported within the request, larger rosters are imported in the background
+	rosterInlineRows = 200
+	// rosterProgressEvery is how many rows a background import applies between saving its progress
+	rosterProgressEvery = 100
+)
+
+// rosterHeaders maps the accepted column headings, in lower case, to the column they fill
+var rosterHeaders = map[string]string{
+	"name":          "name",
+	"full name":     "name",
+	"email":         "email",
+	"email address": "email",
+	"role":          "role",
+	"group":         "group",
+}
+
+type (
+	// ImportRosterRequest, the file has a heading row with name and email columns, role and group are optional
+	ImportRosterRequest struct {
+		ActorId, InstitutionId int
+		FileName               string // the extension tells the format, .csv or .xlsx
+		Content                io.ReaderAt
+		Size                   int64
+		DryRun                 bool   // report what the import would do without changing anything
+		AcceptUrl              string // the link in the invite emails, the token is added to it
+	}
+	RosterRowResult struct {
+		Line    int
+		Email   string
+		Action  string
+		Invited bool
+		Error   string
+	}
+	RosterImport struct {
+		Id         int // zero for a dry run, which is not saved
+		FileName   string
+		DryRun     bool
+		Status     string
+		TotalRows  int
+		Processed  int
+		Created    int
+		Updated    int
+		Unchanged  int
+		Failed     int
+		Invited    int
+		Failure    string
+		Rows       []RosterRowResult
+		CreatedAt  time.Time
+		FinishedAt *time.Time
+	}
+
+	rosterRow struct {
+		line                     int
+		name, email, role, group string
+	}
+	// roster is the staff and groups of the institution as the import goes
+	roster struct {
+		institution *institution.Institution
+		staff       map[string]*institution.Staff // by lower cased email
+		groups      map[string]institution.Id     // by lower cased name
+		groupStaff  map[institution.Id]map[institution.Id]bool
+		seen        map[string]int // the first line each email was on
+	}
+)
+
+// ImportRoster adds the people in the file as staff and invites them, people who are already staff are updated.
+// Rows succeed or fail on their own. Large files are imported in the background, GetRosterImport reports the progress.
+func (s *InstitutionManagementService) ImportRoster(ctx context.Context, req ImportRosterRequest) (*RosterImport, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	format, err := document.SpreadsheetFormatOf(req.FileName)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.spreadsheets.ReadRows(ctx, req.Content, req.Size, format)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := parseRoster(records)
+	if err != nil {
+		return nil, err
+	}
+	ri, err := institution.NewRosterImport(instituteId, institution.Id(req.ActorId), req.FileName, len(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DryRun {
+		r, err := s.loadRoster(ctx, instituteId)
+		if err != nil {
+			return nil, err
+		}
+		if err := ri.Start(time.Now()); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			result, _ := s.applyRosterRow(ctx, r, req.ActorId, row, true)
+			ri.Record(result)
+		}
+		ri.Complete(time.Now())
+		preview := mapToServiceRosterImport(ri)
+		preview.DryRun = true
+		return &preview, nil
+	}
+
+	ri, err = s.instituteRepo.CreateRosterImport(ctx, ri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create roster import: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        "staff.import",
+		TargetType:    auditlog.TargetInstitution,
+		TargetId:      req.InstitutionId,
+		InstitutionId: &req.InstitutionId,
+		After:         map[string]any{"importId": ri.Id().Value(), "fileName": req.FileName, "rows": len(rows)},
+	})
+	if len(rows) <= rosterInlineRows {
+		s.runRosterImport(ctx, ri, rows, req.ActorId, req.AcceptUrl)
+		imported := mapToServiceRosterImport(ri)
+		return &imported, nil
+	}
+	// mapped before the import starts changing it
+	queued := mapToServiceRosterImport(ri)
+	go s.runRosterImport(context.WithoutCancel(ctx), ri, rows, req.ActorId, req.AcceptUrl)
+	return &queued, nil
+}
+
+// GetRosterImport returns an import with the result of every row applied so far
+func (s *InstitutionManagementService) GetRosterImport(ctx context.Context, actorId, institutionId, importId int) (*RosterImport, error) {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	parsedImportId, err := institution.NewId(importId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing importId: %w", err)
+	}
+	ri, err := s.instituteRepo.GetRosterImportById(ctx, instituteId, parsedImportId)
+	if err != nil {
+		return nil, err
+	}
+	mapped := mapToServiceRosterImport(ri)
+	return &mapped, nil
+}
+
+// runRosterImport applies the rows, saving the progress as it goes, and sends the invites once done
+func (s *InstitutionManagementService) runRosterImport(ctx context.Context, ri *institution.RosterImport, rows []rosterRow, actorId int, acceptUrl string) {
+	if err := ri.Start(time.Now()); err != nil {
+		s.logger.WithContext(ctx).Error("error starting roster import", err)
+		return
+	}
+	s.saveRosterImport(ctx, ri)
+	r, err := s.loadRoster(ctx, ri.InstitutionId())
+	if err != nil {
+		s.logger.WithContext(ctx).Error("error loading roster", err)
+		ri.Fail("the staff of the institution could not be loaded", time.Now())
+		s.saveRosterImport(ctx, ri)
+		return
+	}
+
+	var notifications []email.Notification
+	for i, row := range rows {
+		result, invite := s.applyRosterRow(ctx, r, actorId, row, false)
+		ri.Record(result)
+		if invite != nil {
+			notifications = append(notifications, inviteNotification(r.institution, invite, acceptUrl))
+		}
+		if (i+1)%rosterProgressEvery == 0 && i+1 < len(rows) {
+			s.saveRosterImport(ctx, ri)
+		}
+	}
+	ri.Complete(time.Now())
+	s.saveRosterImport(ctx, ri)
+	s.sendInBackground(ctx, notifications)
+}
+
+func (s *InstitutionManagementService) saveRosterImport(ctx context.Context, ri *institution.RosterImport) {
+	if err := s.instituteRepo.UpdateRosterImport(ctx, ri); err != nil {
+		s.logger.WithContext(ctx).Error("error saving roster import progress", err)
+	}
+}
+
+func (s *InstitutionManagementService) loadRoster(ctx context.Context, instituteId institution.Id) (*roster, error) {
+	inst, err := s.instituteRepo.GetInstitutionById(ctx, instituteId)
+	if err != nil {
+		return nil, err
+	}
+	staff, _, err := s.instituteRepo.ListStaff(ctx, instituteId, institution.StaffFilter{})
+	if err != nil {
+		return nil, err
+	}
+	groups, _, err := s.instituteRepo.ListGroups(ctx, instituteId, institution.Page{})
+	if err != nil {
+		return nil, err
+	}
+	r := &roster{
+		institution: inst,
+		staff:       make(map[string]*institution.Staff, len(staff)),
+		groups:      make(map[string]institution.Id, len(groups)),
+		groupStaff:  make(map[institution.Id]map[institution.Id]bool),
+		seen:        make(map[string]int),
+	}
+	for i := range staff {
+		r.staff[strings.ToLower(staff[i].Email().String())] = &staff[i]
+	}
+	for _, g := range groups {
+		r.groups[strings.ToLower(g.Name().String())] = g.Id()
+	}
+	return r, nil
+}
+
+// inGroup reports whether the staff is in the group, the members of a group are loaded the first time it is asked about
+func (s *InstitutionManagementService) inGroup(ctx context.Context, r *roster, groupId, staffId institution.Id) (bool, error) {
+	members, ok := r.groupStaff[groupId]
+	if !ok {
+		group, err := s.instituteRepo.GetGroupById(ctx, r.institution.Id(), groupId)
+		if err != nil {
+			return false, err
+		}
+		members = make(map[institution.Id]bool, len(group.Staff()))
+		for _, member := range group.Staff() {
+			members[member.Id()] = true
+		}
+		r.groupStaff[groupId] = members
+	}
+	return members[staffId], nil
+}
+
+// applyRosterRow adds or updates the staff of the row, in a dry run it only reports what it would do.
+// The invite is returned for a new staff so it can be sent with the others.
+func (s *InstitutionManagementService) applyRosterRow(ctx context.Context, r *roster, actorId int, row rosterRow, dryRun bool) (institution.RosterRowResult, *institution.Invite) {
+	result := institution.RosterRowResult{Line: row.line, Email: row.email}
+	fail := func(msg string) (institution.RosterRowResult, *institution.Invite) {
+		result.Action = institution.RowFailed
+		result.Error = msg
+		return result, nil
+	}
+
+	var valErrs errors.ValidationErrors
+	name, err := institution.NewName(row.name)
+	if err != nil {
+		valErrs.Add("name", err.Error())
+	}
+	email, err := institution.NewEmail(row.email)
+	if err != nil {
+		valErrs.Add("email", err.Error())
+	}
+	var role institution.StaffRole
+	if row.role != "" {
+		if role, err = institution.NewStaffRole(strings.ToLower(row.role)); err != nil {
+			valErrs.Add("role", err.Error())
+		}
+	}
+	var groupId *institution.Id
+	if row.group != "" {
+		if id, ok := r.groups[strings.ToLower(row.group)]; ok {
+			groupId = &id
+		} else {
+			valErrs.Add("group", fmt.Sprintf("the institution has no group named %q", row.group))
+		}
+	}
+	if valErrs.HasErrors() {
+		return fail(valErrs.Error())
+	}
+	key := strings.ToLower(email.String())
+	if line, ok := r.seen[key]; ok {
+		return fail(fmt.Sprintf("the email is already on line %d", line))
+	}
+	r.seen[key] = row.line
+
+	instituteId := r.institution.Id()
+	institutionId := instituteId.Value()
+	staff, exists := r.staff[key]
+	if !exists {
+		result.Action = institution.RowCreated
+		result.Invited = true
+		if dryRun {
+			return result, nil
+		}
+		if role == "" {
+			role = institution.MemberRole
+		}
+		staff, err := s.addInvitedStaff(ctx, instituteId, actorId, name, email, role.String())
+		if err != nil {
+			return fail(s.rosterRowError(ctx, err))
+		}
+		r.staff[key] = staff
+		if groupId != nil {
+			if err := s.addRosterStaffToGroup(ctx, r, actorId, *groupId, staff.Id()); err != nil {
+				result.Error = "added, but not to the group: " + s.rosterRowError(ctx, err)
+			}
+		}
+		invite, err := s.createInvite(ctx, instituteId, actorId, staff)
+		if err != nil {
+			s.logger.WithContext(ctx).Error("error creating roster invite", err)
+			result.Invited = false
+			if result.Error == "" {
+				result.Error = "added, but the invite could not be created"
+			}
+			return result, nil
+		}
+		return result, invite
+	}
+
+	before, after := map[string]any{}, map[string]any{}
+	if role != "" && role != staff.Role() {
+		if staff.UserId() != nil && staff.UserId().Value() == actorId {
+			return fail("you cannot change your own role")
+		}
+		if staff.IsOwner() {
+			return fail(ErrLastOwner.Error())
+		}
+		before["role"], after["role"] = staff.Role().String(), role.String()
+	}
+	var redacted []string
+	if name != staff.Name() {
+		redacted = append(redacted, "name")
+	}
+	addToGroup := false
+	if groupId != nil {
+		member, err := s.inGroup(ctx, r, *groupId, staff.Id())
+		if err != nil {
+			return fail(s.rosterRowError(ctx, err))
+		}
+		addToGroup = !member
+	}
+	if len(after) == 0 && len(redacted) == 0 && !addToGroup {
+		result.Action = institution.RowUnchanged
+		return result, nil
+	}
+	result.Action = institution.RowUpdated
+	if dryRun {
+		return result, nil
+	}
+
+	if len(after) > 0 || len(redacted) > 0 {
+		if err := staff.Rename(name); err != nil {
+			return fail(err.Error())
+		}
+		if role != "" {
+			staff.SetRole(role)
+		}
+		if err := s.instituteRepo.UpdateStaff(ctx, instituteId, staff); err != nil {
+			return fail(s.rosterRowError(ctx, err))
+		}
+		s.audit.Record(ctx, auditlog.Event{
+			ActorId:       actorId,
+			Action:        "staff.update",
+			TargetType:    auditlog.TargetStaff,
+			TargetId:      staff.Id().Value(),
+			InstitutionId: &institutionId,
+			Before:        before,
+			After:         after,
+			Redacted:      redacted,
+		})
+	}
+	if addToGroup {
+		if err := s.addRosterStaffToGroup(ctx, r, actorId, *groupId, staff.Id()); err != nil {
+			return fail(s.rosterRowError(ctx, err))
+		}
+	}
+	return result, nil
+}
+
+func (s *InstitutionManagementService) addRosterStaffToGroup(ctx context.Context, r *roster, actorId int, groupId, staffId institution.Id) error {
+	if err := s.instituteRepo.AddStaffToGroup(ctx, r.institution.Id(), groupId, staffId); err != nil {
+		return err
+	}
+	if members, ok := r.groupStaff[groupId]; ok {
+		members[staffId] = true
+	}
+	institutionId := r.institution.Id().Value()
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "group.staff.add",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      staffId.Value(),
+		InstitutionId: &institutionId,
+		After:         map[string]any{"groupId": groupId.Value()},
+	})
+	return nil
+}
+
+// rosterRowError is the message reported for a row that could not be saved, unexpected errors are logged rather than shown
+func (s *InstitutionManagementService) rosterRowError(ctx context.Context, err error) string {
+	switch {
+	case stderrors.Is(err, institute_repo.ErrStaffExists):
+		return "a removed staff has this email"
+	case stderrors.Is(err, institute_repo.ErrStaffNotFound), stderrors.Is(err, institute_repo.ErrGroupNotFound):
+		return err.Error()
+	}
+	s.logger.WithContext(ctx).Error("error importing roster row", err)
+	return "the row could not be saved"
+}
+
+// parseRoster finds the columns from the heading row and reads the rows below it, blank rows are skipped
+func parseRoster(records [][]string) ([]rosterRow, error) {
+	if len(records) == 0 {
+		return nil, stderrors.New("the file is empty")
+	}
+	columns := map[string]int{}
+	for i, heading := range records[0] {
+		if column, ok := rosterHeaders[strings.ToLower(strings.TrimSpace(heading))]; ok {
+			if _, dup := columns[column]; !dup {
+				columns[column] = i
+			}
+		}
+	}
+	var valErrs errors.ValidationErrors
+	for _, required := range []string{"name", "email"} {
+		if _, ok := columns[required]; !ok {
+			valErrs.Add("file", "the heading row has no "+required+" column")
+		}
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
+	}
+
+	cell := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	rows := []rosterRow{}
+	for i, record := range records[1:] {
+		row := rosterRow{
+			line:  i + 2,
+			name:  cell(record, "name"),
+			email: cell(record, "email"),
+			role:  cell(record, "role"),
+			group: cell(record, "group"),
+		}
+		if row.name == "" && row.email == "" && row.role == "" && row.group == "" {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, stderrors.New("the file has no rows below the heading row")
+	}
+	if len(rows) > maxRosterRows {
+		return nil, fmt.Errorf("at most %d rows can be imported at once", maxRosterRows)
+	}
+	return rows, nil
+}
+
+func mapToServiceRosterImport(ri *institution.RosterImport) RosterImport {
+	rows := make([]RosterRowResult, len(ri.Results()))
+	for i, result := range ri.Results() {
+		rows[i] = RosterRowResult{
+			Line:    result.Line,
+			Email:   result.Email,
+			Action:  result.Action.String(),
+			Invited: result.Invited,
+			Error:   result.Error,
+		}
+	}
+	mapped := RosterImport{
+		Id:        ri.Id().Value(),
+		FileName:  ri.FileName(),
+		Status:    ri.Status().String(),
+		TotalRows: ri.TotalRows(),
+		Processed: len(rows),
+		Created:   ri.Count(institution.RowCreated),
+		Updated:   ri.Count(institution.RowUpdated),
+		Unchanged: ri.Count(institution.RowUnchanged),
+		Failed:    ri.Count(institution.RowFailed),
+		Invited:   ri.Invited(),
+		Failure:   ri.Failure(),
+		Rows:      rows,
+		CreatedAt: ri.CreatedAt(),
+	}
+	if ri.FinishedAt() != nil {
+		finishedAt := *ri.FinishedAt()
+		mapped.FinishedAt = &finishedAt
+	}
+	return mapped
+}
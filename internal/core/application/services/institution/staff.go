@@ -0,0 +1,151 @@
+package institution
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	errors "github.com/kaasikodes/assessmate_backend/internal/shared"
+)
+
+// RemovedStaffStatus lists the soft deleted staff when used as the GetStaff status filter
+const RemovedStaffStatus = "removed"
+
+var (
+	ErrCannotChangeOwnStatus = stderrors.New("admins cannot change their own status")
+	ErrStaffBlacklisted      = stderrors.New("the staff is blacklisted from the institution")
+)
+
+// ChangeStaffStatusRequest suspends, blacklists or reactivates a staff, or approves one who joined by email domain.
+// A reason is required unless reactivating or approving.
+type ChangeStaffStatusRequest struct {
+	ActorId, InstitutionId, StaffId int
+	Status                          string
+	Reason                          string
+}
+
+// ChangeStaffStatus moves the staff to another status, a suspended or blacklisted staff loses access on their next request
+func (s *InstitutionManagementService) ChangeStaffStatus(ctx context.Context, req ChangeStaffStatusRequest) (*Staff, error) {
+	instituteId, err := s.requireAdmin(ctx, req.ActorId, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+	var valErrs errors.ValidationErrors
+	staffId, err := institution.NewId(req.StaffId)
+	if err != nil {
+		valErrs.Add("staffId", err.Error())
+	}
+	status, err := institution.NewStaffStatus(req.Status)
+	if err != nil {
+		valErrs.Add("status", err.Error())
+	}
+	if valErrs.HasErrors() {
+		return nil, &valErrs
+	}
+
+	staff, err := s.instituteRepo.GetStaffById(ctx, instituteId, staffId)
+	if err != nil {
+		return nil, err
+	}
+	if staff.UserId() != nil && staff.UserId().Value() == req.ActorId {
+		return nil, ErrCannotChangeOwnStatus
+	}
+	if staff.IsOwner() && status != institution.Active {
+		return nil, ErrLastOwner
+	}
+	before := staffStatusAuditFields(staff)
+	approving := staff.Status() == institution.InActive
+	if err := staff.ChangeStatus(status, req.Reason, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.instituteRepo.UpdateStaff(ctx, instituteId, staff); err != nil {
+		return nil, fmt.Errorf("failed to change staff status: %w", err)
+	}
+	action := "staff.reactivate"
+	switch staff.Status() {
+	case institution.Active:
+		if approving {
+			action = "staff.approve"
+		}
+	case institution.Suspended:
+		action = "staff.suspend"
+	case institution.Blacklisted:
+		action = "staff.blacklist"
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       req.ActorId,
+		Action:        action,
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      req.StaffId,
+		InstitutionId: &req.InstitutionId,
+		Before:        before,
+		After:         staffStatusAuditFields(staff),
+	})
+	mapped := mapToServiceStaff(staff)
+	return &mapped, nil
+}
+
+// RestoreStaff brings back a removed staff within institution.StaffRetention of the removal, with the status they had
+func (s *InstitutionManagementService) RestoreStaff(ctx context.Context, actorId, institutionId, staffId int) (*Staff, error) {
+	instituteId, err := s.requireAdmin(ctx, actorId, institutionId)
+	if err != nil {
+		return nil, err
+	}
+	parsedStaffId, err := institution.NewId(staffId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing staffId: %w", err)
+	}
+	staff, err := s.instituteRepo.GetRemovedStaffById(ctx, instituteId, parsedStaffId)
+	if err != nil {
+		return nil, err
+	}
+	removedAt := staff.DeletedAt().String()
+	if err := staff.Restore(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.instituteRepo.RestoreStaff(ctx, instituteId, staff); err != nil {
+		return nil, fmt.Errorf("failed to restore staff: %w", err)
+	}
+	s.audit.Record(ctx, auditlog.Event{
+		ActorId:       actorId,
+		Action:        "staff.restore",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      staffId,
+		InstitutionId: &institutionId,
+		Before:        map[string]any{"removedAt": removedAt},
+		After:         map[string]any{"status": staff.Status().String()},
+		Redacted:      []string{"name", "email"},
+	})
+	mapped := mapToServiceStaff(staff)
+	return &mapped, nil
+}
+
+// parseStaffFilter reads the GetStaff status filter, an empty status lists every staff that is not removed
+func parseStaffFilter(status string) (institution.StaffFilter, error) {
+	var filter institution.StaffFilter
+	switch status {
+	case "":
+	case RemovedStaffStatus:
+		filter.Removed = true
+	default:
+		parsed, err := institution.NewStaffStatus(status)
+		if err != nil {
+			var valErrs errors.ValidationErrors
+			valErrs.Add("status", err.Error())
+			return filter, &valErrs
+		}
+		filter.Status = &parsed
+	}
+	return filter, nil
+}
+
+func staffStatusAuditFields(s *institution.Staff) map[string]any {
+	fields := map[string]any{"status": s.Status().String()}
+	if s.StatusReason() != "" {
+		fields["reason"] = s.StatusReason()
+	}
+	return fields
+}
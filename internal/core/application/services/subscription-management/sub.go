@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
 	"github.com/kaasikodes/assessmate_backend/internal/core/domain/payment"
 	"github.com/kaasikodes/assessmate_backend/internal/core/domain/subscription"
 	pay_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/payment"
@@ -116,6 +117,16 @@ type (
 type SubscriptionManagementService struct {
 	planRepo    sub_repo.SubscriptionRepository
 	paymentRepo pay_repo.PaymentRepository
+	audit       *auditlog.AuditLogService
+}
+
+// Constructor
+func NewSubscriptionManagementService(planRepo sub_repo.SubscriptionRepository, paymentRepo pay_repo.PaymentRepository, audit *auditlog.AuditLogService) *SubscriptionManagementService {
+	return &SubscriptionManagementService{
+		planRepo:    planRepo,
+		paymentRepo: paymentRepo,
+		audit:       audit,
+	}
 }
 
 // create plan
@@ -144,6 +155,17 @@ func (s *SubscriptionManagementService) CreatePlan(ctx context.Context, payload
 	if err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, auditlog.Event{
+		Action:     "plan.create",
+		TargetType: auditlog.TargetPlan,
+		TargetId:   plan.Id().Value(),
+		After: map[string]any{
+			"name":           plan.Name().String(),
+			"durationInDays": int(plan.Duration().Days()),
+			"priceInUsd":     plan.Price().Amount(),
+			"isActive":       plan.IsActive().Bool(),
+		},
+	})
 
 	return &CreatePlanResponse{
 		Id:             plan.Id().Value(),
@@ -285,6 +307,12 @@ func (s *SubscriptionManagementService) ActivateOrDeactivatePlan(ctx context.Con
 	if err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, auditlog.Event{
+		Action:     "plan.set_active",
+		TargetType: auditlog.TargetPlan,
+		TargetId:   planId,
+		After:      map[string]any{"isActive": plan.IsActive().Bool()},
+	})
 
 	return &GetPlansResult{
 		Id:             plan.Id().Value(),
@@ -305,6 +333,10 @@ func (s *SubscriptionManagementService) ActivateOrDeactivatePlan(ctx context.Con
 
 // subscribeUserToPlan
 func (s *SubscriptionManagementService) SubscribeUserToPlan(ctx context.Context, _planId, _userId int) (*CreateSubscriptionResponse, error) {
+	// payments are never made on a user's behalf by an impersonating admin
+	if auditlog.IsImpersonated(ctx) {
+		return nil, auditlog.ErrImpersonated
+	}
 	// TODO: Add domain logic/methods to check wether userId belongs to a user, also add logic/methods to check that plan is present and isActive
 
 	plan, planId, err := s.verifyPlanExists(ctx, _planId)
@@ -341,6 +373,12 @@ func (s *SubscriptionManagementService) SubscribeUserToPlan(ctx context.Context,
 	if err != nil {
 		return nil, fmt.Errorf("payment initiation failed: %w", err)
 	}
+	s.audit.Record(ctx, auditlog.Event{
+		Action:     "subscription.create",
+		TargetType: auditlog.TargetSubscription,
+		TargetId:   subscriber.Id.Value(),
+		After:      map[string]any{"planId": _planId, "userId": _userId, "transactionId": transaction.Id().Value()},
+	})
 
 	return &CreateSubscriptionResponse{
 		Id:    subscriber.Id.Value(),
@@ -357,6 +395,9 @@ func (s *SubscriptionManagementService) SubscribeUserToPlan(ctx context.Context,
 
 // complete subsciption payment
 func (s *SubscriptionManagementService) CompleteSubscriptionPayment(ctx context.Context, _transactionId int) error {
+	if auditlog.IsImpersonated(ctx) {
+		return auditlog.ErrImpersonated
+	}
 	transactionId, err := payment.NewId(_transactionId)
 	if err != nil {
 		return fmt.Errorf("error parsing transactionId: %w", err)
@@ -365,6 +406,13 @@ func (s *SubscriptionManagementService) CompleteSubscriptionPayment(ctx context.
 	if err != nil {
 		return fmt.Errorf("error completing subscription payment: %w", err)
 	}
+	s.audit.Record(ctx, auditlog.Event{
+		Action:     "subscription.payment.complete",
+		TargetType: auditlog.TargetTransaction,
+		TargetId:   _transactionId,
+		Before:     map[string]any{"paid": false},
+		After:      map[string]any{"paid": true},
+	})
 	return nil
 }
 
@@ -464,6 +512,7 @@ func (s *SubscriptionManagementService) DeletePlan(ctx context.Context, planId i
 	if err != nil {
 		return fmt.Errorf("failed to delete plan with id %d: %w", planId, err)
 	}
+	s.audit.Record(ctx, auditlog.Event{Action: "plan.delete", TargetType: auditlog.TargetPlan, TargetId: planId})
 	return nil
 
 }
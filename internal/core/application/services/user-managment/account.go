@@ -0,0 +1,326 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	email_client "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
+)
+
+// how long a deleted account can be restored before its personal data is purged
+const accountDeletionGracePeriod = time.Hour * 24 * 30
+
+var (
+	ErrAccountDeleted    = errors.New("account has been scheduled for deletion")
+	ErrOwnerMustTransfer = errors.New("you own an institution, transfer its ownership to another admin before deleting your account")
+)
+
+type (
+	EmailChangeRecord struct {
+		OldEmail  string
+		NewEmail  string
+		Status    string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+	LoginAttemptRecord struct {
+		Failures     int
+		LastFailedAt *time.Time
+		LockedUntil  *time.Time
+	}
+	// StaffRecord is the user's membership of an institution, kept by user id or by their email before they joined
+	StaffRecord struct {
+		InstitutionId int
+		StaffId       int
+		Name          string
+		Email         string
+		Role          string
+		Status        string
+		CreatedAt     time.Time
+		RemovedAt     *time.Time
+	}
+	InviteRecord struct {
+		InstitutionId int
+		Name          string
+		Email         string
+		Status        string
+		CreatedAt     time.Time
+		ExpiresAt     time.Time
+		AcceptedAt    *time.Time
+		RevokedAt     *time.Time
+	}
+	// UserDataExport is everything held about a user, each field becomes a file in the export
+	UserDataExport struct {
+		ExportedAt    time.Time
+		User          User
+		Profile       Profile
+		Sessions      []Session
+		EmailChanges  []EmailChangeRecord
+		LoginAttempts LoginAttemptRecord
+		ApiKeys       []ApiKey
+		StaffRecords  []StaffRecord
+		Invites       []InviteRecord
+		AuditEntries  []auditlog.Entry
+	}
+)
+
+// Files returns the export as file names mapped to their contents
+func (e *UserDataExport) Files() map[string]any {
+	return map[string]any{
+		"export.json":              map[string]any{"exportedAt": e.ExportedAt},
+		"user.json":                e.User,
+		"profile.json":             e.Profile,
+		"sessions.json":            e.Sessions,
+		"email_changes.json":       e.EmailChanges,
+		"login_attempts.json":      e.LoginAttempts,
+		"api_keys.json":            e.ApiKeys,
+		"institution_staff.json":   e.StaffRecords,
+		"institution_invites.json": e.Invites,
+		"audit_log.json":           e.AuditEntries,
+	}
+}
+
+// DeleteAccount schedules the user's account for deletion and signs them out everywhere.
+// The account can be restored until the grace period runs out, after which it is purged.
+// Owners have to transfer their institutions first, the purge removes their staff records.
+func (u *UserManagementService) DeleteAccount(ctx context.Context, userId int, password string) error {
+	if auditlog.IsImpersonated(ctx) {
+		return auditlog.ErrImpersonated
+	}
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return fmt.Errorf("error parsing userId: %w", err)
+	}
+	if err := u.ensureOwnsNoInstitution(ctx, parsedUserId); err != nil {
+		return err
+	}
+	domainUser, err := u.userRepo.GetUserById(ctx, parsedUserId)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+	if !domainUser.ComparePassword(password) {
+		return ErrInvalidCredentials
+	}
+	now := time.Now()
+	if err := domainUser.ScheduleDeletion(now); err != nil {
+		return err
+	}
+	if _, err := u.userRepo.UpdateUserDeletedAt(ctx, domainUser); err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+	if err := u.revokeAllSessions(ctx, parsedUserId); err != nil {
+		return err
+	}
+	u.auditUserEvent(ctx, userId, "user.delete", userId, nil, map[string]any{"deletedAt": now.UTC()})
+
+	go func() {
+		err := u.emailClient.Send(context.Background(), &email_client.Notification{
+			Email: domainUser.GetEmail().String(),
+			Title: "Account Scheduled For Deletion",
+			Content: fmt.Sprintf("Your account has been scheduled for deletion and will be permanently removed on %s. You can restore it with your email and password until then.",
+				now.Add(accountDeletionGracePeriod).UTC().Format(time.RFC1123)),
+		})
+		if err != nil {
+			u.logger.WithContext(ctx).Error("error sending account deletion notification", err)
+		}
+	}()
+
+	return nil
+}
+
+// ensureOwnsNoInstitution returns ErrOwnerMustTransfer naming the first institution the user owns
+func (u *UserManagementService) ensureOwnsNoInstitution(ctx context.Context, userId user.Id) error {
+	memberships, err := u.memberships.ListMembershipsByUser(ctx, institution.Id(userId.Value()))
+	if err != nil {
+		return fmt.Errorf("error retrieving institutions: %w", err)
+	}
+	for _, m := range memberships {
+		if m.Role == institution.OwnerRole {
+			return fmt.Errorf("%w: %s", ErrOwnerMustTransfer, m.InstitutionName)
+		}
+	}
+	return nil
+}
+
+// RestoreAccount cancels a scheduled deletion within the grace period
+func (u *UserManagementService) RestoreAccount(ctx context.Context, email, password string) (*User, error) {
+	parsedEmail, err := user.NewEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing email: %w", err)
+	}
+	domainUser, err := u.userRepo.GetUserByEmail(ctx, parsedEmail)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if !domainUser.ComparePassword(password) {
+		return nil, ErrInvalidCredentials
+	}
+	if err := domainUser.RestoreDeleted(time.Now(), accountDeletionGracePeriod); err != nil {
+		return nil, err
+	}
+	if _, err := u.userRepo.UpdateUserDeletedAt(ctx, domainUser); err != nil {
+		return nil, fmt.Errorf("error restoring user: %w", err)
+	}
+	u.auditUserEvent(ctx, domainUser.GetId().Value(), "user.restore", domainUser.GetId().Value(), nil, nil)
+	return mapToServiceUser(domainUser), nil
+}
+
+// PurgeDeletedAccounts anonymises every account whose grace period has run out and returns how many were purged
+func (u *UserManagementService) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	users, err := u.userRepo.GetUsersDeletedBefore(ctx, time.Now().Add(-accountDeletionGracePeriod))
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving deleted users: %w", err)
+	}
+	purged := 0
+	for i := range users {
+		domainUser := &users[i]
+		// the avatar is personal data too, the profile row goes with the purge
+		if profile, err := u.userRepo.GetProfile(ctx, domainUser.GetId()); err == nil {
+			u.deleteFile(ctx, profile.AvatarKey())
+		}
+		domainUser.Anonymise()
+		if err := u.userRepo.PurgeUser(ctx, domainUser); err != nil {
+			u.logger.WithContext(ctx).Error("error purging user", domainUser.GetId().String(), err)
+			continue
+		}
+		// the system acts here, no one is signed in
+		u.auditUserEvent(ctx, 0, "user.purge", domainUser.GetId().Value(), nil, nil)
+		purged++
+	}
+	return purged, nil
+}
+
+// StartAccountPurger purges deleted accounts every interval until the context is done
+func (u *UserManagementService) StartAccountPurger(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purged, err := u.PurgeDeletedAccounts(ctx)
+				if err != nil {
+					u.logger.Error("error purging deleted accounts", err)
+					continue
+				}
+				if purged > 0 {
+					u.logger.Info("purged deleted accounts", purged)
+				}
+			}
+		}
+	}()
+}
+
+// ExportUserData gathers everything held about the user
+func (u *UserManagementService) ExportUserData(ctx context.Context, userId int) (*UserDataExport, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	domainUser, err := u.userRepo.GetUserById(ctx, parsedUserId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	profile, err := u.getProfile(ctx, parsedUserId)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := u.ListSessions(ctx, userId, 0)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := u.userRepo.ListEmailChanges(ctx, parsedUserId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving email changes: %w", err)
+	}
+	emailChanges := make([]EmailChangeRecord, len(changes))
+	for i, c := range changes {
+		emailChanges[i] = EmailChangeRecord{
+			OldEmail:  c.OldEmail().String(),
+			NewEmail:  c.NewEmail().String(),
+			Status:    c.Status().String(),
+			CreatedAt: c.CreatedAt(),
+			UpdatedAt: c.UpdatedAt(),
+		}
+	}
+	var loginAttempts LoginAttemptRecord
+	if key, err := user.NewAccountLoginAttemptKey(domainUser.GetEmail()); err == nil {
+		attempt, err := u.loginAttempts.GetLoginAttempt(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving login attempts: %w", err)
+		}
+		loginAttempts = LoginAttemptRecord{
+			Failures:     attempt.Failures(),
+			LastFailedAt: attempt.LastFailedAt(),
+			LockedUntil:  attempt.LockedUntil(),
+		}
+	}
+	apiKeys, err := u.ListApiKeys(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	// the institutions hold the user by id once they joined and by email before then
+	email := institution.Email(domainUser.GetEmail().String())
+	staff, err := u.personalData.ListStaffRecordsOfPerson(ctx, institution.Id(userId), email)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving staff records: %w", err)
+	}
+	staffRecords := make([]StaffRecord, len(staff))
+	staffIds := make([]institution.Id, len(staff))
+	auditStaffIds := make([]int, len(staff))
+	for i, r := range staff {
+		staffRecords[i] = StaffRecord{
+			InstitutionId: int(r.InstitutionId),
+			StaffId:       int(r.Staff.Id()),
+			Name:          r.Staff.Name().String(),
+			Email:         r.Staff.Email().String(),
+			Role:          r.Staff.Role().String(),
+			Status:        r.Staff.Status().String(),
+			CreatedAt:     r.Staff.CreatedAt(),
+			RemovedAt:     r.Staff.DeletedAt(),
+		}
+		staffIds[i] = r.Staff.Id()
+		auditStaffIds[i] = int(r.Staff.Id())
+	}
+	invites, err := u.personalData.ListInvitesToPerson(ctx, email, staffIds)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving invites: %w", err)
+	}
+	inviteRecords := make([]InviteRecord, len(invites))
+	for i, inv := range invites {
+		inviteRecords[i] = InviteRecord{
+			InstitutionId: int(inv.InstitutionId()),
+			Name:          inv.Name().String(),
+			Email:         inv.Email().String(),
+			Status:        inv.StatusAt(time.Now()).String(),
+			CreatedAt:     inv.CreatedAt(),
+			ExpiresAt:     inv.ExpiresAt(),
+			AcceptedAt:    inv.AcceptedAt(),
+			RevokedAt:     inv.RevokedAt(),
+		}
+	}
+	auditEntries, err := u.audit.EntriesAboutUser(ctx, userId, auditStaffIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserDataExport{
+		ExportedAt:    time.Now().UTC(),
+		User:          *mapToServiceUser(domainUser),
+		Profile:       *mapToServiceProfile(profile),
+		Sessions:      sessions,
+		EmailChanges:  emailChanges,
+		LoginAttempts: loginAttempts,
+		ApiKeys:       apiKeys,
+		StaffRecords:  staffRecords,
+		Invites:       inviteRecords,
+		AuditEntries:  auditEntries,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+)
+
+type fakeMembershipReader []institution.Membership
+
+func (f fakeMembershipReader) ListMembershipsByUser(ctx context.Context, userId institution.Id) ([]institution.Membership, error) {
+	return f, nil
+}
+
+var errUserLoaded = errors.New("user loaded")
+
+// fakeUserRepo stops DeleteAccount at the first lookup, any other method panics on the nil interface
+type fakeUserRepo struct {
+	user_repo.UserRepository
+}
+
+func (fakeUserRepo) GetUserById(ctx context.Context, userId user.Id) (*user.User, error) {
+	return nil, errUserLoaded
+}
+
+func TestDeleteAccountRequiresOwnersToTransferFirst(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []institution.StaffRole
+		want  error
+	}{
+		{name: "owner of an institution", roles: []institution.StaffRole{institution.MemberRole, institution.OwnerRole}, want: ErrOwnerMustTransfer},
+		{name: "admin who owns nothing", roles: []institution.StaffRole{institution.AdminRole}, want: errUserLoaded},
+		{name: "no institutions", want: errUserLoaded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memberships := make(fakeMembershipReader, len(tt.roles))
+			for i, role := range tt.roles {
+				memberships[i] = institution.Membership{InstitutionId: institution.Id(i + 1), InstitutionName: "Hilltop College", Role: role, Status: institution.Active}
+			}
+			u := &UserManagementService{userRepo: fakeUserRepo{}, memberships: memberships}
+
+			if err := u.DeleteAccount(context.Background(), 7, "password"); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
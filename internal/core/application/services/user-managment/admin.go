@@ -0,0 +1,218 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var (
+	ErrAccountSuspended = errors.New("account has been suspended")
+	ErrNotPlatformAdmin = errors.New("platform admin role required")
+)
+
+// checkCanSignIn is applied on every way of signing in
+func checkCanSignIn(domainUser *user.User) error {
+	if domainUser.IsDeleted() {
+		return ErrAccountDeleted
+	}
+	if domainUser.IsSuspended() {
+		return ErrAccountSuspended
+	}
+	return nil
+}
+
+func normalisePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
+
+// IsPlatformAdmin
+func (u *UserManagementService) IsPlatformAdmin(ctx context.Context, userId int) (bool, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return false, fmt.Errorf("error parsing userId: %w", err)
+	}
+	roles, err := u.userRepo.GetUserRoles(ctx, parsedUserId)
+	if err != nil {
+		return false, fmt.Errorf("error retrieving roles: %w", err)
+	}
+	return slices.Contains(roles, user.PlatformAdmin), nil
+}
+
+// requirePlatformAdmin guards every admin action, the http layer checks too but the service does not rely on it
+func (u *UserManagementService) requirePlatformAdmin(ctx context.Context, actorId int) error {
+	isAdmin, err := u.IsPlatformAdmin(ctx, actorId)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return ErrNotPlatformAdmin
+	}
+	return nil
+}
+
+// AdminGetUsers lists users for a platform admin
+func (u *UserManagementService) AdminGetUsers(ctx context.Context, actorId int, filter *UserFilter) (*GetUsersResponse, error) {
+	if err := u.requirePlatformAdmin(ctx, actorId); err != nil {
+		return nil, err
+	}
+	res, err := u.GetUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	u.auditUserEvent(ctx, actorId, "admin.users.list", 0, nil, nil)
+	return res, nil
+}
+
+// AdminGetUser
+func (u *UserManagementService) AdminGetUser(ctx context.Context, actorId, userId int) (*User, error) {
+	if err := u.requirePlatformAdmin(ctx, actorId); err != nil {
+		return nil, err
+	}
+	res, err := u.FindUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	u.auditUserEvent(ctx, actorId, "admin.user.view", userId, nil, nil)
+	return res, nil
+}
+
+// SuspendUser blocks the user from signing in and ends their sessions
+func (u *UserManagementService) SuspendUser(ctx context.Context, actorId, userId int) (*User, error) {
+	if err := u.requirePlatformAdmin(ctx, actorId); err != nil {
+		return nil, err
+	}
+	if actorId == userId {
+		return nil, errors.New("admins cannot suspend themselves")
+	}
+	domainUser, err := u.getUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	before := map[string]any{"status": domainUser.GetStatus().String()}
+	if err := domainUser.Suspend(); err != nil {
+		return nil, err
+	}
+	if _, err := u.userRepo.ChangeUserStatus(ctx, domainUser.GetId(), domainUser.GetStatus()); err != nil {
+		return nil, fmt.Errorf("error suspending user: %w", err)
+	}
+	if err := u.revokeAllSessions(ctx, domainUser.GetId()); err != nil {
+		return nil, err
+	}
+	u.auditUserEvent(ctx, actorId, "admin.user.suspend", userId, before, map[string]any{"status": domainUser.GetStatus().String()})
+	return mapToServiceUser(domainUser), nil
+}
+
+// ReactivateUser lifts a suspension
+func (u *UserManagementService) ReactivateUser(ctx context.Context, actorId, userId int) (*User, error) {
+	if err := u.requirePlatformAdmin(ctx, actorId); err != nil {
+		return nil, err
+	}
+	domainUser, err := u.getUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	before := map[string]any{"status": domainUser.GetStatus().String()}
+	if err := domainUser.Reactivate(); err != nil {
+		return nil, err
+	}
+	if _, err := u.userRepo.ChangeUserStatus(ctx, domainUser.GetId(), domainUser.GetStatus()); err != nil {
+		return nil, fmt.Errorf("error reactivating user: %w", err)
+	}
+	u.auditUserEvent(ctx, actorId, "admin.user.reactivate", userId, before, map[string]any{"status": domainUser.GetStatus().String()})
+	return mapToServiceUser(domainUser), nil
+}
+
+// ForcePasswordReset clears the user's password, signs them out everywhere and emails them a reset token
+func (u *UserManagementService) ForcePasswordReset(ctx context.Context, actorId, userId int) error {
+	if err := u.requirePlatformAdmin(ctx, actorId); err != nil {
+		return err
+	}
+	domainUser, err := u.getUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	domainUser.ClearPassword()
+	if _, err := u.userRepo.UpdateUserPassword(ctx, domainUser); err != nil {
+		return fmt.Errorf("error clearing user password: %w", err)
+	}
+	if err := u.revokeAllSessions(ctx, domainUser.GetId()); err != nil {
+		return err
+	}
+	if _, err := u.ForgotPassword(ctx, domainUser.GetEmail().String()); err != nil {
+		return err
+	}
+	u.auditUserEvent(ctx, actorId, "admin.user.force_password_reset", userId, nil, nil)
+	return nil
+}
+
+// AdminResendVerification
+func (u *UserManagementService) AdminResendVerification(ctx context.Context, actorId, userId int) error {
+	if err := u.requirePlatformAdmin(ctx, actorId); err != nil {
+		return err
+	}
+	domainUser, err := u.getUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if _, err := u.CreateAndSendVerificationTokenForExistingUser(ctx, domainUser.GetEmail().String()); err != nil {
+		return err
+	}
+	u.auditUserEvent(ctx, actorId, "admin.user.resend_verification", userId, nil, nil)
+	return nil
+}
+
+// AdminListSessions returns the user's active sessions
+func (u *UserManagementService) AdminListSessions(ctx context.Context, actorId, userId int) ([]Session, error) {
+	if err := u.requirePlatformAdmin(ctx, actorId); err != nil {
+		return nil, err
+	}
+	sessions, err := u.ListSessions(ctx, userId, 0)
+	if err != nil {
+		return nil, err
+	}
+	u.auditUserEvent(ctx, actorId, "admin.user.sessions.list", userId, nil, nil)
+	return sessions, nil
+}
+
+func (u *UserManagementService) getUser(ctx context.Context, userId int) (*user.User, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	domainUser, err := u.userRepo.GetUserById(ctx, parsedUserId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	return domainUser, nil
+}
+
+// auditUserEvent records who did what to which user, before and after hold the fields the action changed
+func (u *UserManagementService) auditUserEvent(ctx context.Context, actorId int, action string, targetUserId int, before, after map[string]any) {
+	u.audit.Record(ctx, auditlog.Event{
+		ActorId:    actorId,
+		Action:     action,
+		TargetType: auditlog.TargetUser,
+		TargetId:   targetUserId,
+		Before:     before,
+		After:      after,
+	})
+}
@@ -0,0 +1,187 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+)
+
+var (
+	ErrInvalidApiKey       = errors.New("invalid api key")
+	ErrNotInstitutionAdmin = errors.New("institution admin role required")
+)
+
+type (
+	ApiKey struct {
+		Id            int
+		Name          string
+		Prefix        string
+		Scopes        []string
+		InstitutionId *int
+		ExpiresAt     *time.Time
+		LastUsedAt    *time.Time
+		CreatedAt     time.Time
+	}
+	// CreateApiKeyResponse holds the only copy of the key that is ever returned
+	CreateApiKeyResponse struct {
+		ApiKey ApiKey
+		Key    string
+	}
+	// ApiKeyPrincipal is who a request authenticated with an api key acts as
+	ApiKeyPrincipal struct {
+		User   User
+		ApiKey ApiKey
+	}
+)
+
+// CreateApiKey issues a key for the user, or for the institution when institutionId is set
+func (u *UserManagementService) CreateApiKey(ctx context.Context, userId int, name string, scopes []string, expiresAt *time.Time, institutionId *int) (*CreateApiKeyResponse, error) {
+	// a key would outlive the impersonation
+	if auditlog.IsImpersonated(ctx) {
+		return nil, auditlog.ErrImpersonated
+	}
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	parsedScopes := make([]user.ApiKeyScope, len(scopes))
+	for i, s := range scopes {
+		if parsedScopes[i], err = user.NewApiKeyScope(s); err != nil {
+			return nil, err
+		}
+	}
+	key, err := user.NewApiKey(parsedUserId, name, parsedScopes, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing api key: %w", err)
+	}
+	if institutionId != nil {
+		parsedInstitutionId, err := user.NewId(*institutionId)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing institutionId: %w", err)
+		}
+		if err := u.authorizeInstitutionApiKey(ctx, parsedUserId, parsedInstitutionId); err != nil {
+			return nil, err
+		}
+		key.SetInstitutionId(parsedInstitutionId)
+	}
+
+	rawKey := key.SetSecret(u.randomIdGenerator.Create("", 8), u.randomIdGenerator.Create("", 40))
+	key, err = u.userRepo.CreateApiKey(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("error saving api key: %w", err)
+	}
+	created := mapToServiceApiKey(key)
+	u.audit.Record(ctx, auditlog.Event{
+		ActorId:       userId,
+		Action:        "api_key.create",
+		TargetType:    auditlog.TargetApiKey,
+		TargetId:      created.Id,
+		InstitutionId: institutionId,
+		After:         map[string]any{"name": created.Name, "prefix": created.Prefix, "scopes": created.Scopes},
+	})
+	return &CreateApiKeyResponse{ApiKey: created, Key: rawKey}, nil
+}
+
+// authorizeInstitutionApiKey ensures the user may issue keys that act for the institution, only its admins can.
+func (u *UserManagementService) authorizeInstitutionApiKey(ctx context.Context, userId, institutionId user.Id) error {
+	canManage, err := u.userRepo.CanManageInstitution(ctx, userId, institutionId)
+	if err != nil {
+		return fmt.Errorf("error checking institution role: %w", err)
+	}
+	if !canManage {
+		return ErrNotInstitutionAdmin
+	}
+	return nil
+}
+
+// ListApiKeys returns the user's active keys, never the keys themselves
+func (u *UserManagementService) ListApiKeys(ctx context.Context, userId int) ([]ApiKey, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	data, err := u.userRepo.ListApiKeys(ctx, parsedUserId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving api keys: %w", err)
+	}
+	keys := make([]ApiKey, len(data))
+	for i := range data {
+		keys[i] = mapToServiceApiKey(&data[i])
+	}
+	return keys, nil
+}
+
+// RevokeApiKey
+func (u *UserManagementService) RevokeApiKey(ctx context.Context, userId, keyId int) error {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return fmt.Errorf("error parsing userId: %w", err)
+	}
+	parsedKeyId, err := user.NewId(keyId)
+	if err != nil {
+		return fmt.Errorf("error parsing keyId: %w", err)
+	}
+	if err := u.userRepo.RevokeApiKey(ctx, parsedUserId, parsedKeyId); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, auditlog.Event{ActorId: userId, Action: "api_key.revoke", TargetType: auditlog.TargetApiKey, TargetId: keyId})
+	return nil
+}
+
+// AuthenticateApiKey resolves the key to the user it acts as and records that it was used
+func (u *UserManagementService) AuthenticateApiKey(ctx context.Context, rawKey string) (*ApiKeyPrincipal, error) {
+	prefix, err := user.ParseApiKeyPrefix(rawKey)
+	if err != nil {
+		return nil, ErrInvalidApiKey
+	}
+	key, err := u.userRepo.GetApiKeyByPrefix(ctx, prefix)
+	if errors.Is(err, user_repo.ErrApiKeyNotFound) {
+		return nil, ErrInvalidApiKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving api key: %w", err)
+	}
+	now := time.Now()
+	if !key.Matches(rawKey) || !key.IsUsable(now) {
+		return nil, ErrInvalidApiKey
+	}
+	domainUser, err := u.userRepo.GetUserById(ctx, key.UserId())
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if key.MarkUsed(now) {
+		if err := u.userRepo.UpdateApiKeyLastUsedAt(ctx, key); err != nil {
+			u.logger.WithContext(ctx).Error("error recording api key use", err)
+		}
+	}
+	return &ApiKeyPrincipal{
+		User:   *mapToServiceUser(domainUser),
+		ApiKey: mapToServiceApiKey(key),
+	}, nil
+}
+
+func mapToServiceApiKey(k *user.ApiKey) ApiKey {
+	scopes := make([]string, len(k.Scopes()))
+	for i, s := range k.Scopes() {
+		scopes[i] = s.String()
+	}
+	key := ApiKey{
+		Id:         k.Id().Value(),
+		Name:       k.Name(),
+		Prefix:     k.Prefix(),
+		Scopes:     scopes,
+		ExpiresAt:  k.ExpiresAt(),
+		LastUsedAt: k.LastUsedAt(),
+		CreatedAt:  k.CreatedAt(),
+	}
+	if k.InstitutionId() != nil {
+		institutionId := k.InstitutionId().Value()
+		key.InstitutionId = &institutionId
+	}
+	return key
+}
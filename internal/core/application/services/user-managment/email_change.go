@@ -0,0 +1,252 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	email_client "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
+)
+
+const (
+	emailChangeConfirmDuration = time.Hour * 24
+	emailChangeRevertDuration  = time.Hour * 24 * 7
+)
+
+// RequestEmailChange starts moving the account to newEmail. The new address gets a confirmation token,
+// the current address gets a notice with a token that can undo the change for a limited time.
+func (u *UserManagementService) RequestEmailChange(ctx context.Context, userId int, newEmail, password string) error {
+	if auditlog.IsImpersonated(ctx) {
+		return auditlog.ErrImpersonated
+	}
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return fmt.Errorf("error parsing userId: %w", err)
+	}
+	parsedEmail, err := user.NewEmail(newEmail)
+	if err != nil {
+		return fmt.Errorf("error parsing email: %w", err)
+	}
+	domainUser, err := u.userRepo.GetUserById(ctx, parsedUserId)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+	if !domainUser.ComparePassword(password) {
+		return ErrInvalidCredentials
+	}
+	if existingUser, _ := u.userRepo.GetUserByEmail(ctx, parsedEmail); existingUser != nil {
+		return errors.New("email has been taken")
+	}
+
+	change, err := user.NewEmailChange(domainUser.GetId(), domainUser.GetEmail(), parsedEmail, time.Now().Add(emailChangeRevertDuration))
+	if err != nil {
+		return fmt.Errorf("error parsing email change: %w", err)
+	}
+	// only the latest request can be confirmed, the links sent for earlier requests stop working
+	if err := u.userRepo.CancelPendingEmailChanges(ctx, domainUser.GetId()); err != nil {
+		return fmt.Errorf("error cancelling previous email changes: %w", err)
+	}
+	change, err = u.userRepo.CreateEmailChange(ctx, change)
+	if err != nil {
+		return fmt.Errorf("error saving email change: %w", err)
+	}
+
+	confirmToken, err := u.createEmailChangeToken(ctx, change, "email_change_", user.EmailChangeConfirmation, time.Now().Add(emailChangeConfirmDuration))
+	if err != nil {
+		return err
+	}
+	revertToken, err := u.createEmailChangeToken(ctx, change, "email_revert_", user.EmailChangeRevert, change.RevertableUntil())
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := u.emailClient.SendMultiple(context.Background(), []email_client.Notification{
+			{
+				Email:   parsedEmail.String(),
+				Title:   "Confirm Your New Email",
+				Content: fmt.Sprintf("Use this token to confirm %s as the new email for your account: %s", parsedEmail.String(), confirmToken.String()),
+			},
+			{
+				Email: domainUser.GetEmail().String(),
+				Title: "Email Change Requested",
+				Content: fmt.Sprintf("A request was made to change the email on your account to %s. If this wasn't you, use this token before %s to undo it: %s",
+					parsedEmail.String(), change.RevertableUntil().UTC().Format(time.RFC1123), revertToken.String()),
+			},
+		})
+		if err != nil {
+			u.logger.WithContext(ctx).Error("error sending email change notifications", err)
+		}
+	}()
+
+	return nil
+}
+
+// ConfirmEmailChange applies the pending change once the new address presents its token
+func (u *UserManagementService) ConfirmEmailChange(ctx context.Context, userId int, _token string) (*User, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	token, err := u.getTypedToken(ctx, parsedUserId, _token, user.EmailChangeConfirmation)
+	if err != nil {
+		return nil, err
+	}
+	change, err := u.emailChangeOfToken(ctx, parsedUserId, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := change.Confirm(); err != nil {
+		return nil, err
+	}
+	// the address could have been registered since the request
+	if existingUser, _ := u.userRepo.GetUserByEmail(ctx, change.NewEmail()); existingUser != nil {
+		return nil, errors.New("email has been taken")
+	}
+
+	domainUser, err := u.userRepo.GetUserById(ctx, parsedUserId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	domainUser.SetEmail(change.NewEmail())
+	if _, err := u.userRepo.UpdateUserEmail(ctx, domainUser); err != nil {
+		return nil, fmt.Errorf("error updating user email: %w", err)
+	}
+	if err := u.userRepo.UpdateEmailChange(ctx, change); err != nil {
+		return nil, fmt.Errorf("error updating email change: %w", err)
+	}
+	if err := u.userRepo.DeleteToken(ctx, token.Id()); err != nil {
+		return nil, fmt.Errorf("error deleting token: %w", err)
+	}
+	u.audit.Record(ctx, auditlog.Event{
+		ActorId:    userId,
+		Action:     "user.email_change.confirm",
+		TargetType: auditlog.TargetUser,
+		TargetId:   userId,
+		Redacted:   []string{"email"},
+	})
+
+	go func() {
+		err := u.emailClient.Send(context.Background(), &email_client.Notification{
+			Email: change.OldEmail().String(),
+			Title: "Email Changed",
+			Content: fmt.Sprintf("The email on your account is now %s. If this wasn't you, the token in our previous email can undo the change until %s.",
+				change.NewEmail().String(), change.RevertableUntil().UTC().Format(time.RFC1123)),
+		})
+		if err != nil {
+			u.logger.WithContext(ctx).Error("error sending email changed notification", err)
+		}
+	}()
+
+	return mapToServiceUser(domainUser), nil
+}
+
+// RevertEmailChange is the "this wasn't me" action for the old address, it cancels a pending change or restores the old email
+// and signs the account out everywhere if the change had already been applied.
+func (u *UserManagementService) RevertEmailChange(ctx context.Context, userId int, _token string) error {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return fmt.Errorf("error parsing userId: %w", err)
+	}
+	token, err := u.getTypedToken(ctx, parsedUserId, _token, user.EmailChangeRevert)
+	if err != nil {
+		return err
+	}
+	change, err := u.emailChangeOfToken(ctx, parsedUserId, token)
+	if err != nil {
+		return err
+	}
+	restore, err := change.Revert(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if restore {
+		domainUser, err := u.userRepo.GetUserById(ctx, parsedUserId)
+		if err != nil {
+			return fmt.Errorf("error retrieving user: %w", err)
+		}
+		domainUser.SetEmail(change.OldEmail())
+		if _, err := u.userRepo.UpdateUserEmail(ctx, domainUser); err != nil {
+			return fmt.Errorf("error restoring user email: %w", err)
+		}
+		if err := u.revokeAllSessions(ctx, parsedUserId); err != nil {
+			return err
+		}
+	}
+	if err := u.userRepo.UpdateEmailChange(ctx, change); err != nil {
+		return fmt.Errorf("error updating email change: %w", err)
+	}
+	// whoever asked for the change may still hold links, none of them should work any more
+	if err := u.userRepo.DeleteEmailChangeTokens(ctx, parsedUserId); err != nil {
+		return fmt.Errorf("error deleting email change tokens: %w", err)
+	}
+	var redacted []string
+	if restore {
+		redacted = []string{"email"}
+	}
+	u.audit.Record(ctx, auditlog.Event{
+		ActorId:    userId,
+		Action:     "user.email_change.revert",
+		TargetType: auditlog.TargetUser,
+		TargetId:   userId,
+		Redacted:   redacted,
+	})
+	return nil
+}
+
+func (u *UserManagementService) revokeAllSessions(ctx context.Context, userId user.Id) error {
+	sessions, err := u.userRepo.ListActiveSessions(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("error retrieving sessions: %w", err)
+	}
+	for _, s := range sessions {
+		if err := u.userRepo.RevokeSession(ctx, userId, s.Id()); err != nil {
+			return fmt.Errorf("error revoking session: %w", err)
+		}
+	}
+	return nil
+}
+
+// createEmailChangeToken stores a new random token of the given type for the email change
+func (u *UserManagementService) createEmailChangeToken(ctx context.Context, change *user.EmailChange, prefix string, tokenType user.TokenType, expiresAt time.Time) (user.TokenValue, error) {
+	tokenVal, err := user.NewTokenValue(u.randomIdGenerator.Create(prefix, 20))
+	if err != nil {
+		return "", fmt.Errorf("error parsing token value: %w", err)
+	}
+	if _, err := u.userRepo.CreateEmailChangeToken(ctx, tokenVal, tokenType, change.UserId(), change.Id(), expiresAt); err != nil {
+		return "", fmt.Errorf("error saving token: %w", err)
+	}
+	return tokenVal, nil
+}
+
+// emailChangeOfToken returns the email change the token was issued for
+func (u *UserManagementService) emailChangeOfToken(ctx context.Context, userId user.Id, token *user.Token) (*user.EmailChange, error) {
+	if token.EmailChangeId() == nil {
+		return nil, fmt.Errorf("error retrieving token: %w", errors.New("invalid token provided"))
+	}
+	change, err := u.userRepo.GetEmailChangeById(ctx, userId, *token.EmailChangeId())
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving email change: %w", err)
+	}
+	return change, nil
+}
+
+// getTypedToken retrieves the user's token and ensures it is of the expected type and still valid
+func (u *UserManagementService) getTypedToken(ctx context.Context, userId user.Id, value string, tokenType user.TokenType) (*user.Token, error) {
+	tokenVal, err := user.NewTokenValue(value)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing token value: %w", err)
+	}
+	token, err := u.userRepo.GetToken(ctx, userId, tokenVal)
+	if err != nil || token.Type() != tokenType {
+		return nil, fmt.Errorf("error retrieving token: %w", errors.New("invalid token provided"))
+	}
+	if token.HasExpired() {
+		return nil, errors.New("token has expired")
+	}
+	return token, nil
+}
@@ -0,0 +1,88 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
+)
+
+// joinByEmailDomain adds the user to the institution that verified their email domain.
+// Until the email is verified they are only added as inactive staff without their account,
+// verifying it links the account and applies the institution's join policy.
+// Joining never fails signing up, errors are only logged.
+func (u *UserManagementService) joinByEmailDomain(ctx context.Context, domainUser *user.User) {
+	if u.domainJoins == nil {
+		return
+	}
+	email, err := institution.NewEmail(domainUser.GetEmail().String())
+	if err != nil {
+		return
+	}
+	claim, err := u.domainJoins.GetVerifiedEmailDomain(ctx, institution.DomainOf(email))
+	if errors.Is(err, institute_repo.ErrEmailDomainNotFound) {
+		return
+	}
+	if err != nil {
+		u.logger.WithContext(ctx).Error("error finding email domain to join", err)
+		return
+	}
+	instituteId := claim.InstitutionId()
+	institutionId := instituteId.Value()
+	userId := domainUser.GetId().Value()
+
+	staff, err := u.domainJoins.GetStaffByEmail(ctx, instituteId, email)
+	if errors.Is(err, institute_repo.ErrStaffNotFound) {
+		if staff, err = u.addDomainStaff(ctx, claim, domainUser.GetName().String(), email); err != nil {
+			u.logger.WithContext(ctx).Error("error joining institution by email domain", err)
+			return
+		}
+		u.audit.Record(ctx, auditlog.Event{
+			ActorId:       userId,
+			Action:        "staff.domain_join",
+			TargetType:    auditlog.TargetStaff,
+			TargetId:      staff.Id().Value(),
+			InstitutionId: &institutionId,
+			After:         map[string]any{"status": staff.Status().String()},
+			Redacted:      []string{"name", "email"},
+		})
+	} else if err != nil {
+		u.logger.WithContext(ctx).Error("error finding staff to join by email domain", err)
+		return
+	}
+	// only a verified email proves the user owns it, and a staff already linked has joined
+	if !domainUser.IsVerified() || staff.UserId() != nil || staff.Status() != institution.InActive {
+		return
+	}
+	if err := claim.JoinStaff(staff, institution.Id(userId)); err != nil {
+		return
+	}
+	if err := u.domainJoins.LinkStaffUser(ctx, instituteId, staff); err != nil {
+		u.logger.WithContext(ctx).Error("error linking staff who joined by email domain", err)
+		return
+	}
+	u.audit.Record(ctx, auditlog.Event{
+		ActorId:       userId,
+		Action:        "staff.domain_join.link",
+		TargetType:    auditlog.TargetStaff,
+		TargetId:      staff.Id().Value(),
+		InstitutionId: &institutionId,
+		Before:        map[string]any{"status": institution.InActive.String()},
+		After:         map[string]any{"status": staff.Status().String(), "userId": userId},
+	})
+}
+
+func (u *UserManagementService) addDomainStaff(ctx context.Context, claim *institution.EmailDomain, name string, email institution.Email) (*institution.Staff, error) {
+	parsedName, err := institution.NewName(name)
+	if err != nil {
+		return nil, err
+	}
+	staff, err := claim.NewStaff(parsedName, email)
+	if err != nil {
+		return nil, err
+	}
+	return u.domainJoins.AddStaffToInstitution(ctx, claim.InstitutionId(), *staff)
+}
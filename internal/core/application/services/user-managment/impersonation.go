@@ -0,0 +1,78 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+)
+
+// impersonation tokens are short lived and cannot be refreshed, support starts a new one if they need longer
+const impersonationTokenDuration = time.Minute * 15
+
+var ErrCannotImpersonate = errors.New("this user cannot be impersonated")
+
+type ImpersonationResponse struct {
+	User           User
+	AccessToken    string
+	ExpiresAt      time.Time
+	ImpersonatorId int
+}
+
+// Impersonate issues a token for the platform admin to act as the user, it carries both of them
+// and every request made with it is audited.
+func (u *UserManagementService) Impersonate(ctx context.Context, actorId, userId int) (*ImpersonationResponse, error) {
+	if auditlog.IsImpersonated(ctx) {
+		return nil, auditlog.ErrImpersonated
+	}
+	if err := u.requirePlatformAdmin(ctx, actorId); err != nil {
+		return nil, err
+	}
+	if actorId == userId {
+		return nil, ErrCannotImpersonate
+	}
+	domainUser, err := u.getUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanSignIn(domainUser); err != nil {
+		return nil, err
+	}
+	// an admin acting as another admin would gain whatever that admin can do
+	isAdmin, err := u.IsPlatformAdmin(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin {
+		return nil, ErrCannotImpersonate
+	}
+
+	expiresAt := time.Now().Add(impersonationTokenDuration)
+	token, err := u.jwt.CreateImpersonationToken(domainUser.GetId().String(), domainUser.GetEmail().String(), fmt.Sprint(actorId), impersonationTokenDuration)
+	if err != nil {
+		return nil, fmt.Errorf("error creating impersonation token: %w", err)
+	}
+	u.auditUserEvent(ctx, actorId, "admin.impersonation.start", userId, nil, map[string]any{"expiresAt": expiresAt.UTC()})
+
+	return &ImpersonationResponse{
+		User:           *mapToServiceUser(domainUser),
+		AccessToken:    token,
+		ExpiresAt:      expiresAt,
+		ImpersonatorId: actorId,
+	}, nil
+}
+
+// ValidateImpersonator checks on every request that the admin behind an impersonation token may still impersonate,
+// so removing the role or suspending the admin ends their impersonation straight away
+func (u *UserManagementService) ValidateImpersonator(ctx context.Context, impersonatorId int) error {
+	admin, err := u.getUser(ctx, impersonatorId)
+	if err != nil {
+		return err
+	}
+	if err := checkCanSignIn(admin); err != nil {
+		return err
+	}
+	return u.requirePlatformAdmin(ctx, impersonatorId)
+}
@@ -0,0 +1,77 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+)
+
+var ErrNoInstitutionAccess = errors.New("you do not have access to this institution")
+
+type SwitchInstitutionResponse struct {
+	// AccessToken only works within the institution, the refresh token of the session is unchanged
+	AccessToken string
+	Institution Institution
+}
+
+// userInstitutions lists every institution the user is staff of with their standing there
+func (u *UserManagementService) userInstitutions(ctx context.Context, userId user.Id) ([]Institution, error) {
+	memberships, err := u.memberships.ListMembershipsByUser(ctx, institution.Id(userId.Value()))
+	if err != nil {
+		return nil, fmt.Errorf("error listing institutions: %w", err)
+	}
+	institutions := make([]Institution, len(memberships))
+	for i, m := range memberships {
+		institutions[i] = mapToServiceInstitution(m)
+	}
+	return institutions, nil
+}
+
+// SwitchInstitution issues an access token for the session that is scoped to the institution, the user must have access to it
+func (u *UserManagementService) SwitchInstitution(ctx context.Context, userId, sessionId, institutionId int) (*SwitchInstitutionResponse, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	domainUser, err := u.userRepo.GetUserById(ctx, parsedUserId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	institutions, err := u.userInstitutions(ctx, parsedUserId)
+	if err != nil {
+		return nil, err
+	}
+	var chosen *Institution
+	for i := range institutions {
+		if institutions[i].Id == institutionId {
+			chosen = &institutions[i]
+			break
+		}
+	}
+	if chosen == nil || !chosen.IsActive {
+		return nil, ErrNoInstitutionAccess
+	}
+	token, err := u.jwt.CreateTenantToken(domainUser.GetId().String(), domainUser.GetEmail().String(), strconv.Itoa(sessionId), strconv.Itoa(institutionId), accessTokenDuration)
+	if err != nil {
+		return nil, fmt.Errorf("error creating access token: %w", err)
+	}
+	u.auditUserEvent(ctx, userId, "user.institution.switch", userId, nil, map[string]any{"institutionId": institutionId})
+	return &SwitchInstitutionResponse{AccessToken: token, Institution: *chosen}, nil
+}
+
+func mapToServiceInstitution(m institution.Membership) Institution {
+	return Institution{
+		Id:          m.InstitutionId.Value(),
+		Name:        m.InstitutionName.String(),
+		Description: m.InstitutionDescription.String(),
+		StaffId:     m.StaffId.Value(),
+		Role:        m.Role.String(),
+		Status:      m.Status.String(),
+		IsUserAdmin: m.IsAdmin(),
+		IsActive:    m.HasAccess(),
+	}
+}
@@ -0,0 +1,76 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+)
+
+var ErrPasswordRequired = errors.New("password is required to create an account")
+
+// ProvisionInvitedUser returns the account an invitee signs in with, creating it when there is none.
+// The invite was sent to the email, so the account is verified without a separate verification token.
+func (u *UserManagementService) ProvisionInvitedUser(ctx context.Context, name, email, password string) (int, bool, error) {
+	parsedEmail, err := user.NewEmail(email)
+	if err != nil {
+		return 0, false, fmt.Errorf("error parsing email: %w", err)
+	}
+	existingUser, err := u.userRepo.GetUserByEmail(ctx, parsedEmail)
+	if err == nil {
+		if err := checkCanSignIn(existingUser); err != nil {
+			return 0, false, err
+		}
+		if !existingUser.IsVerified() {
+			existingUser.SetVerifiedAt(time.Now())
+			if _, err := u.userRepo.VerifyUser(ctx, existingUser); err != nil {
+				return 0, false, fmt.Errorf("error verifying user: %w", err)
+			}
+			u.auditUserEvent(ctx, existingUser.GetId().Value(), "user.verify", existingUser.GetId().Value(), map[string]any{"verified": false}, map[string]any{"verified": true})
+		}
+		return existingUser.GetId().Value(), false, nil
+	}
+	if !errors.Is(err, user_repo.ErrUserNotFound) {
+		return 0, false, fmt.Errorf("error retrieving user: %w", err)
+	}
+
+	if password == "" {
+		return 0, false, ErrPasswordRequired
+	}
+	parsedName, err := user.NewName(name)
+	if err != nil {
+		return 0, false, fmt.Errorf("error parsing name: %w", err)
+	}
+	domainUser, err := user.NewUser(parsedName, parsedEmail)
+	if err != nil {
+		return 0, false, fmt.Errorf("error parsing user: %w", err)
+	}
+	if err := domainUser.SetPassword(password, u.passwordPolicyFor(ctx)); err != nil {
+		return 0, false, fmt.Errorf("error setting user password: %w", err)
+	}
+	createdUser, err := u.userRepo.CreateUser(ctx, domainUser)
+	if err != nil {
+		return 0, false, err
+	}
+	createdUser.SetVerifiedAt(time.Now())
+	if _, err := u.userRepo.VerifyUser(ctx, createdUser); err != nil {
+		return 0, false, fmt.Errorf("error verifying user: %w", err)
+	}
+	u.audit.Record(ctx, auditlog.Event{
+		ActorId:    createdUser.GetId().Value(),
+		Action:     "user.register",
+		TargetType: auditlog.TargetUser,
+		TargetId:   createdUser.GetId().Value(),
+		After: map[string]any{
+			"status":   createdUser.GetStatus().String(),
+			"verified": true,
+			"source":   "invite",
+		},
+		Redacted: []string{"name", "email"},
+	})
+	return createdUser.GetId().Value(), true, nil
+}
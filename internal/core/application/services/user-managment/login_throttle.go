@@ -0,0 +1,97 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	email_client "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// TooManyAttemptsError is returned when logins are throttled for the ip or the account.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// loginAttemptKeys returns the account key first, followed by the ip key when an ip is known
+func loginAttemptKeys(email user.Email, ip string) ([]user.LoginAttemptKey, error) {
+	accountKey, err := user.NewAccountLoginAttemptKey(email)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing login attempt key: %w", err)
+	}
+	keys := []user.LoginAttemptKey{accountKey}
+	if ipKey, err := user.NewIpLoginAttemptKey(ip); err == nil {
+		keys = append(keys, ipKey)
+	}
+	return keys, nil
+}
+
+// checkLoginAttempts rejects the login if any of the keys is backing off or locked
+func (u *UserManagementService) checkLoginAttempts(ctx context.Context, keys []user.LoginAttemptKey) error {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range keys {
+		attempt, err := u.loginAttempts.GetLoginAttempt(ctx, key)
+		if err != nil {
+			return fmt.Errorf("error retrieving login attempts: %w", err)
+		}
+		if retryAfter := attempt.RetryAfter(now, u.lockoutPolicy); retryAfter > wait {
+			wait = retryAfter
+		}
+	}
+	if wait > 0 {
+		return &TooManyAttemptsError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// recordFailedLogin counts a failure against every key, and lets the account owner know when the account gets locked.
+// domainUser is nil when the email does not belong to an account, the email is still tracked so both cases behave alike.
+func (u *UserManagementService) recordFailedLogin(ctx context.Context, keys []user.LoginAttemptKey, domainUser *user.User) {
+	now := time.Now()
+	for i, key := range keys {
+		attempt, locked, err := u.loginAttempts.RecordLoginFailure(ctx, key, now, u.lockoutPolicy)
+		if err != nil {
+			u.logger.WithContext(ctx).Error("error recording login attempt", err)
+			continue
+		}
+		// the first key is always the account
+		if locked && i == 0 && domainUser != nil {
+			u.sendLockoutNotification(ctx, domainUser, *attempt.LockedUntil())
+		}
+	}
+}
+
+// clearFailedLogins resets the account counter after a successful login, the ip counter is left to expire
+// so that one valid account cannot be used to reset the limit for an ip spraying other accounts.
+func (u *UserManagementService) clearFailedLogins(ctx context.Context, email user.Email) {
+	accountKey, err := user.NewAccountLoginAttemptKey(email)
+	if err != nil {
+		return
+	}
+	if err := u.loginAttempts.DeleteLoginAttempt(ctx, accountKey); err != nil {
+		u.logger.WithContext(ctx).Error("error clearing login attempts", err)
+	}
+}
+
+func (u *UserManagementService) sendLockoutNotification(ctx context.Context, domainUser *user.User, lockedUntil time.Time) {
+	go func() {
+		err := u.emailClient.Send(context.Background(), &email_client.Notification{
+			Email: domainUser.GetEmail().String(),
+			Title: "Account Temporarily Locked",
+			Content: fmt.Sprintf("We noticed several failed attempts to sign in to your account, so it has been locked until %s. If this wasn't you, we recommend resetting your password.",
+				lockedUntil.UTC().Format(time.RFC1123)),
+		})
+		if err != nil {
+			u.logger.WithContext(ctx).Error("error sending lockout notification", err)
+		}
+	}()
+}
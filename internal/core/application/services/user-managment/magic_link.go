@@ -0,0 +1,128 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	email_client "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
+)
+
+const magicLinkDuration = time.Minute * 15
+
+// magicLinkCodeLength is the length of the code confirming a magic link opened on another device
+const magicLinkCodeLength = 8
+
+var (
+	ErrMagicLinkConfirmationRequired = errors.New("magic link was opened on another device, enter the code shown where you requested it to sign in here")
+	ErrMagicLinkCodeMismatch         = errors.New("the confirmation code does not match, request a new sign in link")
+)
+
+// MagicLinkRequest is what the browser requesting a magic link keeps. The nonce signs in straight away from that browser,
+// the code is shown to the user so the link can be confirmed from another device.
+type MagicLinkRequest struct {
+	Nonce string
+	Code  string
+}
+
+// RequestMagicLink emails a single use sign in link and returns what the requesting browser has to keep.
+// A nonce and code are returned even when no account matches, so the response does not reveal which emails are registered.
+func (u *UserManagementService) RequestMagicLink(ctx context.Context, email, linkUrl string) (*MagicLinkRequest, error) {
+	parsedEmail, err := user.NewEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing email: %w", err)
+	}
+	req := &MagicLinkRequest{
+		Nonce: u.randomIdGenerator.Create("", 32),
+		Code:  strings.ToUpper(u.randomIdGenerator.Create("", magicLinkCodeLength)),
+	}
+
+	domainUser, err := u.userRepo.GetUserByEmail(ctx, parsedEmail)
+	if err != nil || domainUser.IsDeleted() {
+		return req, nil
+	}
+
+	tokenVal, err := user.NewTokenValue(u.randomIdGenerator.Create("magic_", 40))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token value: %w", err)
+	}
+	token, err := user.NewToken(tokenVal, user.MagicLink, domainUser.GetId())
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token: %w", err)
+	}
+	token.SetExpiresAt(time.Now().Add(magicLinkDuration))
+	token.BindToDevice(req.Nonce, req.Code)
+	if _, err := u.userRepo.CreateDeviceBoundToken(ctx, token); err != nil {
+		return nil, fmt.Errorf("error saving token: %w", err)
+	}
+
+	link := linkUrl + "?" + url.Values{
+		"uid":   {strconv.Itoa(domainUser.GetId().Value())},
+		"token": {tokenVal.String()},
+	}.Encode()
+	go func() {
+		err := u.emailClient.Send(context.Background(), &email_client.Notification{
+			Email:   domainUser.GetEmail().String(),
+			Title:   "Your Sign In Link",
+			Content: fmt.Sprintf("Use this link to sign in, it expires in %d minutes and can only be used once: %s", int(magicLinkDuration.Minutes()), link),
+		})
+		if err != nil {
+			u.logger.WithContext(ctx).Error("error sending magic link", err)
+		}
+	}()
+
+	return req, nil
+}
+
+// LoginWithMagicLink consumes the link. In the browser that requested it the nonce matches and the user is signed in straight away,
+// anywhere else the code that browser shows is needed, so a forwarded link or one prefetched by a mail scanner signs no one in.
+// A wrong code uses up the link so the code cannot be guessed.
+func (u *UserManagementService) LoginWithMagicLink(ctx context.Context, userId int, _token, nonce, code string, device DeviceInfo) (*LoginResponse, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	token, err := u.getTypedToken(ctx, parsedUserId, _token, user.MagicLink)
+	if err != nil {
+		return nil, err
+	}
+	sameDevice := token.IsBoundToDevice(nonce)
+	if !sameDevice && code == "" {
+		return nil, ErrMagicLinkConfirmationRequired
+	}
+	// single use, the token goes before the session is created
+	if err := u.userRepo.DeleteToken(ctx, token.Id()); err != nil {
+		return nil, fmt.Errorf("error deleting token: %w", err)
+	}
+	if !sameDevice && !token.MatchesConfirmationCode(code) {
+		return nil, ErrMagicLinkCodeMismatch
+	}
+
+	domainUser, err := u.userRepo.GetUserById(ctx, parsedUserId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if err := checkCanSignIn(domainUser); err != nil {
+		return nil, err
+	}
+	institutions, err := u.userInstitutions(ctx, domainUser.GetId())
+	if err != nil {
+		return nil, err
+	}
+	accessToken, refreshToken, err := u.startSession(ctx, domainUser, device)
+	if err != nil {
+		return nil, err
+	}
+	u.auditUserEvent(ctx, userId, "user.login.magic_link", userId, nil, nil)
+	return &LoginResponse{
+		User:         *mapToServiceUser(domainUser),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Institutions: institutions,
+	}, nil
+}
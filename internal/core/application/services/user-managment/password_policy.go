@@ -0,0 +1,18 @@
+package usermanagment
+
+import (
+	"context"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+)
+
+// passwordPolicyFor binds the breached password lookup to the request context
+func (u *UserManagementService) passwordPolicyFor(ctx context.Context) user.PasswordPolicy {
+	policy := u.passwordPolicy
+	if u.breachedPasswords != nil {
+		policy.BreachedRange = func(prefix string) ([]string, error) {
+			return u.breachedPasswords.Range(ctx, prefix)
+		}
+	}
+	return policy
+}
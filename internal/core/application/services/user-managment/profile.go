@@ -0,0 +1,230 @@
+package usermanagment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+	validation "github.com/kaasikodes/assessmate_backend/internal/shared"
+)
+
+var ErrAvatarUploadsDisabled = errors.New("avatar uploads are not available")
+
+type (
+	ProfilePreferences struct {
+		NotificationChannels []string
+		DefaultQuestionType  string
+		DefaultAiDifficulty  string
+	}
+	Profile struct {
+		DisplayName string
+		Bio         string
+		AvatarUrl   string
+		Timezone    string
+		Locale      string
+		Preferences ProfilePreferences
+		UpdatedAt   time.Time
+	}
+	// UpdateProfileRequest changes the fields that are set
+	UpdateProfileRequest struct {
+		DisplayName          *string
+		Bio                  *string
+		Timezone             *string
+		Locale               *string
+		NotificationChannels []string
+		DefaultQuestionType  *string
+		DefaultAiDifficulty  *string
+	}
+)
+
+// GetProfile returns the user's profile, the defaults if they never saved one
+func (u *UserManagementService) GetProfile(ctx context.Context, userId int) (*Profile, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	profile, err := u.getProfile(ctx, parsedUserId)
+	if err != nil {
+		return nil, err
+	}
+	return mapToServiceProfile(profile), nil
+}
+
+// UpdateProfile
+func (u *UserManagementService) UpdateProfile(ctx context.Context, userId int, req UpdateProfileRequest) (*Profile, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	profile, err := u.getProfile(ctx, parsedUserId)
+	if err != nil {
+		return nil, err
+	}
+	before, beforeName, beforeBio := profileAuditFields(profile), profile.DisplayName(), profile.Bio()
+	err = profile.Update(user.ProfileUpdate{
+		DisplayName:          req.DisplayName,
+		Bio:                  req.Bio,
+		Timezone:             req.Timezone,
+		Locale:               req.Locale,
+		NotificationChannels: req.NotificationChannels,
+		DefaultQuestionType:  req.DefaultQuestionType,
+		DefaultAiDifficulty:  req.DefaultAiDifficulty,
+	})
+	var profileErr *user.ProfileError
+	if errors.As(err, &profileErr) {
+		return nil, mapProfileError(profileErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := u.userRepo.SaveProfile(ctx, profile); err != nil {
+		return nil, fmt.Errorf("error saving profile: %w", err)
+	}
+	var redacted []string
+	if profile.DisplayName() != beforeName {
+		redacted = append(redacted, "displayName")
+	}
+	if profile.Bio() != beforeBio {
+		redacted = append(redacted, "bio")
+	}
+	u.audit.Record(ctx, auditlog.Event{
+		ActorId:    userId,
+		Action:     "user.profile.update",
+		TargetType: auditlog.TargetUser,
+		TargetId:   userId,
+		Before:     before,
+		After:      profileAuditFields(profile),
+		Redacted:   redacted,
+	})
+	return mapToServiceProfile(profile), nil
+}
+
+// UploadAvatar replaces the user's avatar, contentType is what the image was sniffed as
+func (u *UserManagementService) UploadAvatar(ctx context.Context, userId int, contentType string, image []byte) (*Profile, error) {
+	if u.files == nil {
+		return nil, ErrAvatarUploadsDisabled
+	}
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	ext, err := user.AvatarExtension(contentType, len(image))
+	if err != nil {
+		return nil, err
+	}
+	profile, err := u.getProfile(ctx, parsedUserId)
+	if err != nil {
+		return nil, err
+	}
+	// a new key each time so caches never serve the previous avatar
+	key := fmt.Sprintf("avatars/%d-%s%s", userId, u.randomIdGenerator.Create("", 12), ext)
+	url, err := u.files.Put(ctx, key, contentType, bytes.NewReader(image))
+	if err != nil {
+		return nil, fmt.Errorf("error storing avatar: %w", err)
+	}
+	previousKey := profile.AvatarKey()
+	profile.SetAvatar(key, url)
+	if err := u.userRepo.SaveProfile(ctx, profile); err != nil {
+		u.deleteFile(ctx, key)
+		return nil, fmt.Errorf("error saving profile: %w", err)
+	}
+	u.deleteFile(ctx, previousKey)
+	u.auditUserEvent(ctx, userId, "user.profile.avatar.update", userId, nil, nil)
+	return mapToServiceProfile(profile), nil
+}
+
+// RemoveAvatar
+func (u *UserManagementService) RemoveAvatar(ctx context.Context, userId int) (*Profile, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	profile, err := u.getProfile(ctx, parsedUserId)
+	if err != nil {
+		return nil, err
+	}
+	previousKey := profile.AvatarKey()
+	if previousKey == "" {
+		return mapToServiceProfile(profile), nil
+	}
+	profile.RemoveAvatar()
+	if err := u.userRepo.SaveProfile(ctx, profile); err != nil {
+		return nil, fmt.Errorf("error saving profile: %w", err)
+	}
+	u.deleteFile(ctx, previousKey)
+	u.auditUserEvent(ctx, userId, "user.profile.avatar.remove", userId, nil, nil)
+	return mapToServiceProfile(profile), nil
+}
+
+func (u *UserManagementService) getProfile(ctx context.Context, userId user.Id) (*user.Profile, error) {
+	profile, err := u.userRepo.GetProfile(ctx, userId)
+	if errors.Is(err, user_repo.ErrProfileNotFound) {
+		return user.NewProfile(userId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving profile: %w", err)
+	}
+	return profile, nil
+}
+
+// deleteFile removes a file that is no longer referenced, failing only leaves an orphaned file behind
+func (u *UserManagementService) deleteFile(ctx context.Context, key string) {
+	if key == "" || u.files == nil {
+		return
+	}
+	if err := u.files.Delete(ctx, key); err != nil {
+		u.logger.WithContext(ctx).Error("error deleting file", key, err)
+	}
+}
+
+func mapProfileError(err *user.ProfileError) error {
+	fields := make([]string, 0, len(err.Fields))
+	for field := range err.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	var valErrs validation.ValidationErrors
+	for _, field := range fields {
+		valErrs.Add(field, err.Fields[field])
+	}
+	return &valErrs
+}
+
+// profileAuditFields leaves out the display name and bio, they are personal data and only recorded as redacted
+func profileAuditFields(p *user.Profile) map[string]any {
+	preferences := mapToServiceProfile(p).Preferences
+	return map[string]any{
+		"timezone":             p.Timezone(),
+		"locale":               p.Locale(),
+		"notificationChannels": preferences.NotificationChannels,
+		"defaultQuestionType":  preferences.DefaultQuestionType,
+		"defaultAiDifficulty":  preferences.DefaultAiDifficulty,
+	}
+}
+
+func mapToServiceProfile(p *user.Profile) *Profile {
+	preferences := p.Preferences()
+	channels := make([]string, len(preferences.NotificationChannels))
+	for i, c := range preferences.NotificationChannels {
+		channels[i] = c.String()
+	}
+	return &Profile{
+		DisplayName: p.DisplayName(),
+		Bio:         p.Bio(),
+		AvatarUrl:   p.AvatarUrl(),
+		Timezone:    p.Timezone(),
+		Locale:      p.Locale(),
+		Preferences: ProfilePreferences{
+			NotificationChannels: channels,
+			DefaultQuestionType:  preferences.DefaultQuestionType.String(),
+			DefaultAiDifficulty:  preferences.DefaultAiDifficulty.String(),
+		},
+		UpdatedAt: p.UpdatedAt(),
+	}
+}
@@ -0,0 +1,251 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+)
+
+const refreshTokenDuration = time.Hour * 24 * 30
+
+var (
+	ErrSessionRevoked = errors.New("session has been revoked")
+	// ErrRefreshTokenReused means a rotated refresh token came back, someone else may hold a copy so the session is ended
+	ErrRefreshTokenReused = errors.New("refresh token has already been used, sign in again")
+)
+
+type (
+	// DeviceInfo describes where a request came from
+	DeviceInfo struct {
+		Ip        string
+		UserAgent string
+	}
+	Session struct {
+		Id         int
+		UserAgent  string
+		Ip         string
+		CreatedAt  time.Time
+		LastSeenAt time.Time
+		IsCurrent  bool
+	}
+)
+
+// sessionActivity buffers last seen times in memory so the auth middleware does not write on every request
+type sessionActivity struct {
+	mu       sync.Mutex
+	lastSeen map[user.Id]user.DateTime
+}
+
+func newSessionActivity() *sessionActivity {
+	return &sessionActivity{lastSeen: make(map[user.Id]user.DateTime)}
+}
+
+func (a *sessionActivity) record(sessionId user.Id, t time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastSeen[sessionId] = t
+}
+
+func (a *sessionActivity) drain() map[user.Id]user.DateTime {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	drained := a.lastSeen
+	a.lastSeen = make(map[user.Id]user.DateTime)
+	return drained
+}
+
+// startSession records a new session for the user and issues its access and refresh tokens
+func (u *UserManagementService) startSession(ctx context.Context, domainUser *user.User, device DeviceInfo) (accessToken, refreshToken string, err error) {
+	session, err := user.NewSession(domainUser.GetId(), device.UserAgent, device.Ip)
+	if err != nil {
+		return "", "", fmt.Errorf("error parsing session: %w", err)
+	}
+	session, err = u.userRepo.CreateSession(ctx, session)
+	if err != nil {
+		return "", "", fmt.Errorf("error saving session: %w", err)
+	}
+	return u.issueSessionTokens(ctx, domainUser, session.Id())
+}
+
+func (u *UserManagementService) issueSessionTokens(ctx context.Context, domainUser *user.User, sessionId user.Id) (accessToken, refreshToken string, err error) {
+	accessToken, err = u.createAccessToken(domainUser.GetId().String(), domainUser.GetEmail().String(), sessionId.String())
+	if err != nil {
+		return "", "", fmt.Errorf("error creating access token: %w", err)
+	}
+	tokenVal, err := user.NewTokenValue(u.randomIdGenerator.Create("refresh_", 40))
+	if err != nil {
+		return "", "", fmt.Errorf("error parsing token value: %w", err)
+	}
+	_, err = u.userRepo.CreateRefreshToken(ctx, tokenVal, domainUser.GetId(), sessionId, time.Now().Add(refreshTokenDuration))
+	if err != nil {
+		return "", "", fmt.Errorf("error saving refresh token: %w", err)
+	}
+	return accessToken, tokenVal.String(), nil
+}
+
+// RefreshSession exchanges a refresh token for a new access token, the refresh token is rotated within its session
+func (u *UserManagementService) RefreshSession(ctx context.Context, refreshToken string) (*LoginResponse, error) {
+	tokenVal, err := user.NewTokenValue(refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing token value: %w", err)
+	}
+	token, err := u.userRepo.GetRefreshToken(ctx, tokenVal)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving token: %w", errors.New("invalid token provided"))
+	}
+	if token.SessionId() == nil {
+		return nil, errors.New("invalid token provided")
+	}
+	// the token is single use whatever happens next, it is kept as used so a replay can be told apart from a typo
+	if err := u.userRepo.UseRefreshToken(ctx, token.Id()); err != nil {
+		if errors.Is(err, user_repo.ErrRefreshTokenUsed) {
+			u.revokeReusedSession(ctx, token)
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, fmt.Errorf("error using token: %w", err)
+	}
+	if token.HasExpired() {
+		return nil, errors.New("token has expired")
+	}
+	session, err := u.userRepo.GetSessionById(ctx, *token.SessionId())
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving session: %w", err)
+	}
+	if session.IsRevoked() {
+		return nil, ErrSessionRevoked
+	}
+	domainUser, err := u.userRepo.GetUserById(ctx, token.UserId())
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if err := checkCanSignIn(domainUser); err != nil {
+		return nil, err
+	}
+	accessToken, newRefreshToken, err := u.issueSessionTokens(ctx, domainUser, session.Id())
+	if err != nil {
+		return nil, err
+	}
+	u.activity.record(session.Id(), time.Now())
+
+	return &LoginResponse{
+		User:         *mapToServiceUser(domainUser),
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+	}, nil
+}
+
+// revokeReusedSession ends the session of a refresh token that was presented twice, either the owner or whoever
+// copied the token used it first, so neither is trusted with the session any longer
+func (u *UserManagementService) revokeReusedSession(ctx context.Context, token *user.Token) {
+	sessionId := *token.SessionId()
+	u.logger.WithContext(ctx).Warn("refresh token reused, revoking session", sessionId.Value())
+	if err := u.userRepo.RevokeSession(ctx, token.UserId(), sessionId); err != nil {
+		if !errors.Is(err, user_repo.ErrSessionNotFound) {
+			u.logger.WithContext(ctx).Error("error revoking session of reused refresh token", err)
+		}
+		return
+	}
+	u.audit.Record(ctx, auditlog.Event{ActorId: token.UserId().Value(), Action: "session.revoke_reused_token", TargetType: auditlog.TargetSession, TargetId: sessionId.Value()})
+}
+
+// ValidateSession ensures the session in an access token belongs to the user and is still active
+func (u *UserManagementService) ValidateSession(ctx context.Context, userId, sessionId int) error {
+	parsedSessionId, err := user.NewId(sessionId)
+	if err != nil {
+		return fmt.Errorf("error parsing sessionId: %w", err)
+	}
+	session, err := u.userRepo.GetSessionById(ctx, parsedSessionId)
+	if err != nil {
+		return fmt.Errorf("error retrieving session: %w", err)
+	}
+	if session.UserId().Value() != userId || session.IsRevoked() {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// ListSessions returns the active sessions of the user, flagging the one the request was made with
+func (u *UserManagementService) ListSessions(ctx context.Context, userId, currentSessionId int) ([]Session, error) {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing userId: %w", err)
+	}
+	data, err := u.userRepo.ListActiveSessions(ctx, parsedUserId)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving sessions: %w", err)
+	}
+	sessions := make([]Session, len(data))
+	for i, s := range data {
+		sessions[i] = Session{
+			Id:         s.Id().Value(),
+			UserAgent:  s.UserAgent(),
+			Ip:         s.Ip(),
+			CreatedAt:  s.CreatedAt(),
+			LastSeenAt: s.LastSeenAt(),
+			IsCurrent:  s.Id().Value() == currentSessionId,
+		}
+	}
+	return sessions, nil
+}
+
+// RevokeSession logs the user out of the session and revokes its refresh tokens
+func (u *UserManagementService) RevokeSession(ctx context.Context, userId, sessionId int) error {
+	parsedUserId, err := user.NewId(userId)
+	if err != nil {
+		return fmt.Errorf("error parsing userId: %w", err)
+	}
+	parsedSessionId, err := user.NewId(sessionId)
+	if err != nil {
+		return fmt.Errorf("error parsing sessionId: %w", err)
+	}
+	if err := u.userRepo.RevokeSession(ctx, parsedUserId, parsedSessionId); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, auditlog.Event{ActorId: userId, Action: "session.revoke", TargetType: auditlog.TargetSession, TargetId: sessionId})
+	return nil
+}
+
+// RecordSessionActivity notes that the session was just used, the write happens on the next flush
+func (u *UserManagementService) RecordSessionActivity(sessionId int) {
+	parsedSessionId, err := user.NewId(sessionId)
+	if err != nil {
+		return
+	}
+	u.activity.record(parsedSessionId, time.Now())
+}
+
+// FlushSessionActivity writes the buffered last seen times in a single batch
+func (u *UserManagementService) FlushSessionActivity(ctx context.Context) error {
+	lastSeen := u.activity.drain()
+	if len(lastSeen) == 0 {
+		return nil
+	}
+	return u.userRepo.TouchSessions(ctx, lastSeen)
+}
+
+// StartSessionActivityFlusher flushes session activity every interval until the context is done
+func (u *UserManagementService) StartSessionActivityFlusher(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				if err := u.FlushSessionActivity(context.Background()); err != nil {
+					u.logger.Error("error flushing session activity", err)
+				}
+				return
+			case <-ticker.C:
+				if err := u.FlushSessionActivity(ctx); err != nil {
+					u.logger.Error("error flushing session activity", err)
+				}
+			}
+		}
+	}()
+}
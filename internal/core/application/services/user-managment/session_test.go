@@ -0,0 +1,78 @@
+package usermanagment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/logger"
+	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
+)
+
+type discardLogger struct{}
+
+func (discardLogger) Info(v ...any)                                   {}
+func (discardLogger) Warn(v ...any)                                   {}
+func (discardLogger) Error(v ...any)                                  {}
+func (discardLogger) Fatal(v ...any)                                  {}
+func (l discardLogger) WithContext(ctx context.Context) logger.Logger { return l }
+
+// refreshTokenRepo holds a single refresh token of session 4, any method RefreshSession should not reach panics
+type refreshTokenRepo struct {
+	user_repo.UserRepository
+	token   *user.Token
+	used    bool
+	revoked []user.Id
+}
+
+func (r *refreshTokenRepo) GetRefreshToken(ctx context.Context, value user.TokenValue) (*user.Token, error) {
+	return r.token, nil
+}
+
+func (r *refreshTokenRepo) UseRefreshToken(ctx context.Context, id user.Id) error {
+	if r.used {
+		return user_repo.ErrRefreshTokenUsed
+	}
+	r.used = true
+	return nil
+}
+
+func (r *refreshTokenRepo) RevokeSession(ctx context.Context, userId, sessionId user.Id) error {
+	r.revoked = append(r.revoked, sessionId)
+	return nil
+}
+
+func (r *refreshTokenRepo) GetSessionById(ctx context.Context, sessionId user.Id) (*user.Session, error) {
+	return nil, errSessionLoaded
+}
+
+var errSessionLoaded = errors.New("session loaded")
+
+func TestRefreshSessionRevokesTheSessionWhenATokenIsReused(t *testing.T) {
+	token, err := user.NewToken(user.TokenValue("refresh_abc"), user.RefreshToken, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token.SetId(11)
+	token.SetSessionId(4)
+	token.SetExpiresAt(time.Now().Add(time.Hour))
+	repo := &refreshTokenRepo{token: token}
+	u := &UserManagementService{userRepo: repo, logger: discardLogger{}}
+
+	// the first use carries on to the session
+	if _, err := u.RefreshSession(context.Background(), "refresh_abc"); !errors.Is(err, errSessionLoaded) {
+		t.Fatalf("first use err = %v, want %v", err, errSessionLoaded)
+	}
+	if len(repo.revoked) != 0 {
+		t.Fatalf("first use revoked sessions %v", repo.revoked)
+	}
+
+	if _, err := u.RefreshSession(context.Background(), "refresh_abc"); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("second use err = %v, want %v", err, ErrRefreshTokenReused)
+	}
+	if len(repo.revoked) != 1 || repo.revoked[0] != 4 {
+		t.Errorf("revoked sessions = %v, want [4]", repo.revoked)
+	}
+}
@@ -6,14 +6,22 @@ import (
 	"fmt"
 	"time"
 
+	auditlog "github.com/kaasikodes/assessmate_backend/internal/core/application/services/audit-log"
 	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+	breachedpassword "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/breached-password"
 	email_client "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/email"
+	filestorage "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/file-storage"
+	institute_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/institution"
 	jwtport "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/jwt"
 	"github.com/kaasikodes/assessmate_backend/internal/ports/outbound/logger"
+	loginattempt "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/login-attempt"
 	randomidgenerator "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/random-id-generator"
 	user_repo "github.com/kaasikodes/assessmate_backend/internal/ports/outbound/user"
 )
 
+// accessTokenDuration is how long an access token lasts, the refresh token renews it
+const accessTokenDuration = time.Hour * 24 * 3
+
 type UserManagementService struct {
 	userRepo user_repo.UserRepository
 	jwt      jwtport.JwtMaker
@@ -22,11 +30,22 @@ type UserManagementService struct {
 	//email
 	emailClient       email_client.EmailClient
 	randomIdGenerator randomidgenerator.RandomIdGenerator
+	loginAttempts     loginattempt.LoginAttemptStore
+	lockoutPolicy     user.LockoutPolicy
+	activity          *sessionActivity
+	passwordPolicy    user.PasswordPolicy
+	breachedPasswords breachedpassword.BreachedPasswordStore // optional
+	audit             *auditlog.AuditLogService
+	files             filestorage.FileStorage // optional, avatars can't be uploaded without it
+	memberships       institute_repo.MembershipReader
+	domainJoins       institute_repo.EmailDomainJoiner // optional, nobody joins by email domain without it
+	personalData      institute_repo.PersonalDataReader
 }
 type (
 	LoginResponse struct {
 		User         User
 		AccessToken  string
+		RefreshToken string
 		Institutions []Institution
 	}
 	User struct {
@@ -37,27 +56,40 @@ type (
 		IsVerified bool
 		CreatedAt  time.Time
 		UpdatedAt  time.Time
+		DeletedAt  *time.Time // set while the account is scheduled for deletion
 	}
 	Institution struct {
 		Id          int
 		Name        string
 		Description string
-		IsUserAdmin bool // is the user an admin
-		IsActive    bool //represents whether user has access to institution could be blackisted or subscription has expired
+		StaffId     int
+		Role        string
+		Status      string // the user's staff status in the institution
+		IsUserAdmin bool   // is the user an admin
+		IsActive    bool   //represents whether user has access to institution could be blackisted or subscription has expired
 	}
+	// ForgotPasswordResponse never carries the token, it only ever goes to the user's inbox
 	ForgotPasswordResponse struct {
-		Token string
 		Email string
 	}
 	UserFilter struct {
-		Status *string
+		Status        *string
+		IsVerified    *bool
+		Search        string // prefix of the name or email
+		CreatedAfter  *time.Time
+		CreatedBefore *time.Time
+		Page          int // starts at 1
+		PageSize      int
 	}
 	GetUsersResponse struct {
-		Users []User
-		Total int
+		Users    []User
+		Total    int
+		Page     int
+		PageSize int
 	}
 	AuthUserResponse struct {
 		User         User
+		Profile      Profile
 		Institutions []Institution
 	}
 	TokenResponse struct {
@@ -68,13 +100,23 @@ type (
 )
 
 // Constructor
-func NewUserManagementService(repo user_repo.UserRepository, jwt jwtport.JwtMaker, emailClient email_client.EmailClient, logger logger.Logger, randomIdGenerator randomidgenerator.RandomIdGenerator) *UserManagementService {
+func NewUserManagementService(repo user_repo.UserRepository, jwt jwtport.JwtMaker, emailClient email_client.EmailClient, logger logger.Logger, randomIdGenerator randomidgenerator.RandomIdGenerator, loginAttempts loginattempt.LoginAttemptStore, passwordPolicy user.PasswordPolicy, breachedPasswords breachedpassword.BreachedPasswordStore, audit *auditlog.AuditLogService, files filestorage.FileStorage, memberships institute_repo.MembershipReader, domainJoins institute_repo.EmailDomainJoiner, personalData institute_repo.PersonalDataReader) *UserManagementService {
 	return &UserManagementService{
 		userRepo:          repo,
 		jwt:               jwt,
 		emailClient:       emailClient,
 		logger:            logger,
 		randomIdGenerator: randomIdGenerator,
+		loginAttempts:     loginAttempts,
+		lockoutPolicy:     user.DefaultLockoutPolicy,
+		activity:          newSessionActivity(),
+		passwordPolicy:    passwordPolicy,
+		breachedPasswords: breachedPasswords,
+		audit:             audit,
+		files:             files,
+		memberships:       memberships,
+		domainJoins:       domainJoins,
+		personalData:      personalData,
 	}
 }
 
@@ -105,40 +147,57 @@ func (u *UserManagementService) FindUserById(ctx context.Context, id int) (*User
 	return mapToServiceUser(domainUser), nil
 }
 
-func (u *UserManagementService) createAccessToken(userId, userEmail string) (string, error) {
-	tokenDuration := time.Hour * 24 * 3 //3 days
-	token, err := u.jwt.CreateToken(userId, userEmail, tokenDuration)
+func (u *UserManagementService) createAccessToken(userId, userEmail, sessionId string) (string, error) {
+	token, err := u.jwt.CreateToken(userId, userEmail, sessionId, accessTokenDuration)
 	if err != nil {
 		return "", err
 	}
 	return token, nil
 }
 
-// login
-func (u *UserManagementService) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
+// login, the device ip is used to throttle failed attempts and both ip and user agent are recorded on the session
+func (u *UserManagementService) Login(ctx context.Context, email, password string, device DeviceInfo) (*LoginResponse, error) {
 	parsedEmail, err := user.NewEmail(email)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing email: %w", err)
 	}
+	keys, err := loginAttemptKeys(parsedEmail, device.Ip)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkLoginAttempts(ctx, keys); err != nil {
+		return nil, err
+	}
 
 	domainUser, err := u.userRepo.GetUserByEmail(ctx, parsedEmail)
 	if err != nil {
-		return nil, err
+		u.recordFailedLogin(ctx, keys, nil)
+		return nil, ErrInvalidCredentials
 	}
 	passwordsMatch := domainUser.ComparePassword(password)
 	if !passwordsMatch {
-		return nil, errors.New("invalid credentials")
+		u.recordFailedLogin(ctx, keys, domainUser)
+		u.auditUserEvent(ctx, 0, "user.login_failed", domainUser.GetId().Value(), nil, nil)
+		return nil, ErrInvalidCredentials
+	}
+	if err := checkCanSignIn(domainUser); err != nil {
+		return nil, err
 	}
-	token, err := u.createAccessToken(domainUser.GetId().String(), domainUser.GetEmail().String())
+	u.clearFailedLogins(ctx, parsedEmail)
+	institutions, err := u.userInstitutions(ctx, domainUser.GetId())
 	if err != nil {
-		return nil, fmt.Errorf("error creating access token: %w", err)
+		return nil, err
 	}
+	token, refreshToken, err := u.startSession(ctx, domainUser, device)
+	if err != nil {
+		return nil, err
+	}
+	u.auditUserEvent(ctx, domainUser.GetId().Value(), "user.login", domainUser.GetId().Value(), nil, nil)
 	return &LoginResponse{
 		User:         *mapToServiceUser(domainUser),
 		AccessToken:  token,
-		Institutions: []Institution{
-			// TODO: Get Instititutions user belongs to if any and populate here, might have to be a bounded context or something or take in institute_repo and update
-		},
+		RefreshToken: refreshToken,
+		Institutions: institutions,
 	}, nil
 }
 
@@ -161,12 +220,12 @@ func (u *UserManagementService) CreateAndSendVerificationTokenForExistingUser(ct
 		return nil, errors.New("user is already verified")
 	}
 
-	verifyToken, err := user.NewToken(tokenVal, user.ResetPassword, existingUser.GetId())
+	verifyToken, err := user.NewToken(tokenVal, user.Verification, existingUser.GetId())
 	if err != nil {
 		return nil, fmt.Errorf("error parsing token: %w", err)
 	}
 	verifyToken.SetExpiresAt(time.Now().Add(time.Hour * 24 * 5))
-	verifyToken, err = u.userRepo.CreateToken(ctx, verifyToken.Value(), user.ResetPassword, existingUser.GetId(), *verifyToken.ExpiresAt())
+	verifyToken, err = u.userRepo.CreateToken(ctx, verifyToken.Value(), user.Verification, existingUser.GetId(), *verifyToken.ExpiresAt())
 	if err != nil {
 		return nil, fmt.Errorf("error saving token: %w", err)
 	}
@@ -201,13 +260,12 @@ func (u *UserManagementService) Register(ctx context.Context, email, name, passw
 	if err != nil {
 		return nil, fmt.Errorf("error parsing user: %w", err)
 	}
-	err = domainUser.SetPassword(password)
+	err = domainUser.SetPassword(password, u.passwordPolicyFor(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("error setting user password: %w", err)
 	}
 
 	existingUser, _ := u.userRepo.GetUserByEmail(ctx, parsedEmail)
-	u.logger.Info(existingUser, "EXISTING ...")
 	if existingUser != nil && existingUser.GetEmail() == parsedEmail {
 		return nil, errors.New("email has been taken")
 
@@ -217,13 +275,21 @@ func (u *UserManagementService) Register(ctx context.Context, email, name, passw
 	if err != nil {
 		return nil, err
 	}
+	u.audit.Record(ctx, auditlog.Event{
+		ActorId:    createdUser.GetId().Value(),
+		Action:     "user.register",
+		TargetType: auditlog.TargetUser,
+		TargetId:   createdUser.GetId().Value(),
+		After:      map[string]any{"status": createdUser.GetStatus().String()},
+		Redacted:   []string{"name", "email"},
+	})
+	u.joinByEmailDomain(ctx, createdUser)
 	// Create verification token and send to user via mail
 	tokenVal, err := user.NewTokenValue(u.randomIdGenerator.Create("verify_", 20))
 	if err != nil {
 		return nil, fmt.Errorf("error parsing token value: %w", err)
 	}
 
-	u.logger.Info(createdUser.GetId(), "___CREATED")
 	verifyToken, err := user.NewToken(tokenVal, user.Verification, createdUser.GetId())
 	if err != nil {
 		return nil, fmt.Errorf("error parsing token: %w", err)
@@ -303,11 +369,7 @@ func (u *UserManagementService) DeleteToken(ctx context.Context, id int) error {
 }
 
 // verifyAccount
-func (u *UserManagementService) VerifyUser(ctx context.Context, email, _token string) (*LoginResponse, error) {
-	tokenVal, err := user.NewTokenValue(_token)
-	if err != nil {
-		return nil, fmt.Errorf("error constructing token value: %w", err)
-	}
+func (u *UserManagementService) VerifyUser(ctx context.Context, email, _token string, device DeviceInfo) (*LoginResponse, error) {
 	parsedEmail, err := user.NewEmail(email)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing email: %w", err)
@@ -316,13 +378,10 @@ func (u *UserManagementService) VerifyUser(ctx context.Context, email, _token st
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving user: %w", err)
 	}
-	token, err := u.userRepo.GetToken(ctx, domainUser.GetId(), tokenVal)
+	// only a verification token proves the email, not any other token the user holds
+	token, err := u.getTypedToken(ctx, domainUser.GetId(), _token, user.Verification)
 	if err != nil {
-		// TODO: Come up with app standard error messages, and ensure they dont show db errors only human frienndly
-		return nil, fmt.Errorf("error retrieving token: %w", errors.New("invalid token provided"))
-	}
-	if token.HasExpired() {
-		return nil, errors.New("token has expired")
+		return nil, err
 	}
 
 	domainUser.SetVerifiedAt(time.Now())
@@ -331,21 +390,28 @@ func (u *UserManagementService) VerifyUser(ctx context.Context, email, _token st
 	if err != nil {
 		return nil, fmt.Errorf("error verifying user: %w", err)
 	}
+	u.auditUserEvent(ctx, domainUser.GetId().Value(), "user.verify", domainUser.GetId().Value(), map[string]any{"verified": false}, map[string]any{"verified": true})
+	u.joinByEmailDomain(ctx, domainUser)
 	// delete the token
-	u.logger.Info(token, "TOKENNN")
 	err = u.userRepo.DeleteToken(ctx, token.Id())
 	if err != nil {
 		return nil, fmt.Errorf("error deleting token: %w", err)
 	}
 
-	accessToken, err := u.createAccessToken(domainUser.GetId().String(), domainUser.GetEmail().String())
+	institutions, err := u.userInstitutions(ctx, domainUser.GetId())
 	if err != nil {
-		return nil, fmt.Errorf("error creating access token: %w", err)
+		return nil, err
+	}
+	accessToken, refreshToken, err := u.startSession(ctx, domainUser, device)
+	if err != nil {
+		return nil, err
 	}
 
 	return &LoginResponse{
-		User:        *mapToServiceUser(domainUser),
-		AccessToken: accessToken,
+		User:         *mapToServiceUser(domainUser),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Institutions: institutions,
 	}, nil
 }
 
@@ -383,27 +449,23 @@ func (u *UserManagementService) ForgotPassword(ctx context.Context, email string
 	}()
 
 	return &ForgotPasswordResponse{
-		Token: resetToken.Value().String(),
 		Email: domainUser.GetEmail().String(),
 	}, nil
 }
 
 // resetPassword
 func (u *UserManagementService) ResetPassword(ctx context.Context, _token, email, newPassword string, userId int) error {
-	tokenVal, err := user.NewTokenValue(_token)
-	if err != nil {
-		return fmt.Errorf("error constructing token value: %w", err)
+	if auditlog.IsImpersonated(ctx) {
+		return auditlog.ErrImpersonated
 	}
 	parsedUserId, err := user.NewId(userId)
 	if err != nil {
 		return fmt.Errorf("error parsing userId: %w", err)
 	}
-	token, err := u.userRepo.GetToken(ctx, parsedUserId, tokenVal)
+	// the user id comes from the client, so the token must be one sent to reset the password of that user
+	token, err := u.getTypedToken(ctx, parsedUserId, _token, user.ResetPassword)
 	if err != nil {
-		return fmt.Errorf("error retrieving token: %w", err)
-	}
-	if token.HasExpired() {
-		return errors.New("token has expired")
+		return err
 	}
 
 	domainUser, err := u.userRepo.GetUserById(ctx, parsedUserId)
@@ -411,7 +473,9 @@ func (u *UserManagementService) ResetPassword(ctx context.Context, _token, email
 		return fmt.Errorf("error retrieving user: %w", err)
 	}
 
-	domainUser.SetPassword(newPassword)
+	if err := domainUser.SetPassword(newPassword, u.passwordPolicyFor(ctx)); err != nil {
+		return fmt.Errorf("error setting user password: %w", err)
+	}
 
 	_, err = u.userRepo.UpdateUserPassword(ctx, domainUser)
 	if err != nil {
@@ -423,14 +487,26 @@ func (u *UserManagementService) ResetPassword(ctx context.Context, _token, email
 	if err != nil {
 		return fmt.Errorf("error deleting token: %w", err)
 	}
+	u.auditUserEvent(ctx, userId, "user.password_reset", userId, nil, nil)
 
 	return nil
 }
 
 // getUsers
 func (u *UserManagementService) GetUsers(ctx context.Context, filter *UserFilter) (*GetUsersResponse, error) {
-	var parsedFilter user.UserFilter
-	if filter != nil && filter.Status != nil {
+	if filter == nil {
+		filter = &UserFilter{}
+	}
+	page, pageSize := normalisePage(filter.Page, filter.PageSize)
+	parsedFilter := user.UserFilter{
+		IsVerified:    filter.IsVerified,
+		Search:        filter.Search,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+		Limit:         pageSize,
+		Offset:        (page - 1) * pageSize,
+	}
+	if filter.Status != nil {
 		status, err := user.NewUserStatus(*filter.Status)
 		if err != nil {
 			return nil, fmt.Errorf("error parsing user status: %w", err)
@@ -452,15 +528,25 @@ func (u *UserManagementService) GetUsers(ctx context.Context, filter *UserFilter
 	}
 
 	return &GetUsersResponse{
-		Users: users,
-		Total: total,
+		Users:    users,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
 	}, nil
 
 }
 
 // logout
 func (u *UserManagementService) Logout(ctx context.Context, email string) error {
-	//TODO: create an audit repo that will record the logout event
+	parsedEmail, err := user.NewEmail(email)
+	if err != nil {
+		return fmt.Errorf("error parsing email: %w", err)
+	}
+	domainUser, err := u.userRepo.GetUserByEmail(ctx, parsedEmail)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+	u.auditUserEvent(ctx, domainUser.GetId().Value(), "user.logout", domainUser.GetId().Value(), nil, nil)
 
 	return nil
 }
@@ -475,9 +561,19 @@ func (u *UserManagementService) GetAuthUser(ctx context.Context, email string) (
 	if err != nil {
 		return nil, err
 	}
+	profile, err := u.getProfile(ctx, domainUser.GetId())
+	if err != nil {
+		return nil, err
+	}
+	institutions, err := u.userInstitutions(ctx, domainUser.GetId())
+	if err != nil {
+		return nil, err
+	}
 
 	return &AuthUserResponse{
-		User: *mapToServiceUser(domainUser),
+		User:         *mapToServiceUser(domainUser),
+		Profile:      *mapToServiceProfile(profile),
+		Institutions: institutions,
 	}, nil
 }
 
@@ -491,5 +587,6 @@ func mapToServiceUser(domainUser *user.User) *User {
 		CreatedAt:  domainUser.GetCreatedAt(),
 		UpdatedAt:  domainUser.GetUpdatedAt(),
 		IsVerified: domainUser.IsVerified(),
+		DeletedAt:  domainUser.GetDeletedAt(),
 	}
 }
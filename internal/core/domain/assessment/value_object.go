@@ -42,3 +42,36 @@ var (
 	FillInTheBlank        QuestionType = "fill-in-the-blank"
 	MatchQuestionToOption QuestionType = "match-questions-to-options"
 )
+
+func NewQuestionType(val string) (QuestionType, error) {
+	switch t := QuestionType(val); t {
+	case Essay, TrueFalse, MultiAnswer, OneAnswer, FillInTheBlank, MatchQuestionToOption:
+		return t, nil
+	}
+	return "", fmt.Errorf("invalid question type: %s", val)
+}
+
+func (t QuestionType) String() string {
+	return string(t)
+}
+
+// Difficulty is how hard the generated questions should be
+type Difficulty string
+
+var (
+	Easy   Difficulty = "easy"
+	Medium Difficulty = "medium"
+	Hard   Difficulty = "hard"
+)
+
+func NewDifficulty(val string) (Difficulty, error) {
+	switch d := Difficulty(val); d {
+	case Easy, Medium, Hard:
+		return d, nil
+	}
+	return "", fmt.Errorf("invalid difficulty: %s", val)
+}
+
+func (d Difficulty) String() string {
+	return string(d)
+}
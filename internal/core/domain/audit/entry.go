@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Entry is one record in the append-only audit log. Once appended it is never updated or deleted.
+type Entry struct {
+	id             int
+	actorId        int // 0 when the system acted, e.g. a scheduled purge
+	impersonatorId int // the admin acting as the actor, 0 when the actor acted themselves
+	action         string
+	targetType     string
+	targetId       string
+	institutionId  *int
+	ip             string
+	userAgent      string
+	requestId      string
+	changes        map[string]Change
+	createdAt      time.Time
+}
+
+// Change is the value of a field before and after the action, nil when the field did not exist.
+// Personal data is redacted, the log is append-only and outlives the account, so only the fact the field changed is kept.
+type Change struct {
+	Before   any  `json:"before"`
+	After    any  `json:"after"`
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+type EntryParams struct {
+	ActorId        int
+	ImpersonatorId int
+	Action         string
+	TargetType     string
+	TargetId       string
+	InstitutionId  *int
+	Ip             string
+	UserAgent      string
+	RequestId      string
+	Changes        map[string]Change
+}
+
+// NewEntry validates the params, an entry must at least say what was done to what
+func NewEntry(params EntryParams) (*Entry, error) {
+	action := strings.TrimSpace(params.Action)
+	if action == "" {
+		return nil, errors.New("audit action cannot be empty")
+	}
+	targetType := strings.TrimSpace(params.TargetType)
+	if targetType == "" {
+		return nil, errors.New("audit target type cannot be empty")
+	}
+	if params.ActorId < 0 || params.ImpersonatorId < 0 {
+		return nil, errors.New("audit actor id cannot be negative")
+	}
+	return &Entry{
+		actorId:        params.ActorId,
+		impersonatorId: params.ImpersonatorId,
+		action:         action,
+		targetType:     targetType,
+		targetId:       params.TargetId,
+		institutionId:  params.InstitutionId,
+		ip:             params.Ip,
+		userAgent:      params.UserAgent,
+		requestId:      params.RequestId,
+		changes:        params.Changes,
+		createdAt:      time.Now().UTC(),
+	}, nil
+}
+
+// Diff returns the fields whose values differ between before and after, either may be nil
+func Diff(before, after map[string]any) map[string]Change {
+	changes := map[string]Change{}
+	for field, old := range before {
+		if val, ok := after[field]; !ok || !reflect.DeepEqual(old, val) {
+			changes[field] = Change{Before: old, After: val}
+		}
+	}
+	for field, val := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = Change{After: val}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// Redact records the fields as changed without their values, replacing any value the changes hold for them
+func Redact(changes map[string]Change, fields ...string) map[string]Change {
+	if len(fields) == 0 {
+		return changes
+	}
+	if changes == nil {
+		changes = map[string]Change{}
+	}
+	for _, field := range fields {
+		changes[field] = Change{Redacted: true}
+	}
+	return changes
+}
+
+// Filter narrows a query of the log, zero values match everything
+type Filter struct {
+	ActorId        *int
+	ImpersonatorId *int
+	Action         string
+	TargetType     string
+	TargetId       string
+	InstitutionId  *int
+	From           *time.Time
+	To             *time.Time
+	Limit          int
+	Offset         int
+}
+
+// Setters used when loading an entry from storage
+func (e *Entry) SetId(id int) {
+	e.id = id
+}
+
+func (e *Entry) SetCreatedAt(t time.Time) {
+	e.createdAt = t
+}
+
+// Getters
+func (e *Entry) Id() int {
+	return e.id
+}
+
+func (e *Entry) ActorId() int {
+	return e.actorId
+}
+
+func (e *Entry) ImpersonatorId() int {
+	return e.impersonatorId
+}
+
+func (e *Entry) Action() string {
+	return e.action
+}
+
+func (e *Entry) TargetType() string {
+	return e.targetType
+}
+
+func (e *Entry) TargetId() string {
+	return e.targetId
+}
+
+func (e *Entry) InstitutionId() *int {
+	return e.institutionId
+}
+
+func (e *Entry) Ip() string {
+	return e.ip
+}
+
+func (e *Entry) UserAgent() string {
+	return e.userAgent
+}
+
+func (e *Entry) RequestId() string {
+	return e.requestId
+}
+
+func (e *Entry) Changes() map[string]Change {
+	return e.changes
+}
+
+func (e *Entry) CreatedAt() time.Time {
+	return e.createdAt
+}
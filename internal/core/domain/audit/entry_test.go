@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedactKeepsNoValues(t *testing.T) {
+	changes := Redact(Diff(
+		map[string]any{"email": "old@example.com", "role": "member"},
+		map[string]any{"email": "new@example.com", "role": "admin"},
+	), "email", "name")
+
+	if got := changes["role"]; got.Before != "member" || got.After != "admin" || got.Redacted {
+		t.Errorf("role = %+v, want it recorded as is", got)
+	}
+	for _, field := range []string{"email", "name"} {
+		if got := changes[field]; !got.Redacted || got.Before != nil || got.After != nil {
+			t.Errorf("%s = %+v, want it redacted", field, got)
+		}
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "example.com") {
+		t.Errorf("redacted changes still hold the email: %s", data)
+	}
+}
+
+func TestRedactWithoutFieldsLeavesChangesAlone(t *testing.T) {
+	if changes := Redact(nil); changes != nil {
+		t.Errorf("Redact(nil) = %v, want nil", changes)
+	}
+}
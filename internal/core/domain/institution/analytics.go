@@ -0,0 +1,9 @@
+package institution
+
+// StaffActivity is the number of staff in each status, removed staff are left out
+type StaffActivity struct {
+	Active      int
+	Inactive    int
+	Suspended   int
+	Blacklisted int
+}
@@ -0,0 +1,213 @@
+package institution
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxCategoryDepth is how many levels deep categories can be nested
+const MaxCategoryDepth = 6
+
+// category kind
+type CategoryKind string
+
+var (
+	FacultyKind    CategoryKind = "faculty"    // top level, holds departments
+	DepartmentKind CategoryKind = "department" // holds courses and sub departments
+)
+
+func NewCategoryKind(val string) (CategoryKind, error) {
+	switch k := CategoryKind(val); k {
+	case FacultyKind, DepartmentKind:
+		return k, nil
+	}
+	return "", errors.New("the category kind is not recognized")
+}
+func (k CategoryKind) String() string {
+	return string(k)
+}
+
+// Category is a node of the institution's faculty → department tree, courses and staff are placed in it.
+type Category struct {
+	id        Id
+	parentId  *Id // nil for a top level category
+	kind      CategoryKind
+	name      Name
+	createdAt DateTime
+	updatedAt DateTime
+}
+
+func NewCategory(name Name, kind CategoryKind, parentId *Id) (*Category, error) {
+	if name.IsEmpty() {
+		return nil, errors.New("category name cannot be empty")
+	}
+	if _, err := NewCategoryKind(kind.String()); err != nil {
+		return nil, err
+	}
+	now := DateTime(time.Now().UTC())
+	return &Category{
+		parentId:  parentId,
+		kind:      kind,
+		name:      name,
+		createdAt: now,
+		updatedAt: now,
+	}, nil
+}
+
+// Rename changes the category name.
+func (c *Category) Rename(name Name) error {
+	if name.IsEmpty() {
+		return errors.New("category name cannot be empty")
+	}
+	c.name = name
+	c.updatedAt = DateTime(time.Now().UTC())
+	return nil
+}
+
+// MoveTo places the category under another one, or at the top level when parentId is nil.
+// CategoryTree.CanPlace tells whether the move keeps the tree valid.
+func (c *Category) MoveTo(parentId *Id) {
+	c.parentId = parentId
+	c.updatedAt = DateTime(time.Now().UTC())
+}
+
+// SetId assigns a persisted Id once retrieved from storage.
+func (c *Category) SetId(id Id) {
+	c.id = id
+}
+
+// SetTimestamps sets createdAt and updatedAt when loaded from storage.
+func (c *Category) SetTimestamps(createdAt, updatedAt time.Time) {
+	c.createdAt = DateTime(createdAt)
+	c.updatedAt = DateTime(updatedAt)
+}
+
+// ----------- Getters -----------
+
+func (c *Category) Id() Id {
+	return c.id
+}
+
+func (c *Category) ParentId() *Id {
+	return c.parentId
+}
+
+func (c *Category) Kind() CategoryKind {
+	return c.kind
+}
+
+func (c *Category) Name() Name {
+	return c.name
+}
+
+func (c *Category) CreatedAt() DateTime {
+	return c.createdAt
+}
+
+func (c *Category) UpdatedAt() DateTime {
+	return c.updatedAt
+}
+
+// CategoryTree answers questions about the categories of one institution.
+type CategoryTree struct {
+	byId     map[Id]*Category
+	children map[Id][]Id // the top level categories are the children of 0
+}
+
+func NewCategoryTree(categories []Category) *CategoryTree {
+	t := &CategoryTree{
+		byId:     make(map[Id]*Category, len(categories)),
+		children: make(map[Id][]Id),
+	}
+	for i := range categories {
+		c := &categories[i]
+		t.byId[c.id] = c
+		var parent Id
+		if c.parentId != nil {
+			parent = *c.parentId
+		}
+		t.children[parent] = append(t.children[parent], c.id)
+	}
+	return t
+}
+
+// Get returns the category, nil if it is not in the tree.
+func (t *CategoryTree) Get(id Id) *Category {
+	return t.byId[id]
+}
+
+// Children returns the categories directly under the category, pass 0 for the top level.
+func (t *CategoryTree) Children(id Id) []Id {
+	return t.children[id]
+}
+
+// Subtree returns the category and every category under it.
+func (t *CategoryTree) Subtree(id Id) []Id {
+	if _, ok := t.byId[id]; !ok {
+		return nil
+	}
+	ids := []Id{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, t.children[ids[i]]...)
+	}
+	return ids
+}
+
+// HasSibling reports whether another category under the parent already has the name.
+func (t *CategoryTree) HasSibling(parentId *Id, name Name, except Id) bool {
+	var parent Id
+	if parentId != nil {
+		parent = *parentId
+	}
+	for _, id := range t.children[parent] {
+		if id != except && t.byId[id].name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CanPlace tells whether a category of the kind, with the given subtree height, can go under the parent.
+// A faculty stays at the top level, nothing goes under itself and the tree stays within MaxCategoryDepth.
+func (t *CategoryTree) CanPlace(kind CategoryKind, parentId *Id, moving Id, height int) error {
+	if parentId == nil {
+		if height > MaxCategoryDepth {
+			return errors.New("categories cannot be nested this deep")
+		}
+		return nil
+	}
+	if kind == FacultyKind {
+		return errors.New("a faculty can only be at the top level")
+	}
+	parent, ok := t.byId[*parentId]
+	if !ok {
+		return errors.New("parent category not found")
+	}
+	// the levels above the new position, the category itself sits one below them
+	levels := 0
+	for p := parent; p != nil; {
+		if moving != 0 && p.id == moving {
+			return errors.New("a category cannot be moved under itself")
+		}
+		levels++
+		if p.parentId == nil {
+			break
+		}
+		p = t.byId[*p.parentId]
+	}
+	if levels+height > MaxCategoryDepth {
+		return errors.New("categories cannot be nested this deep")
+	}
+	return nil
+}
+
+// Height is the number of levels from the category down to its deepest descendant, 1 for a leaf.
+func (t *CategoryTree) Height(id Id) int {
+	height := 0
+	for _, child := range t.children[id] {
+		if h := t.Height(child); h > height {
+			height = h
+		}
+	}
+	return height + 1
+}
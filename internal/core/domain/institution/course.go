@@ -9,6 +9,7 @@ type Course struct {
 	id          Id
 	name        Name
 	description Description
+	categoryId  *Id // the department the course is in, nil when it is not placed in one
 	createdAt   DateTime
 	updatedAt   DateTime
 }
@@ -38,6 +39,12 @@ func (c *Course) SetId(id Id) {
 	c.id = id
 }
 
+// SetTimestamps sets createdAt and updatedAt when loaded from storage.
+func (c *Course) SetTimestamps(createdAt, updatedAt time.Time) {
+	c.createdAt = DateTime(createdAt)
+	c.updatedAt = DateTime(updatedAt)
+}
+
 // UpdateName changes the course name and updates the updatedAt timestamp.
 func (c *Course) UpdateName(newName Name) error {
 	if newName.IsEmpty() {
@@ -58,6 +65,12 @@ func (c *Course) UpdateDescription(newDescription Description) error {
 	return nil
 }
 
+// PlaceIn puts the course in a category, nil takes it out of any.
+func (c *Course) PlaceIn(categoryId *Id) {
+	c.categoryId = categoryId
+	c.touch()
+}
+
 // Internal helper to update updatedAt field.
 func (c *Course) touch() {
 	c.updatedAt = DateTime(time.Now())
@@ -77,6 +90,10 @@ func (c *Course) Description() Description {
 	return c.description
 }
 
+func (c *Course) CategoryId() *Id {
+	return c.categoryId
+}
+
 func (c *Course) CreatedAt() DateTime {
 	return c.createdAt
 }
@@ -0,0 +1,344 @@
+package institution
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// EmailDomainConfirmDuration is how long the link sent to the admin mailbox of a domain can be used for
+const EmailDomainConfirmDuration = 7 * 24 * time.Hour
+
+// DomainTxtRecordPrefix is the name the TXT record proving a domain is published under, before the domain itself
+const DomainTxtRecordPrefix = "_assessmate-verification."
+
+// DomainName is an email domain an institution claims, e.g. school.edu
+type DomainName string
+
+var domainLabelRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// publicEmailDomains are shared by everyone, claiming one would let an institution take in strangers
+var publicEmailDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true, "yahoo.com": true, "outlook.com": true, "hotmail.com": true, "live.com": true,
+	"icloud.com": true, "aol.com": true, "proton.me": true, "protonmail.com": true, "gmx.com": true, "mail.com": true, "yandex.com": true,
+}
+
+func NewDomainName(val string) (DomainName, error) {
+	val = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(val)), ".")
+	if val == "" {
+		return "", errors.New("domain cannot be empty")
+	}
+	if len(val) > 253 {
+		return "", errors.New("domain must not exceed 253 characters")
+	}
+	labels := strings.Split(val, ".")
+	if len(labels) < 2 {
+		return "", errors.New("domain must have at least two parts, e.g. school.edu")
+	}
+	for _, label := range labels {
+		if !domainLabelRegex.MatchString(label) {
+			return "", errors.New("invalid domain format")
+		}
+	}
+	if publicEmailDomains[val] {
+		return "", errors.New("public email domains cannot be claimed")
+	}
+	return DomainName(val), nil
+}
+
+// DomainOf is the domain of the email, empty for a malformed email
+func DomainOf(email Email) DomainName {
+	_, domain, ok := strings.Cut(email.String(), "@")
+	if !ok {
+		return ""
+	}
+	return DomainName(strings.ToLower(domain))
+}
+
+func (d DomainName) String() string {
+	return string(d)
+}
+
+// domain verification method
+type DomainVerificationMethod string
+
+var (
+	VerifyByDns     DomainVerificationMethod = "dns"     // a TXT record holding the token under DomainTxtRecordPrefix
+	VerifyByMailbox DomainVerificationMethod = "mailbox" // a link sent to an admin mailbox of the domain
+)
+
+func NewDomainVerificationMethod(val string) (DomainVerificationMethod, error) {
+	switch m := DomainVerificationMethod(val); m {
+	case VerifyByDns, VerifyByMailbox:
+		return m, nil
+	}
+	return "", errors.New("the verification method is not recognized")
+}
+func (m DomainVerificationMethod) String() string {
+	return string(m)
+}
+
+// adminMailboxes are the mailboxes only whoever runs a domain is expected to read
+var adminMailboxes = map[string]bool{"admin": true, "administrator": true, "hostmaster": true, "postmaster": true, "webmaster": true}
+
+// domain join policy
+type DomainJoinPolicy string
+
+var (
+	JoinAsPending DomainJoinPolicy = "pending" // joins as inactive staff until an admin activates them
+	JoinAsActive  DomainJoinPolicy = "active"  // joins as active staff once their email is verified
+)
+
+func NewDomainJoinPolicy(val string) (DomainJoinPolicy, error) {
+	switch p := DomainJoinPolicy(val); p {
+	case JoinAsPending, JoinAsActive:
+		return p, nil
+	}
+	return "", errors.New("the join policy is not recognized")
+}
+func (p DomainJoinPolicy) String() string {
+	return string(p)
+}
+
+// domain status
+type EmailDomainStatus string
+
+var (
+	DomainPending  EmailDomainStatus = "pending"
+	DomainVerified EmailDomainStatus = "verified"
+)
+
+func (s EmailDomainStatus) String() string {
+	return string(s)
+}
+
+// EmailDomain is a domain an institution claims, once verified anyone who signs up with an email on it joins the institution.
+type EmailDomain struct {
+	id            Id
+	institutionId Id
+	domain        DomainName
+	method        DomainVerificationMethod
+	mailbox       string // the local part the confirmation link is sent to, only for VerifyByMailbox
+	token         string // the TXT record value is kept to show again, a mailbox token is only known right after the claim and stored hashed
+	policy        DomainJoinPolicy
+	status        EmailDomainStatus
+	expiresAt     *DateTime // when the mailbox link stops working
+	verifiedAt    *DateTime
+	createdAt     DateTime
+	updatedAt     DateTime
+}
+
+// NewEmailDomain creates a pending claim, SetToken gives it the token to publish or send.
+func NewEmailDomain(institutionId Id, domain DomainName, method DomainVerificationMethod, mailbox string, policy DomainJoinPolicy) (*EmailDomain, error) {
+	if domain == "" {
+		return nil, errors.New("domain cannot be empty")
+	}
+	mailbox = strings.ToLower(strings.TrimSpace(mailbox))
+	now := DateTime(time.Now().UTC())
+	d := &EmailDomain{
+		institutionId: institutionId,
+		domain:        domain,
+		method:        method,
+		policy:        policy,
+		status:        DomainPending,
+		createdAt:     now,
+		updatedAt:     now,
+	}
+	switch method {
+	case VerifyByMailbox:
+		if !adminMailboxes[mailbox] {
+			return nil, errors.New("the mailbox must be one of admin, administrator, hostmaster, postmaster or webmaster")
+		}
+		d.mailbox = mailbox
+		expiresAt := now.Add(EmailDomainConfirmDuration)
+		d.expiresAt = &expiresAt
+	case VerifyByDns:
+		if mailbox != "" {
+			return nil, errors.New("a mailbox is only used to verify by mailbox")
+		}
+	default:
+		return nil, errors.New("the verification method is not recognized")
+	}
+	return d, nil
+}
+
+// SetToken sets the TXT record value or the token sent to the mailbox.
+func (d *EmailDomain) SetToken(token string) error {
+	if strings.TrimSpace(token) == "" {
+		return errors.New("domain token cannot be empty")
+	}
+	d.token = token
+	return nil
+}
+
+// VerifyTxtRecords verifies a DNS claim when one of the TXT records of TxtRecordName is the token.
+func (d *EmailDomain) VerifyTxtRecords(records []string, t time.Time) error {
+	if d.method != VerifyByDns {
+		return errors.New("the domain is verified through its admin mailbox")
+	}
+	for _, record := range records {
+		if d.token != "" && strings.TrimSpace(record) == d.token {
+			return d.verify(t)
+		}
+	}
+	return errors.New("the TXT record with the verification token was not found")
+}
+
+// ConfirmMailbox verifies a mailbox claim, the link must not have expired.
+func (d *EmailDomain) ConfirmMailbox(t time.Time) error {
+	if d.method != VerifyByMailbox {
+		return errors.New("the domain is verified with a DNS record")
+	}
+	if d.expiresAt != nil && !t.Before(*d.expiresAt) {
+		return errors.New("the confirmation link has expired")
+	}
+	return d.verify(t)
+}
+
+func (d *EmailDomain) verify(t time.Time) error {
+	if d.status == DomainVerified {
+		return errors.New("the domain is already verified")
+	}
+	verified := DateTime(t)
+	d.status = DomainVerified
+	d.verifiedAt = &verified
+	d.expiresAt = nil
+	d.updatedAt = verified
+	return nil
+}
+
+// ChangePolicy changes how people who sign up with the domain join.
+func (d *EmailDomain) ChangePolicy(policy DomainJoinPolicy) {
+	d.policy = policy
+	d.updatedAt = DateTime(time.Now().UTC())
+}
+
+// NewStaff is the staff record of someone who signed up with an email on the verified domain.
+// Until their email is verified they are added inactive without their account, JoinStaff links it.
+func (d *EmailDomain) NewStaff(name Name, email Email) (*Staff, error) {
+	if err := d.ensureJoinable(email); err != nil {
+		return nil, err
+	}
+	return NewStaff(name, email, InActive)
+}
+
+// JoinStaff links the verified account to its staff record, activating it when the policy lets them in straight away.
+// Only a staff still waiting to join is changed.
+func (d *EmailDomain) JoinStaff(staff *Staff, userId Id) error {
+	if err := d.ensureJoinable(staff.Email()); err != nil {
+		return err
+	}
+	if staff.IsDeleted() || staff.Status() != InActive {
+		return errors.New("the staff is not waiting to join")
+	}
+	if staff.UserId() != nil && *staff.UserId() != userId {
+		return errors.New("the staff is linked to another account")
+	}
+	staff.LinkUser(userId)
+	if d.policy == JoinAsActive {
+		staff.SetStatus(Active)
+	}
+	return nil
+}
+
+func (d *EmailDomain) ensureJoinable(email Email) error {
+	if d.status != DomainVerified {
+		return errors.New("the domain is not verified")
+	}
+	if DomainOf(email) != d.domain {
+		return errors.New("the email is not on the domain")
+	}
+	return nil
+}
+
+// TxtRecordName is where the TXT record proving a DNS claim is published.
+func (d *EmailDomain) TxtRecordName() string {
+	return DomainTxtRecordPrefix + d.domain.String()
+}
+
+// MailboxAddress is where the confirmation link of a mailbox claim is sent.
+func (d *EmailDomain) MailboxAddress() Email {
+	if d.mailbox == "" {
+		return ""
+	}
+	return Email(d.mailbox + "@" + d.domain.String())
+}
+
+// Setters used when loaded from storage
+
+func (d *EmailDomain) SetId(id Id) {
+	d.id = id
+}
+
+func (d *EmailDomain) SetStatus(status EmailDomainStatus) {
+	d.status = status
+}
+
+func (d *EmailDomain) SetExpiresAt(t *time.Time) {
+	d.expiresAt = t
+}
+
+func (d *EmailDomain) SetVerifiedAt(t time.Time) {
+	verified := DateTime(t)
+	d.verifiedAt = &verified
+}
+
+func (d *EmailDomain) SetTimestamps(createdAt, updatedAt time.Time) {
+	d.createdAt = DateTime(createdAt)
+	d.updatedAt = DateTime(updatedAt)
+}
+
+// ----------- Getters -----------
+
+func (d *EmailDomain) Id() Id {
+	return d.id
+}
+
+func (d *EmailDomain) InstitutionId() Id {
+	return d.institutionId
+}
+
+func (d *EmailDomain) Domain() DomainName {
+	return d.domain
+}
+
+func (d *EmailDomain) Method() DomainVerificationMethod {
+	return d.method
+}
+
+func (d *EmailDomain) Mailbox() string {
+	return d.mailbox
+}
+
+func (d *EmailDomain) Token() string {
+	return d.token
+}
+
+func (d *EmailDomain) Policy() DomainJoinPolicy {
+	return d.policy
+}
+
+func (d *EmailDomain) Status() EmailDomainStatus {
+	return d.status
+}
+
+func (d *EmailDomain) IsVerified() bool {
+	return d.status == DomainVerified
+}
+
+func (d *EmailDomain) ExpiresAt() *DateTime {
+	return d.expiresAt
+}
+
+func (d *EmailDomain) VerifiedAt() *DateTime {
+	return d.verifiedAt
+}
+
+func (d *EmailDomain) CreatedAt() DateTime {
+	return d.createdAt
+}
+
+func (d *EmailDomain) UpdatedAt() DateTime {
+	return d.updatedAt
+}
@@ -53,6 +53,18 @@ func (g *Group) AddCourse(c Course) {
 	g.touch()
 }
 
+// SetMembers sets the staff and courses of the group when loaded from storage.
+func (g *Group) SetMembers(staff []Staff, courses []Course) {
+	g.staff = staff
+	g.accessibleCourses = courses
+}
+
+// SetTimestamps sets createdAt and updatedAt when loaded from storage.
+func (g *Group) SetTimestamps(createdAt, updatedAt time.Time) {
+	g.createdAt = DateTime(createdAt)
+	g.updatedAt = DateTime(updatedAt)
+}
+
 // UpdateName changes the group's name.
 func (g *Group) UpdateName(newName Name) error {
 	if newName.IsEmpty() {
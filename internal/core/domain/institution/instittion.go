@@ -86,6 +86,32 @@ func (i *Institution) UpdateName(newName Name) error {
 	return nil
 }
 
+// UpdateDescription updates the institution description and updatedAt timestamp.
+func (i *Institution) UpdateDescription(newDescription Description) error {
+	if newDescription.IsEmpty() {
+		return errors.New("description cannot be empty")
+	}
+	i.description = newDescription
+	i.touch()
+	return nil
+}
+
+// UpdateEmail updates the institution contact email and updatedAt timestamp.
+func (i *Institution) UpdateEmail(newEmail Email) error {
+	if newEmail.IsEmpty() {
+		return errors.New("email cannot be empty")
+	}
+	i.email = newEmail
+	i.touch()
+	return nil
+}
+
+// SetTimestamps sets createdAt and updatedAt when loaded from persistence.
+func (i *Institution) SetTimestamps(createdAt, updatedAt time.Time) {
+	i.createdAt = DateTime(createdAt)
+	i.updatedAt = DateTime(updatedAt)
+}
+
 // touch updates the updatedAt timestamp.
 func (i *Institution) touch() {
 	i.updatedAt = DateTime(time.Now().UTC())
@@ -0,0 +1,218 @@
+package institution
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// InviteDuration is how long an invite can be accepted for after it is sent
+const InviteDuration = 7 * 24 * time.Hour
+
+// invite status
+type InviteStatus string
+
+var (
+	InvitePending  InviteStatus = "pending"
+	InviteAccepted InviteStatus = "accepted"
+	InviteRevoked  InviteStatus = "revoked"
+	InviteExpired  InviteStatus = "expired" // never stored, a pending invite past its expiry
+)
+
+func NewInviteStatus(val string) (InviteStatus, error) {
+	switch s := InviteStatus(val); s {
+	case InvitePending, InviteAccepted, InviteRevoked, InviteExpired:
+		return s, nil
+	}
+	return "", errors.New("the invite status is not recognized")
+}
+func (s InviteStatus) String() string {
+	return string(s)
+}
+
+// Invite asks someone by email to join an institution as the staff it was created for.
+type Invite struct {
+	id            Id
+	institutionId Id
+	staffId       Id
+	name          Name
+	email         Email
+	token         string // only known right after the invite is created or renewed, it is stored hashed
+	status        InviteStatus
+	invitedBy     Id
+	expiresAt     DateTime
+	acceptedAt    *DateTime
+	revokedAt     *DateTime
+	createdAt     DateTime
+	updatedAt     DateTime
+}
+
+// NewInvite creates a pending invite that expires after InviteDuration, SetToken gives it the token to send.
+func NewInvite(institutionId, staffId Id, name Name, email Email, invitedBy Id) (*Invite, error) {
+	if email.IsEmpty() {
+		return nil, errors.New("invite email cannot be empty")
+	}
+	now := DateTime(time.Now().UTC())
+	return &Invite{
+		institutionId: institutionId,
+		staffId:       staffId,
+		name:          name,
+		email:         email,
+		status:        InvitePending,
+		invitedBy:     invitedBy,
+		expiresAt:     now.Add(InviteDuration),
+		createdAt:     now,
+		updatedAt:     now,
+	}, nil
+}
+
+// Accept marks the invite as used, it must still be pending.
+func (i *Invite) Accept(t time.Time) error {
+	if err := i.ensurePending(t); err != nil {
+		return err
+	}
+	accepted := DateTime(t)
+	i.acceptedAt = &accepted
+	i.status = InviteAccepted
+	i.updatedAt = accepted
+	return nil
+}
+
+// Revoke stops the invite from being accepted, only a pending invite can be revoked.
+func (i *Invite) Revoke(t time.Time) error {
+	if err := i.ensurePending(t); err != nil {
+		return err
+	}
+	revoked := DateTime(t)
+	i.revokedAt = &revoked
+	i.status = InviteRevoked
+	i.updatedAt = revoked
+	return nil
+}
+
+// Renew replaces the token and restarts the expiry, used to resend a pending or expired invite.
+func (i *Invite) Renew(token string) error {
+	if i.status != InvitePending {
+		return errors.New("only pending or expired invites can be resent")
+	}
+	if err := i.SetToken(token); err != nil {
+		return err
+	}
+	now := DateTime(time.Now().UTC())
+	i.expiresAt = now.Add(InviteDuration)
+	i.updatedAt = now
+	return nil
+}
+
+func (i *Invite) ensurePending(t time.Time) error {
+	switch i.StatusAt(t) {
+	case InviteAccepted:
+		return errors.New("invite has already been accepted")
+	case InviteRevoked:
+		return errors.New("invite has been revoked")
+	case InviteExpired:
+		return errors.New("invite has expired")
+	}
+	return nil
+}
+
+// SetToken sets the token sent to the invitee.
+func (i *Invite) SetToken(token string) error {
+	if strings.TrimSpace(token) == "" {
+		return errors.New("invite token cannot be empty")
+	}
+	i.token = token
+	return nil
+}
+
+// StatusAt is the status of the invite at the given time, a pending invite past its expiry has expired.
+func (i *Invite) StatusAt(t time.Time) InviteStatus {
+	if i.status == InvitePending && !t.Before(i.expiresAt) {
+		return InviteExpired
+	}
+	return i.status
+}
+
+// Setters used when loaded from storage
+
+func (i *Invite) SetId(id Id) {
+	i.id = id
+}
+
+func (i *Invite) SetStatus(status InviteStatus) {
+	i.status = status
+}
+
+func (i *Invite) SetExpiresAt(t time.Time) {
+	i.expiresAt = DateTime(t)
+}
+
+func (i *Invite) SetAcceptedAt(t time.Time) {
+	accepted := DateTime(t)
+	i.acceptedAt = &accepted
+}
+
+func (i *Invite) SetRevokedAt(t time.Time) {
+	revoked := DateTime(t)
+	i.revokedAt = &revoked
+}
+
+func (i *Invite) SetTimestamps(createdAt, updatedAt time.Time) {
+	i.createdAt = DateTime(createdAt)
+	i.updatedAt = DateTime(updatedAt)
+}
+
+// ----------- Getters -----------
+
+func (i *Invite) Id() Id {
+	return i.id
+}
+
+func (i *Invite) InstitutionId() Id {
+	return i.institutionId
+}
+
+func (i *Invite) StaffId() Id {
+	return i.staffId
+}
+
+func (i *Invite) Name() Name {
+	return i.name
+}
+
+func (i *Invite) Email() Email {
+	return i.email
+}
+
+func (i *Invite) Token() string {
+	return i.token
+}
+
+// Status is the stored status, StatusAt tells whether a pending invite has expired.
+func (i *Invite) Status() InviteStatus {
+	return i.status
+}
+
+func (i *Invite) InvitedBy() Id {
+	return i.invitedBy
+}
+
+func (i *Invite) ExpiresAt() DateTime {
+	return i.expiresAt
+}
+
+func (i *Invite) AcceptedAt() *DateTime {
+	return i.acceptedAt
+}
+
+func (i *Invite) RevokedAt() *DateTime {
+	return i.revokedAt
+}
+
+func (i *Invite) CreatedAt() DateTime {
+	return i.createdAt
+}
+
+func (i *Invite) UpdatedAt() DateTime {
+	return i.updatedAt
+}
@@ -0,0 +1,26 @@
+package institution
+
+// Membership is a read model of a user's place in one institution, used to list the institutions a user belongs to.
+type Membership struct {
+	InstitutionId          Id
+	InstitutionName        Name
+	InstitutionDescription Description
+	StaffId                Id
+	Role                   StaffRole
+	Status                 StaffStatus
+}
+
+// HasAccess reports whether the user can currently act within the institution.
+func (m Membership) HasAccess() bool {
+	return m.Status == Active
+}
+
+func (m Membership) IsAdmin() bool {
+	return m.Role.IsAdmin()
+}
+
+// StaffRecord is a staff together with the institution holding it, read across institutions when exporting a person's data.
+type StaffRecord struct {
+	InstitutionId Id
+	Staff         Staff
+}
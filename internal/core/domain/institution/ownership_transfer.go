@@ -0,0 +1,247 @@
+package institution
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// OwnershipTransferDuration is how long both parties have to confirm an ownership transfer
+const OwnershipTransferDuration = 72 * time.Hour
+
+// ownership transfer status
+type OwnershipTransferStatus string
+
+var (
+	TransferPending   OwnershipTransferStatus = "pending"
+	TransferCompleted OwnershipTransferStatus = "completed"
+	TransferCancelled OwnershipTransferStatus = "cancelled"
+	TransferExpired   OwnershipTransferStatus = "expired" // never stored, a pending transfer past its expiry
+)
+
+func (s OwnershipTransferStatus) String() string {
+	return string(s)
+}
+
+// OwnershipTransfer hands the institution from its owner to another staff.
+// Both confirm by email, the owner then becomes an admin and the new owner takes over billing.
+type OwnershipTransfer struct {
+	id              Id
+	institutionId   Id
+	fromStaffId     Id
+	toStaffId       Id
+	fromToken       string // the tokens are only known right after the transfer is created, they are stored hashed
+	toToken         string
+	status          OwnershipTransferStatus
+	fromConfirmedAt *DateTime
+	toConfirmedAt   *DateTime
+	expiresAt       DateTime
+	completedAt     *DateTime
+	cancelledAt     *DateTime
+	createdAt       DateTime
+	updatedAt       DateTime
+}
+
+// NewOwnershipTransfer starts a transfer from the owner to a staff who has access to the institution and an account.
+func NewOwnershipTransfer(institutionId Id, from, to *Staff) (*OwnershipTransfer, error) {
+	if !from.IsOwner() {
+		return nil, errors.New("only the owner can transfer ownership")
+	}
+	if from.Id() == to.Id() {
+		return nil, errors.New("ownership cannot be transferred to the current owner")
+	}
+	if to.UserId() == nil || !to.HasAccess() {
+		return nil, errors.New("ownership can only be transferred to an active staff")
+	}
+	now := DateTime(time.Now().UTC())
+	return &OwnershipTransfer{
+		institutionId: institutionId,
+		fromStaffId:   from.Id(),
+		toStaffId:     to.Id(),
+		status:        TransferPending,
+		expiresAt:     now.Add(OwnershipTransferDuration),
+		createdAt:     now,
+		updatedAt:     now,
+	}, nil
+}
+
+// LoadOwnershipTransfer rebuilds a transfer loaded from storage, the setters fill in the rest.
+func LoadOwnershipTransfer(institutionId, fromStaffId, toStaffId Id) *OwnershipTransfer {
+	return &OwnershipTransfer{institutionId: institutionId, fromStaffId: fromStaffId, toStaffId: toStaffId}
+}
+
+// SetTokens sets the tokens sent to the owner and to the new owner.
+func (o *OwnershipTransfer) SetTokens(fromToken, toToken string) error {
+	if strings.TrimSpace(fromToken) == "" || strings.TrimSpace(toToken) == "" {
+		return errors.New("ownership transfer tokens cannot be empty")
+	}
+	if fromToken == toToken {
+		return errors.New("each party needs their own ownership transfer token")
+	}
+	o.fromToken = fromToken
+	o.toToken = toToken
+	return nil
+}
+
+// Confirm records the confirmation of the staff, who must be one of the parties.
+func (o *OwnershipTransfer) Confirm(staffId Id, t time.Time) error {
+	if err := o.ensurePending(t); err != nil {
+		return err
+	}
+	confirmed := DateTime(t)
+	switch staffId {
+	case o.fromStaffId:
+		o.fromConfirmedAt = &confirmed
+	case o.toStaffId:
+		o.toConfirmedAt = &confirmed
+	default:
+		return errors.New("only the parties can confirm an ownership transfer")
+	}
+	o.updatedAt = confirmed
+	return nil
+}
+
+// IsConfirmed reports whether both parties have confirmed.
+func (o *OwnershipTransfer) IsConfirmed() bool {
+	return o.fromConfirmedAt != nil && o.toConfirmedAt != nil
+}
+
+// Complete finishes a transfer both parties have confirmed.
+func (o *OwnershipTransfer) Complete(t time.Time) error {
+	if err := o.ensurePending(t); err != nil {
+		return err
+	}
+	if !o.IsConfirmed() {
+		return errors.New("both parties must confirm the ownership transfer")
+	}
+	completed := DateTime(t)
+	o.completedAt = &completed
+	o.status = TransferCompleted
+	o.updatedAt = completed
+	return nil
+}
+
+// Cancel stops a pending transfer.
+func (o *OwnershipTransfer) Cancel(t time.Time) error {
+	if err := o.ensurePending(t); err != nil {
+		return err
+	}
+	cancelled := DateTime(t)
+	o.cancelledAt = &cancelled
+	o.status = TransferCancelled
+	o.updatedAt = cancelled
+	return nil
+}
+
+func (o *OwnershipTransfer) ensurePending(t time.Time) error {
+	switch o.StatusAt(t) {
+	case TransferCompleted:
+		return errors.New("the ownership transfer has already been completed")
+	case TransferCancelled:
+		return errors.New("the ownership transfer has been cancelled")
+	case TransferExpired:
+		return errors.New("the ownership transfer has expired")
+	}
+	return nil
+}
+
+// StatusAt is the status of the transfer at the given time, a pending transfer past its expiry has expired.
+func (o *OwnershipTransfer) StatusAt(t time.Time) OwnershipTransferStatus {
+	if o.status == TransferPending && !t.Before(o.expiresAt) {
+		return TransferExpired
+	}
+	return o.status
+}
+
+// Setters used when loaded from storage
+
+func (o *OwnershipTransfer) SetId(id Id) {
+	o.id = id
+}
+
+func (o *OwnershipTransfer) SetStatus(status OwnershipTransferStatus) {
+	o.status = status
+}
+
+func (o *OwnershipTransfer) SetExpiresAt(t time.Time) {
+	o.expiresAt = DateTime(t)
+}
+
+func (o *OwnershipTransfer) SetConfirmedAt(from, to *time.Time) {
+	o.fromConfirmedAt = from
+	o.toConfirmedAt = to
+}
+
+func (o *OwnershipTransfer) SetCompletedAt(t time.Time) {
+	completed := DateTime(t)
+	o.completedAt = &completed
+}
+
+func (o *OwnershipTransfer) SetCancelledAt(t time.Time) {
+	cancelled := DateTime(t)
+	o.cancelledAt = &cancelled
+}
+
+func (o *OwnershipTransfer) SetTimestamps(createdAt, updatedAt time.Time) {
+	o.createdAt = DateTime(createdAt)
+	o.updatedAt = DateTime(updatedAt)
+}
+
+// ----------- Getters -----------
+
+func (o *OwnershipTransfer) Id() Id {
+	return o.id
+}
+
+func (o *OwnershipTransfer) InstitutionId() Id {
+	return o.institutionId
+}
+
+func (o *OwnershipTransfer) FromStaffId() Id {
+	return o.fromStaffId
+}
+
+func (o *OwnershipTransfer) ToStaffId() Id {
+	return o.toStaffId
+}
+
+func (o *OwnershipTransfer) FromToken() string {
+	return o.fromToken
+}
+
+func (o *OwnershipTransfer) ToToken() string {
+	return o.toToken
+}
+
+// Status is the stored status, StatusAt tells whether a pending transfer has expired.
+func (o *OwnershipTransfer) Status() OwnershipTransferStatus {
+	return o.status
+}
+
+func (o *OwnershipTransfer) FromConfirmedAt() *DateTime {
+	return o.fromConfirmedAt
+}
+
+func (o *OwnershipTransfer) ToConfirmedAt() *DateTime {
+	return o.toConfirmedAt
+}
+
+func (o *OwnershipTransfer) ExpiresAt() DateTime {
+	return o.expiresAt
+}
+
+func (o *OwnershipTransfer) CompletedAt() *DateTime {
+	return o.completedAt
+}
+
+func (o *OwnershipTransfer) CancelledAt() *DateTime {
+	return o.cancelledAt
+}
+
+func (o *OwnershipTransfer) CreatedAt() DateTime {
+	return o.createdAt
+}
+
+func (o *OwnershipTransfer) UpdatedAt() DateTime {
+	return o.updatedAt
+}
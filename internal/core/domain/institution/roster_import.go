@@ -0,0 +1,216 @@
+package institution
+
+import (
+	"errors"
+	"time"
+)
+
+// roster import status
+type RosterImportStatus string
+
+var (
+	ImportQueued     RosterImportStatus = "queued"
+	ImportProcessing RosterImportStatus = "processing"
+	ImportCompleted  RosterImportStatus = "completed"
+	ImportFailed     RosterImportStatus = "failed" // stopped part way, the rows recorded so far were applied
+)
+
+func NewRosterImportStatus(val string) (RosterImportStatus, error) {
+	switch s := RosterImportStatus(val); s {
+	case ImportQueued, ImportProcessing, ImportCompleted, ImportFailed:
+		return s, nil
+	}
+	return "", errors.New("the roster import status is not recognized")
+}
+func (s RosterImportStatus) String() string {
+	return string(s)
+}
+
+// what an import did, or in a preview would do, with a row
+type RosterAction string
+
+var (
+	RowCreated   RosterAction = "created"
+	RowUpdated   RosterAction = "updated"
+	RowUnchanged RosterAction = "unchanged"
+	RowFailed    RosterAction = "failed"
+)
+
+func (a RosterAction) String() string {
+	return string(a)
+}
+
+// RosterRowResult is the outcome of one row of a roster file, Line is the line in the file
+type RosterRowResult struct {
+	Line    int
+	Email   string
+	Action  RosterAction
+	Invited bool   // an invite was queued for the new staff
+	Error   string // why the row failed
+}
+
+// RosterImport applies a roster file to the staff of an institution, row by row.
+type RosterImport struct {
+	id            Id
+	institutionId Id
+	createdBy     Id
+	fileName      string
+	status        RosterImportStatus
+	totalRows     int
+	results       []RosterRowResult
+	failure       string
+	createdAt     DateTime
+	updatedAt     DateTime
+	finishedAt    *DateTime
+}
+
+func NewRosterImport(institutionId, createdBy Id, fileName string, totalRows int) (*RosterImport, error) {
+	if totalRows < 1 {
+		return nil, errors.New("roster has no rows to import")
+	}
+	now := DateTime(time.Now().UTC())
+	return &RosterImport{
+		institutionId: institutionId,
+		createdBy:     createdBy,
+		fileName:      fileName,
+		status:        ImportQueued,
+		totalRows:     totalRows,
+		results:       []RosterRowResult{},
+		createdAt:     now,
+		updatedAt:     now,
+	}, nil
+}
+
+// Start marks a queued import as being processed.
+func (ri *RosterImport) Start(t time.Time) error {
+	if ri.status != ImportQueued {
+		return errors.New("only a queued import can be started")
+	}
+	ri.status = ImportProcessing
+	ri.updatedAt = DateTime(t)
+	return nil
+}
+
+// Record adds the outcome of the next row.
+func (ri *RosterImport) Record(result RosterRowResult) {
+	ri.results = append(ri.results, result)
+}
+
+// Complete marks the import as done once every row has been recorded.
+func (ri *RosterImport) Complete(t time.Time) {
+	ri.finish(ImportCompleted, t)
+}
+
+// Fail stops the import, the rows recorded so far stay applied.
+func (ri *RosterImport) Fail(reason string, t time.Time) {
+	ri.failure = reason
+	ri.finish(ImportFailed, t)
+}
+
+func (ri *RosterImport) finish(status RosterImportStatus, t time.Time) {
+	finished := DateTime(t)
+	ri.status = status
+	ri.finishedAt = &finished
+	ri.updatedAt = finished
+}
+
+// Count is the number of recorded rows with the action.
+func (ri *RosterImport) Count(action RosterAction) int {
+	count := 0
+	for _, result := range ri.results {
+		if result.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Invited is the number of invites queued by the import.
+func (ri *RosterImport) Invited() int {
+	count := 0
+	for _, result := range ri.results {
+		if result.Invited {
+			count++
+		}
+	}
+	return count
+}
+
+// IsFinished reports whether the import has stopped, successfully or not.
+func (ri *RosterImport) IsFinished() bool {
+	return ri.status == ImportCompleted || ri.status == ImportFailed
+}
+
+// Setters used when loaded from storage
+
+func (ri *RosterImport) SetId(id Id) {
+	ri.id = id
+}
+
+func (ri *RosterImport) SetStatus(status RosterImportStatus) {
+	ri.status = status
+}
+
+func (ri *RosterImport) SetResults(results []RosterRowResult) {
+	ri.results = results
+}
+
+func (ri *RosterImport) SetFailure(reason string) {
+	ri.failure = reason
+}
+
+func (ri *RosterImport) SetFinishedAt(t time.Time) {
+	finished := DateTime(t)
+	ri.finishedAt = &finished
+}
+
+func (ri *RosterImport) SetTimestamps(createdAt, updatedAt time.Time) {
+	ri.createdAt = DateTime(createdAt)
+	ri.updatedAt = DateTime(updatedAt)
+}
+
+// ----------- Getters -----------
+
+func (ri *RosterImport) Id() Id {
+	return ri.id
+}
+
+func (ri *RosterImport) InstitutionId() Id {
+	return ri.institutionId
+}
+
+func (ri *RosterImport) CreatedBy() Id {
+	return ri.createdBy
+}
+
+func (ri *RosterImport) FileName() string {
+	return ri.fileName
+}
+
+func (ri *RosterImport) Status() RosterImportStatus {
+	return ri.status
+}
+
+func (ri *RosterImport) TotalRows() int {
+	return ri.totalRows
+}
+
+func (ri *RosterImport) Results() []RosterRowResult {
+	return ri.results
+}
+
+func (ri *RosterImport) Failure() string {
+	return ri.failure
+}
+
+func (ri *RosterImport) CreatedAt() DateTime {
+	return ri.createdAt
+}
+
+func (ri *RosterImport) UpdatedAt() DateTime {
+	return ri.updatedAt
+}
+
+func (ri *RosterImport) FinishedAt() *DateTime {
+	return ri.finishedAt
+}
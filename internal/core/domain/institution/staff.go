@@ -2,18 +2,26 @@ package institution
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
+// StaffRetention is how long a removed staff can still be restored.
+const StaffRetention = 30 * 24 * time.Hour
+
 // Staff represents a staff member within an institution.
 type Staff struct {
-	id        Id
-	name      Name
-	email     Email
-	status    StaffStatus
-	deletedAt *DateTime
-	createdAt DateTime
-	updatedAt DateTime
+	id              Id
+	name            Name
+	email           Email
+	status          StaffStatus
+	statusReason    string    // why the staff was suspended or blacklisted
+	statusChangedAt *DateTime // nil until the status is changed by an admin
+	role            StaffRole
+	userId          *Id // the account the staff signs in with, nil until one is linked
+	deletedAt       *DateTime
+	createdAt       DateTime
+	updatedAt       DateTime
 }
 
 // NewStaff creates a new Staff domain entity.
@@ -28,10 +36,14 @@ func NewStaff(name Name, email Email, status StaffStatus) (*Staff, error) {
 	if status == "" {
 		status = InActive
 	}
+	now := DateTime(time.Now().UTC())
 	return &Staff{
-		name:   name,
-		email:  email,
-		status: status,
+		name:      name,
+		email:     email,
+		status:    status,
+		role:      MemberRole,
+		createdAt: now,
+		updatedAt: now,
 	}, nil
 }
 
@@ -40,6 +52,97 @@ func (s *Staff) SetId(id Id) {
 	s.id = id
 }
 
+// LinkUser ties the staff to the account they sign in with.
+func (s *Staff) LinkUser(userId Id) {
+	s.userId = &userId
+}
+
+// Rename changes the name the staff is known by in the institution.
+func (s *Staff) Rename(name Name) error {
+	if name.IsEmpty() {
+		return errors.New("staff name cannot be empty")
+	}
+	s.name = name
+	s.updatedAt = DateTime(time.Now().UTC())
+	return nil
+}
+
+// SetRole changes what the staff may do within the institution.
+func (s *Staff) SetRole(role StaffRole) {
+	s.role = role
+}
+
+// SetStatus changes the status of the staff.
+func (s *Staff) SetStatus(status StaffStatus) {
+	s.status = status
+}
+
+// ChangeStatus moves the staff through its lifecycle:
+// active → suspended or blacklisted, suspended → active or blacklisted, inactive → blacklisted and blacklisted → active.
+// An inactive staff with an account, i.e. one who joined by email domain and waits for approval, can be made active.
+// Suspending or blacklisting needs a reason. A staff reactivated before accepting an invite goes back to inactive.
+func (s *Staff) ChangeStatus(to StaffStatus, reason string, t time.Time) error {
+	if s.IsDeleted() {
+		return errors.New("the staff has been removed from the institution")
+	}
+	allowed := false
+	switch to {
+	case Suspended:
+		allowed = s.status == Active
+	case Blacklisted:
+		allowed = s.status != Blacklisted
+	case Active:
+		allowed = s.status == Suspended || s.status == Blacklisted || (s.status == InActive && s.userId != nil)
+	}
+	if !allowed {
+		return fmt.Errorf("a %s staff cannot be made %s", s.status, to)
+	}
+	if to == Active {
+		reason = ""
+		if s.userId == nil {
+			to = InActive
+		}
+	} else if reason == "" {
+		return errors.New("a reason is required to suspend or blacklist a staff")
+	} else if len(reason) > 500 {
+		return errors.New("the reason cannot be longer than 500 characters")
+	}
+	changedAt := DateTime(t)
+	s.status = to
+	s.statusReason = reason
+	s.statusChangedAt = &changedAt
+	s.updatedAt = changedAt
+	return nil
+}
+
+// Restore brings back a removed staff, which is only possible within StaffRetention of the removal.
+func (s *Staff) Restore(t time.Time) error {
+	if !s.IsDeleted() {
+		return errors.New("the staff has not been removed")
+	}
+	if t.Sub(time.Time(*s.deletedAt)) > StaffRetention {
+		return errors.New("the staff was removed too long ago to be restored")
+	}
+	s.deletedAt = nil
+	s.updatedAt = DateTime(t)
+	return nil
+}
+
+// SetStatusDetails sets the reason and time of the last status change when loaded from storage.
+func (s *Staff) SetStatusDetails(reason string, changedAt *time.Time) {
+	s.statusReason = reason
+	if changedAt != nil {
+		dt := DateTime(*changedAt)
+		s.statusChangedAt = &dt
+	}
+}
+
+// SetTimestamps sets createdAt and updatedAt when loaded from storage.
+func (s *Staff) SetTimestamps(createdAt, updatedAt time.Time) {
+	s.createdAt = DateTime(createdAt)
+	s.updatedAt = DateTime(updatedAt)
+}
+
 // MarkDeleted records soft‑deletion with a timestamp.
 func (s *Staff) MarkDeleted(t time.Time) {
 	dt := DateTime(t)
@@ -60,6 +163,38 @@ func (s *Staff) Email() Email {
 func (s *Staff) Status() StaffStatus {
 	return s.status
 }
+func (s *Staff) StatusReason() string {
+	return s.statusReason
+}
+func (s *Staff) StatusChangedAt() *DateTime {
+	return s.statusChangedAt
+}
+
+// RestorableUntil is when a removed staff can no longer be restored, nil when the staff is not removed.
+func (s *Staff) RestorableUntil() *DateTime {
+	if s.deletedAt == nil {
+		return nil
+	}
+	until := DateTime(time.Time(*s.deletedAt).Add(StaffRetention))
+	return &until
+}
+func (s *Staff) Role() StaffRole {
+	return s.role
+}
+func (s *Staff) UserId() *Id {
+	return s.userId
+}
+func (s *Staff) IsAdmin() bool {
+	return s.role.IsAdmin()
+}
+func (s *Staff) IsOwner() bool {
+	return s.role == OwnerRole
+}
+
+// HasAccess reports whether the staff can currently act within the institution.
+func (s *Staff) HasAccess() bool {
+	return s.status == Active && !s.IsDeleted()
+}
 func (s *Staff) DeletedAt() *DateTime {
 	return s.deletedAt
 }
@@ -0,0 +1,49 @@
+package institution
+
+import "errors"
+
+// Tenant is the institution a request is scoped to, with the caller's membership of it.
+type Tenant struct {
+	institutionId Id
+	staffId       Id
+	userId        Id
+	role          StaffRole
+}
+
+// NewTenant scopes a request to the institution for the staff, who must currently have access to it.
+func NewTenant(institutionId Id, staff *Staff) (*Tenant, error) {
+	if staff == nil || staff.UserId() == nil {
+		return nil, errors.New("a tenant needs a staff with an account")
+	}
+	if !staff.HasAccess() {
+		return nil, errors.New("the staff has no access to the institution")
+	}
+	return &Tenant{
+		institutionId: institutionId,
+		staffId:       staff.Id(),
+		userId:        *staff.UserId(),
+		role:          staff.Role(),
+	}, nil
+}
+
+// ----------- Getters -----------
+
+func (t *Tenant) InstitutionId() Id {
+	return t.institutionId
+}
+
+func (t *Tenant) StaffId() Id {
+	return t.staffId
+}
+
+func (t *Tenant) UserId() Id {
+	return t.userId
+}
+
+func (t *Tenant) Role() StaffRole {
+	return t.role
+}
+
+func (t *Tenant) IsAdmin() bool {
+	return t.role.IsAdmin()
+}
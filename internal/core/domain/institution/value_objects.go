@@ -144,8 +144,10 @@ func NewDateTime(input string) (*DateTime, error) {
 type StaffStatus string
 
 var (
-	Active   StaffStatus = "active"
-	InActive StaffStatus = "inactive"
+	Active      StaffStatus = "active"
+	InActive    StaffStatus = "inactive"    // invited, has not accepted yet
+	Suspended   StaffStatus = "suspended"   // access withheld for a while
+	Blacklisted StaffStatus = "blacklisted" // barred from the institution, cannot be invited again
 )
 
 func NewStaffStatus(val string) (StaffStatus, error) {
@@ -166,9 +168,60 @@ func (st StaffStatus) String() string {
 // IsValid checks if the StaffStatus is one of the predefined valid types.
 func isValidStaffStatus(val string) bool {
 	switch StaffStatus(val) {
-	case Active, InActive:
+	case Active, InActive, Suspended, Blacklisted:
 		return true
 	default:
 		return false
 	}
 }
+
+// StaffFilter narrows a staff listing, the zero value lists every staff that is not removed
+type StaffFilter struct {
+	Status *StaffStatus
+	Role   *StaffRole
+	// Removed lists the soft deleted staff instead
+	Removed bool
+	Page    Page
+}
+
+// CourseFilter narrows a course listing, the zero value lists every course
+type CourseFilter struct {
+	// CategoryIds keeps the courses in any of the categories, nil keeps every course
+	CategoryIds []Id
+	Page        Page
+}
+
+// Page is the slice of a listing to return, the zero value returns all of it
+type Page struct {
+	// 0 means no limit
+	Limit  int
+	Offset int
+}
+
+// staff role
+type StaffRole string
+
+var (
+	OwnerRole  StaffRole = "owner"  // an admin who is also responsible for the institution and its billing
+	AdminRole  StaffRole = "admin"  // manages the institution, its staff and groups
+	MemberRole StaffRole = "member" // works within the groups they belong to
+)
+
+// NewStaffRole parses a role that can be given to a staff, the owner role only comes from an ownership transfer.
+func NewStaffRole(val string) (StaffRole, error) {
+	switch r := StaffRole(val); r {
+	case AdminRole, MemberRole:
+		return r, nil
+	case OwnerRole:
+		return "", errors.New("the owner role can only be given by transferring ownership")
+	}
+	return "", errors.New("the staff role is not recognized")
+}
+func (r StaffRole) String() string {
+	return string(r)
+}
+
+// IsAdmin reports whether the role can manage the institution, owners are admins too.
+func (r StaffRole) IsAdmin() bool {
+	return r == AdminRole || r == OwnerRole
+}
@@ -0,0 +1,229 @@
+package user
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ApiKeyScope limits what an api key can be used for
+type ApiKeyScope string
+
+var (
+	ScopeAccountRead      ApiKeyScope = "account:read"
+	ScopeCoursesRead      ApiKeyScope = "courses:read"
+	ScopeCoursesWrite     ApiKeyScope = "courses:write"
+	ScopeRosterRead       ApiKeyScope = "roster:read"
+	ScopeRosterWrite      ApiKeyScope = "roster:write"
+	ScopeAssessmentsRead  ApiKeyScope = "assessments:read"
+	ScopeAssessmentsWrite ApiKeyScope = "assessments:write"
+)
+
+func NewApiKeyScope(val string) (ApiKeyScope, error) {
+	switch s := ApiKeyScope(val); s {
+	case ScopeAccountRead, ScopeCoursesRead, ScopeCoursesWrite, ScopeRosterRead, ScopeRosterWrite, ScopeAssessmentsRead, ScopeAssessmentsWrite:
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid api key scope: %s", val)
+}
+
+func (s ApiKeyScope) String() string {
+	return string(s)
+}
+
+const (
+	apiKeyTag = "am"
+	// how often the last used time is written, keys used in a tight loop would otherwise write on every request
+	apiKeyLastUsedResolution = time.Minute
+)
+
+// ApiKey lets an integration authenticate without a password. Only the prefix (to find the key) and a hash of the
+// whole key are stored, the key itself is shown once when it is created. A key with an institution acts for that institution.
+type ApiKey struct {
+	id            Id
+	userId        Id
+	institutionId *Id
+	name          string
+	prefix        string
+	hash          string
+	scopes        []ApiKeyScope
+	expiresAt     *DateTime
+	lastUsedAt    *DateTime
+	revokedAt     *DateTime
+	createdAt     DateTime
+}
+
+// NewApiKey creates a personal key for the user, the key's secret is set with SetSecret
+func NewApiKey(userId Id, name string, scopes []ApiKeyScope, expiresAt *time.Time) (*ApiKey, error) {
+	if !userId.IsValid() {
+		return nil, errors.New("user id cannot be 0 or negative")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("api key name cannot be empty")
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("api key needs at least one scope")
+	}
+	now := time.Now().UTC()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, errors.New("api key expiry must be in the future")
+	}
+
+	k := &ApiKey{
+		userId:    userId,
+		name:      name,
+		scopes:    scopes,
+		createdAt: DateTime(now),
+	}
+	if expiresAt != nil {
+		k.SetExpiresAt(*expiresAt)
+	}
+	return k, nil
+}
+
+// SetSecret derives the key from the prefix and secret, stores what is needed to verify it and returns the key to hand out
+func (k *ApiKey) SetSecret(prefix, secret string) string {
+	k.prefix = prefix
+	key := FormatApiKey(prefix, secret)
+	k.hash = hashApiKey(key)
+	return key
+}
+
+// FormatApiKey builds the key handed to the user, e.g. am_3fK9aB2c_<secret>
+func FormatApiKey(prefix, secret string) string {
+	return apiKeyTag + "_" + prefix + "_" + secret
+}
+
+// ParseApiKeyPrefix returns the prefix a key is looked up by
+func ParseApiKeyPrefix(key string) (string, error) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyTag || parts[1] == "" || parts[2] == "" {
+		return "", errors.New("malformed api key")
+	}
+	return parts[1], nil
+}
+
+// Matches reports whether the key is this api key
+func (k *ApiKey) Matches(key string) bool {
+	return subtle.ConstantTimeCompare([]byte(k.hash), []byte(hashApiKey(key))) == 1
+}
+
+// the secret is long and random so a fast hash is enough, unlike passwords it cannot be guessed from a dictionary
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// HasScope
+func (k *ApiKey) HasScope(scope ApiKeyScope) bool {
+	for _, s := range k.scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUsable reports whether the key can still authenticate
+func (k *ApiKey) IsUsable(now time.Time) bool {
+	if k.revokedAt != nil {
+		return false
+	}
+	return k.expiresAt == nil || now.Before(*k.expiresAt)
+}
+
+// MarkUsed records the use and reports whether the new time needs persisting
+func (k *ApiKey) MarkUsed(now time.Time) bool {
+	if k.lastUsedAt != nil && now.Sub(*k.lastUsedAt) < apiKeyLastUsedResolution {
+		return false
+	}
+	k.SetLastUsedAt(now)
+	return true
+}
+
+// Revoke
+func (k *ApiKey) Revoke(t time.Time) {
+	dt := DateTime(t)
+	k.revokedAt = &dt
+}
+
+// Setters used when loading a key from storage
+func (k *ApiKey) SetId(id Id) {
+	k.id = id
+}
+
+func (k *ApiKey) SetInstitutionId(institutionId Id) {
+	k.institutionId = &institutionId
+}
+
+func (k *ApiKey) SetPrefix(prefix string) {
+	k.prefix = prefix
+}
+
+func (k *ApiKey) SetHash(hash string) {
+	k.hash = hash
+}
+
+func (k *ApiKey) SetExpiresAt(t time.Time) {
+	dt := DateTime(t)
+	k.expiresAt = &dt
+}
+
+func (k *ApiKey) SetLastUsedAt(t time.Time) {
+	dt := DateTime(t)
+	k.lastUsedAt = &dt
+}
+
+func (k *ApiKey) SetCreatedAt(t time.Time) {
+	k.createdAt = DateTime(t)
+}
+
+// Getters
+func (k *ApiKey) Id() Id {
+	return k.id
+}
+
+func (k *ApiKey) UserId() Id {
+	return k.userId
+}
+
+func (k *ApiKey) InstitutionId() *Id {
+	return k.institutionId
+}
+
+func (k *ApiKey) Name() string {
+	return k.name
+}
+
+func (k *ApiKey) Prefix() string {
+	return k.prefix
+}
+
+func (k *ApiKey) Hash() string {
+	return k.hash
+}
+
+func (k *ApiKey) Scopes() []ApiKeyScope {
+	return k.scopes
+}
+
+func (k *ApiKey) ExpiresAt() *DateTime {
+	return k.expiresAt
+}
+
+func (k *ApiKey) LastUsedAt() *DateTime {
+	return k.lastUsedAt
+}
+
+func (k *ApiKey) RevokedAt() *DateTime {
+	return k.revokedAt
+}
+
+func (k *ApiKey) CreatedAt() DateTime {
+	return k.createdAt
+}
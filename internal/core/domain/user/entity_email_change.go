@@ -0,0 +1,158 @@
+package user
+
+import (
+	"errors"
+	"time"
+)
+
+// EmailChangeStatus
+type EmailChangeStatus string
+
+var (
+	EmailChangePending   EmailChangeStatus = "pending"
+	EmailChangeConfirmed EmailChangeStatus = "confirmed"
+	EmailChangeReverted  EmailChangeStatus = "reverted"
+	EmailChangeCancelled EmailChangeStatus = "cancelled"
+)
+
+func NewEmailChangeStatus(val string) (EmailChangeStatus, error) {
+	switch EmailChangeStatus(val) {
+	case EmailChangePending, EmailChangeConfirmed, EmailChangeReverted, EmailChangeCancelled:
+		return EmailChangeStatus(val), nil
+	default:
+		return "", errors.New("the email change status is not recognized")
+	}
+}
+
+func (st EmailChangeStatus) String() string {
+	return string(st)
+}
+
+// EmailChange is a request to move an account to a new email address. It only applies once the new address
+// confirms it, and the old address can revert it until revertableUntil.
+type EmailChange struct {
+	id              Id
+	userId          Id
+	oldEmail        Email
+	newEmail        Email
+	status          EmailChangeStatus
+	revertableUntil DateTime
+	createdAt       DateTime
+	updatedAt       DateTime
+}
+
+// NewEmailChange creates a pending email change.
+func NewEmailChange(userId Id, oldEmail, newEmail Email, revertableUntil time.Time) (*EmailChange, error) {
+	if !userId.IsValid() {
+		return nil, errors.New("user id cannot be 0 or negative")
+	}
+	if oldEmail.IsEmpty() || newEmail.IsEmpty() {
+		return nil, errors.New("email cannot be empty")
+	}
+	if oldEmail == newEmail {
+		return nil, errors.New("new email must be different from the current email")
+	}
+
+	now := DateTime(time.Now().UTC())
+	return &EmailChange{
+		userId:          userId,
+		oldEmail:        oldEmail,
+		newEmail:        newEmail,
+		status:          EmailChangePending,
+		revertableUntil: DateTime(revertableUntil),
+		createdAt:       now,
+		updatedAt:       now,
+	}, nil
+}
+
+// Confirm marks the change confirmed by the new address.
+func (c *EmailChange) Confirm() error {
+	if c.status != EmailChangePending {
+		return errors.New("email change is no longer pending")
+	}
+	c.status = EmailChangeConfirmed
+	c.touch()
+	return nil
+}
+
+// Revert undoes the change from the old address, it returns true if the email had already been applied and has to be restored.
+func (c *EmailChange) Revert(now time.Time) (bool, error) {
+	if now.After(c.revertableUntil) {
+		return false, errors.New("email change can no longer be reverted")
+	}
+	switch c.status {
+	case EmailChangePending:
+		c.status = EmailChangeCancelled
+		c.touch()
+		return false, nil
+	case EmailChangeConfirmed:
+		c.status = EmailChangeReverted
+		c.touch()
+		return true, nil
+	default:
+		return false, errors.New("email change has already been reverted or cancelled")
+	}
+}
+
+// Cancel drops a pending change, e.g. when a newer change is requested.
+func (c *EmailChange) Cancel() {
+	if c.status == EmailChangePending {
+		c.status = EmailChangeCancelled
+		c.touch()
+	}
+}
+
+// Setters used when loading from persistence
+func (c *EmailChange) SetId(id Id) {
+	c.id = id
+}
+
+func (c *EmailChange) SetStatus(status EmailChangeStatus) {
+	c.status = status
+}
+
+func (c *EmailChange) SetCreatedAt(t time.Time) {
+	c.createdAt = DateTime(t)
+}
+
+func (c *EmailChange) SetUpdatedAt(t time.Time) {
+	c.updatedAt = DateTime(t)
+}
+
+// Getters
+func (c *EmailChange) Id() Id {
+	return c.id
+}
+
+func (c *EmailChange) UserId() Id {
+	return c.userId
+}
+
+func (c *EmailChange) OldEmail() Email {
+	return c.oldEmail
+}
+
+func (c *EmailChange) NewEmail() Email {
+	return c.newEmail
+}
+
+func (c *EmailChange) Status() EmailChangeStatus {
+	return c.status
+}
+
+func (c *EmailChange) RevertableUntil() DateTime {
+	return c.revertableUntil
+}
+
+func (c *EmailChange) CreatedAt() DateTime {
+	return c.createdAt
+}
+
+func (c *EmailChange) UpdatedAt() DateTime {
+	return c.updatedAt
+}
+
+// touch updates the updatedAt timestamp.
+func (c *EmailChange) touch() {
+	c.updatedAt = DateTime(time.Now().UTC())
+}
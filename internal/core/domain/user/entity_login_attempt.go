@@ -0,0 +1,140 @@
+package user
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// LockoutPolicy describes how failed logins are throttled.
+// The first FreeAttempts failures are not delayed, every failure after that doubles the delay
+// (starting at BaseDelay and capped at MaxDelay), and reaching MaxFailures locks the key for LockoutDuration.
+type LockoutPolicy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	MaxFailures     int
+	LockoutDuration time.Duration
+}
+
+var DefaultLockoutPolicy = LockoutPolicy{
+	FreeAttempts:    3,
+	BaseDelay:       time.Second * 2,
+	MaxDelay:        time.Minute * 5,
+	MaxFailures:     10,
+	LockoutDuration: time.Minute * 30,
+}
+
+// LoginAttemptKey identifies what is being throttled, e.g. an ip address or an account.
+type LoginAttemptKey string
+
+func (k LoginAttemptKey) String() string {
+	return string(k)
+}
+
+func NewIpLoginAttemptKey(ip string) (LoginAttemptKey, error) {
+	ip = strings.TrimSpace(ip)
+	if ip == "" {
+		return "", errors.New("ip cannot be empty")
+	}
+	return LoginAttemptKey("ip:" + ip), nil
+}
+
+func NewAccountLoginAttemptKey(email Email) (LoginAttemptKey, error) {
+	if email.IsEmpty() {
+		return "", errors.New("email cannot be empty")
+	}
+	return LoginAttemptKey("account:" + strings.ToLower(email.String())), nil
+}
+
+// LoginAttempt tracks consecutive failed logins for a single key.
+type LoginAttempt struct {
+	key          LoginAttemptKey
+	failures     int
+	lastFailedAt *DateTime
+	lockedUntil  *DateTime
+}
+
+// NewLoginAttempt creates a clean attempt record for the key.
+func NewLoginAttempt(key LoginAttemptKey) *LoginAttempt {
+	return &LoginAttempt{key: key}
+}
+
+// RecordFailure registers a failed login and reports whether this failure locked the key.
+func (a *LoginAttempt) RecordFailure(now time.Time, policy LockoutPolicy) bool {
+	// a lockout that has run its course starts the count afresh
+	if a.lockedUntil != nil && !a.lockedUntil.After(now) {
+		a.failures = 0
+		a.lockedUntil = nil
+	}
+	a.failures++
+	t := DateTime(now)
+	a.lastFailedAt = &t
+
+	if policy.MaxFailures > 0 && a.failures >= policy.MaxFailures && a.lockedUntil == nil {
+		until := DateTime(now.Add(policy.LockoutDuration))
+		a.lockedUntil = &until
+		return true
+	}
+	return false
+}
+
+// RetryAfter returns how long the key has to wait before another attempt is allowed, zero means allowed.
+func (a *LoginAttempt) RetryAfter(now time.Time, policy LockoutPolicy) time.Duration {
+	if a.IsLocked(now) {
+		return a.lockedUntil.Sub(now)
+	}
+	if a.lastFailedAt == nil || a.failures <= policy.FreeAttempts {
+		return 0
+	}
+	delay := policy.BaseDelay
+	for i := policy.FreeAttempts + 1; i < a.failures; i++ {
+		delay *= 2
+		if delay >= policy.MaxDelay {
+			delay = policy.MaxDelay
+			break
+		}
+	}
+	wait := a.lastFailedAt.Add(delay).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// IsLocked reports whether the key is within a lockout window.
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.lockedUntil != nil && a.lockedUntil.After(now)
+}
+
+// Setters used when loading from persistence
+func (a *LoginAttempt) SetFailures(failures int) {
+	a.failures = failures
+}
+
+func (a *LoginAttempt) SetLastFailedAt(t time.Time) {
+	dt := DateTime(t)
+	a.lastFailedAt = &dt
+}
+
+func (a *LoginAttempt) SetLockedUntil(t time.Time) {
+	dt := DateTime(t)
+	a.lockedUntil = &dt
+}
+
+// Getters
+func (a *LoginAttempt) Key() LoginAttemptKey {
+	return a.key
+}
+
+func (a *LoginAttempt) Failures() int {
+	return a.failures
+}
+
+func (a *LoginAttempt) LastFailedAt() *DateTime {
+	return a.lastFailedAt
+}
+
+func (a *LoginAttempt) LockedUntil() *DateTime {
+	return a.lockedUntil
+}
@@ -0,0 +1,276 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata" // timezones are validated against the embedded database, not whatever the host has
+	"unicode/utf8"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/assessment"
+	"golang.org/x/text/language"
+)
+
+// NotificationChannel is a way the user can be notified
+type NotificationChannel string
+
+var (
+	NotifyByEmail NotificationChannel = "email"
+	NotifyInApp   NotificationChannel = "in-app"
+	NotifyByPush  NotificationChannel = "push"
+)
+
+func NewNotificationChannel(val string) (NotificationChannel, error) {
+	switch c := NotificationChannel(val); c {
+	case NotifyByEmail, NotifyInApp, NotifyByPush:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid notification channel: %s", val)
+}
+
+func (c NotificationChannel) String() string {
+	return string(c)
+}
+
+const (
+	maxDisplayNameLength = 100
+	maxBioLength         = 500
+	// MaxAvatarSize is the largest avatar image accepted, in bytes
+	MaxAvatarSize = 2 << 20
+)
+
+var avatarContentTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// Preferences are the defaults the user works with
+type Preferences struct {
+	NotificationChannels []NotificationChannel
+	DefaultQuestionType  assessment.QuestionType
+	DefaultAiDifficulty  assessment.Difficulty
+}
+
+// Profile is how the user presents themselves, every user has one even if it was never saved
+type Profile struct {
+	userId      Id
+	displayName string
+	bio         string
+	avatarKey   string // where the avatar is stored, empty when there is none
+	avatarUrl   string
+	timezone    string
+	locale      string
+	preferences Preferences
+	updatedAt   DateTime
+}
+
+// ProfileUpdate holds the fields to change, nil fields are left as they are
+type ProfileUpdate struct {
+	DisplayName          *string
+	Bio                  *string
+	Timezone             *string
+	Locale               *string
+	NotificationChannels []string // nil leaves them, empty turns every channel off
+	DefaultQuestionType  *string
+	DefaultAiDifficulty  *string
+}
+
+// ProfileError lists every field of an update that is invalid
+type ProfileError struct {
+	Fields map[string]string
+}
+
+func (e *ProfileError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for field, msg := range e.Fields {
+		parts = append(parts, field+": "+msg)
+	}
+	sort.Strings(parts)
+	return "invalid profile: " + strings.Join(parts, "; ")
+}
+
+// NewProfile returns the default profile of the user
+func NewProfile(userId Id) (*Profile, error) {
+	if !userId.IsValid() {
+		return nil, errors.New("user id cannot be 0 or negative")
+	}
+	return &Profile{
+		userId:   userId,
+		timezone: "UTC",
+		locale:   "en",
+		preferences: Preferences{
+			NotificationChannels: []NotificationChannel{NotifyByEmail},
+			DefaultQuestionType:  assessment.OneAnswer,
+			DefaultAiDifficulty:  assessment.Medium,
+		},
+		updatedAt: DateTime(time.Now().UTC()),
+	}, nil
+}
+
+// Update validates the whole update and applies it only if every field is valid
+func (p *Profile) Update(update ProfileUpdate) error {
+	next := *p
+	invalid := map[string]string{}
+
+	if update.DisplayName != nil {
+		name := strings.TrimSpace(*update.DisplayName)
+		if utf8.RuneCountInString(name) > maxDisplayNameLength {
+			invalid["displayName"] = fmt.Sprintf("must be at most %d characters", maxDisplayNameLength)
+		}
+		next.displayName = name
+	}
+	if update.Bio != nil {
+		bio := strings.TrimSpace(*update.Bio)
+		if utf8.RuneCountInString(bio) > maxBioLength {
+			invalid["bio"] = fmt.Sprintf("must be at most %d characters", maxBioLength)
+		}
+		next.bio = bio
+	}
+	if update.Timezone != nil {
+		// LoadLocation also accepts "" and "Local", which name no zone
+		if *update.Timezone == "" || *update.Timezone == "Local" {
+			invalid["timezone"] = "must be an IANA timezone, e.g. Africa/Lagos"
+		} else if _, err := time.LoadLocation(*update.Timezone); err != nil {
+			invalid["timezone"] = "must be an IANA timezone, e.g. Africa/Lagos"
+		}
+		next.timezone = *update.Timezone
+	}
+	if update.Locale != nil {
+		tag, err := language.Parse(*update.Locale)
+		if err != nil {
+			invalid["locale"] = "must be a BCP 47 language tag, e.g. en-GB"
+		} else {
+			next.locale = tag.String()
+		}
+	}
+	if update.NotificationChannels != nil {
+		channels := make([]NotificationChannel, 0, len(update.NotificationChannels))
+		seen := map[NotificationChannel]bool{}
+		for _, val := range update.NotificationChannels {
+			c, err := NewNotificationChannel(val)
+			if err != nil {
+				invalid["notificationChannels"] = err.Error()
+				break
+			}
+			if !seen[c] {
+				seen[c] = true
+				channels = append(channels, c)
+			}
+		}
+		next.preferences.NotificationChannels = channels
+	}
+	if update.DefaultQuestionType != nil {
+		t, err := assessment.NewQuestionType(*update.DefaultQuestionType)
+		if err != nil {
+			invalid["defaultQuestionType"] = err.Error()
+		}
+		next.preferences.DefaultQuestionType = t
+	}
+	if update.DefaultAiDifficulty != nil {
+		d, err := assessment.NewDifficulty(*update.DefaultAiDifficulty)
+		if err != nil {
+			invalid["defaultAiDifficulty"] = err.Error()
+		}
+		next.preferences.DefaultAiDifficulty = d
+	}
+
+	if len(invalid) > 0 {
+		return &ProfileError{Fields: invalid}
+	}
+	next.updatedAt = DateTime(time.Now().UTC())
+	*p = next
+	return nil
+}
+
+// AvatarExtension returns the file extension for an avatar image, or an error if it is not an accepted image
+func AvatarExtension(contentType string, size int) (string, error) {
+	ext, ok := avatarContentTypes[contentType]
+	if !ok {
+		return "", fmt.Errorf("avatar must be a png, jpeg, webp or gif image, got %s", contentType)
+	}
+	if size == 0 {
+		return "", errors.New("avatar cannot be empty")
+	}
+	if size > MaxAvatarSize {
+		return "", fmt.Errorf("avatar must be at most %d bytes", MaxAvatarSize)
+	}
+	return ext, nil
+}
+
+// SetAvatar records where the avatar is stored and how it is served
+func (p *Profile) SetAvatar(key, url string) {
+	p.avatarKey = key
+	p.avatarUrl = url
+	p.updatedAt = DateTime(time.Now().UTC())
+}
+
+// RemoveAvatar
+func (p *Profile) RemoveAvatar() {
+	p.SetAvatar("", "")
+}
+
+// Setters used when loading a profile from storage
+func (p *Profile) SetDisplayName(name string) {
+	p.displayName = name
+}
+
+func (p *Profile) SetBio(bio string) {
+	p.bio = bio
+}
+
+func (p *Profile) SetTimezone(timezone string) {
+	p.timezone = timezone
+}
+
+func (p *Profile) SetLocale(locale string) {
+	p.locale = locale
+}
+
+func (p *Profile) SetPreferences(preferences Preferences) {
+	p.preferences = preferences
+}
+
+func (p *Profile) SetUpdatedAt(t time.Time) {
+	p.updatedAt = DateTime(t)
+}
+
+// Getters
+func (p *Profile) UserId() Id {
+	return p.userId
+}
+
+func (p *Profile) DisplayName() string {
+	return p.displayName
+}
+
+func (p *Profile) Bio() string {
+	return p.bio
+}
+
+func (p *Profile) AvatarKey() string {
+	return p.avatarKey
+}
+
+func (p *Profile) AvatarUrl() string {
+	return p.avatarUrl
+}
+
+func (p *Profile) Timezone() string {
+	return p.timezone
+}
+
+func (p *Profile) Locale() string {
+	return p.locale
+}
+
+func (p *Profile) Preferences() Preferences {
+	return p.preferences
+}
+
+func (p *Profile) UpdatedAt() DateTime {
+	return p.updatedAt
+}
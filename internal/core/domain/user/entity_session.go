@@ -0,0 +1,93 @@
+package user
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Session is a single login on a device. The refresh tokens issued for the login share the session as their family,
+// so revoking the session revokes every token that descended from that login.
+type Session struct {
+	id         Id
+	userId     Id
+	userAgent  string
+	ip         string
+	createdAt  DateTime
+	lastSeenAt DateTime
+	revokedAt  *DateTime
+}
+
+// NewSession creates a session for the user on the device described by the user agent and ip.
+func NewSession(userId Id, userAgent, ip string) (*Session, error) {
+	if !userId.IsValid() {
+		return nil, errors.New("user id cannot be 0 or negative")
+	}
+	userAgent = strings.TrimSpace(userAgent)
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+
+	now := DateTime(time.Now().UTC())
+	return &Session{
+		userId:     userId,
+		userAgent:  userAgent,
+		ip:         strings.TrimSpace(ip),
+		createdAt:  now,
+		lastSeenAt: now,
+	}, nil
+}
+
+// SetId sets the session ID once persisted.
+func (s *Session) SetId(id Id) {
+	s.id = id
+}
+
+// SetCreatedAt manually updates the timestamp.
+func (s *Session) SetCreatedAt(t time.Time) {
+	s.createdAt = DateTime(t)
+}
+
+// SetLastSeenAt records activity on the session.
+func (s *Session) SetLastSeenAt(t time.Time) {
+	s.lastSeenAt = DateTime(t)
+}
+
+// Revoke ends the session.
+func (s *Session) Revoke(t time.Time) {
+	dt := DateTime(t)
+	s.revokedAt = &dt
+}
+
+// Getters
+func (s *Session) Id() Id {
+	return s.id
+}
+
+func (s *Session) UserId() Id {
+	return s.userId
+}
+
+func (s *Session) UserAgent() string {
+	return s.userAgent
+}
+
+func (s *Session) Ip() string {
+	return s.ip
+}
+
+func (s *Session) CreatedAt() DateTime {
+	return s.createdAt
+}
+
+func (s *Session) LastSeenAt() DateTime {
+	return s.lastSeenAt
+}
+
+func (s *Session) RevokedAt() *DateTime {
+	return s.revokedAt
+}
+
+func (s *Session) IsRevoked() bool {
+	return s.revokedAt != nil
+}
@@ -1,7 +1,11 @@
 package user
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"time"
 )
 
@@ -10,9 +14,16 @@ type Token struct {
 	value     TokenValue
 	tokenType TokenType
 	userId    Id
-	createdAt DateTime
-	updatedAt DateTime
-	expiresAt *DateTime
+	sessionId *Id
+	// the email change a confirmation or revert token was issued for
+	emailChangeId *Id
+	// hash of the nonce held by the browser the token was requested from
+	deviceNonceHash string
+	// hash of the code shown by that browser, needed to use the token anywhere else
+	confirmationCodeHash string
+	createdAt            DateTime
+	updatedAt            DateTime
+	expiresAt            *DateTime
 }
 
 // NewToken creates a new token instance.
@@ -53,6 +64,52 @@ func (t *Token) SetUserId(userId Id) {
 	t.touch()
 }
 
+// SetSessionId links the token to the session (token family) it was issued for.
+func (t *Token) SetSessionId(sessionId Id) {
+	t.sessionId = &sessionId
+	t.touch()
+}
+
+// SetEmailChangeId links the token to the email change it confirms or reverts.
+func (t *Token) SetEmailChangeId(changeId Id) {
+	t.emailChangeId = &changeId
+	t.touch()
+}
+
+// BindToDevice ties the token to the browser holding the nonce, only a hash of the nonce is kept.
+// The code is what that browser shows so the token can be confirmed from another device, only its hash is kept too.
+func (t *Token) BindToDevice(nonce, code string) {
+	t.deviceNonceHash = hashTokenSecret(nonce)
+	t.confirmationCodeHash = hashTokenSecret(strings.ToUpper(code))
+	t.touch()
+}
+
+// SetDeviceNonceHash
+func (t *Token) SetDeviceNonceHash(hash string) {
+	t.deviceNonceHash = hash
+}
+
+// IsBoundToDevice reports whether the nonce is the one the token was bound to
+func (t *Token) IsBoundToDevice(nonce string) bool {
+	if t.deviceNonceHash == "" || nonce == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(t.deviceNonceHash), []byte(hashTokenSecret(nonce))) == 1
+}
+
+// SetConfirmationCodeHash
+func (t *Token) SetConfirmationCodeHash(hash string) {
+	t.confirmationCodeHash = hash
+}
+
+// MatchesConfirmationCode reports whether the code is the one shown by the browser the token was bound to, case is ignored
+func (t *Token) MatchesConfirmationCode(code string) bool {
+	if t.confirmationCodeHash == "" || code == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(t.confirmationCodeHash), []byte(hashTokenSecret(strings.ToUpper(code)))) == 1
+}
+
 // SetValue sets a new token value and updates the updatedAt timestamp.
 func (t *Token) SetValue(value TokenValue) {
 	t.value = value
@@ -98,6 +155,22 @@ func (t *Token) UserId() Id {
 	return t.userId
 }
 
+func (t *Token) SessionId() *Id {
+	return t.sessionId
+}
+
+func (t *Token) EmailChangeId() *Id {
+	return t.emailChangeId
+}
+
+func (t *Token) DeviceNonceHash() string {
+	return t.deviceNonceHash
+}
+
+func (t *Token) ConfirmationCodeHash() string {
+	return t.confirmationCodeHash
+}
+
 func (t *Token) CreatedAt() DateTime {
 	return t.createdAt
 }
@@ -116,3 +189,8 @@ func (t *Token) HasExpired() bool {
 func (u *Token) touch() {
 	u.updatedAt = DateTime(time.Now().UTC())
 }
+
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
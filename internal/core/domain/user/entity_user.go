@@ -43,6 +43,16 @@ func (i *User) SetId(id Id) {
 	i.id = id
 }
 
+// SetCreatedAt manually updates the timestamp.
+func (u *User) SetCreatedAt(t time.Time) {
+	u.createdAt = DateTime(t)
+}
+
+// SetUpdatedAt manually updates the timestamp.
+func (u *User) SetUpdatedAt(t time.Time) {
+	u.updatedAt = DateTime(t)
+}
+
 // SetPassword
 func (u *User) SetPasswordHash(hash []byte) error {
 	u.password.SetHash(hash)
@@ -50,9 +60,9 @@ func (u *User) SetPasswordHash(hash []byte) error {
 	return nil
 }
 
-// SetPassword
-func (u *User) SetPassword(text string) error {
-	err := u.password.NewHash(text)
+// SetPassword hashes the text once it satisfies the policy, the user's name and email count against its strength
+func (u *User) SetPassword(text string, policy PasswordPolicy) error {
+	err := u.password.NewHash(text, policy, u.name.String(), u.email.String())
 	if err != nil {
 		return err
 	}
@@ -80,6 +90,68 @@ func (u *User) SetDeletedAt(t time.Time) {
 	u.touch()
 }
 
+// ScheduleDeletion soft-deletes the user, the account is purged once the grace period has passed.
+func (u *User) ScheduleDeletion(t time.Time) error {
+	if u.IsDeleted() {
+		return errors.New("account is already scheduled for deletion")
+	}
+	u.SetDeletedAt(t)
+	return nil
+}
+
+// RestoreDeleted cancels a scheduled deletion within the grace period.
+func (u *User) RestoreDeleted(now time.Time, gracePeriod time.Duration) error {
+	if !u.IsDeleted() {
+		return errors.New("account is not scheduled for deletion")
+	}
+	if now.After(u.deletedAt.Add(gracePeriod)) {
+		return errors.New("account can no longer be restored")
+	}
+	u.deletedAt = nil
+	u.touch()
+	return nil
+}
+
+// Anonymise strips personal data from the user, leaving a record that can no longer be tied to a person or logged into.
+func (u *User) Anonymise() {
+	u.name = Name("Deleted User")
+	u.email = Email("deleted-" + u.id.String() + "@assessmate.invalid")
+	u.password.SetHash(nil)
+	u.status = InActive
+	u.touch()
+}
+
+// Suspend blocks the user from signing in.
+func (u *User) Suspend() error {
+	if u.status == Suspended {
+		return errors.New("user is already suspended")
+	}
+	u.status = Suspended
+	u.touch()
+	return nil
+}
+
+// Reactivate lifts a suspension.
+func (u *User) Reactivate() error {
+	if u.status != Suspended {
+		return errors.New("user is not suspended")
+	}
+	u.status = Active
+	u.touch()
+	return nil
+}
+
+// IsSuspended
+func (u *User) IsSuspended() bool {
+	return u.status == Suspended
+}
+
+// ClearPassword leaves the user without a usable password, e.g. until a forced reset is completed.
+func (u *User) ClearPassword() {
+	u.password.SetHash(nil)
+	u.touch()
+}
+
 // SetStatus changes the user’s status and updates the timestamp.
 func (u *User) SetStatus(status UserStatus) {
 	u.status = status
@@ -126,6 +198,9 @@ func (u *User) GetDeletedAt() *DateTime {
 func (u *User) IsVerified() bool {
 	return u.verifiedAt != nil
 }
+func (u *User) IsDeleted() bool {
+	return u.deletedAt != nil
+}
 func (u *User) PasswordHash() []byte {
 	return u.password.hash
 }
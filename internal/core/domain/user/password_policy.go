@@ -0,0 +1,115 @@
+package user
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// BreachedRangeFunc returns the breached SHA-1 hash suffixes (the 35 characters after the prefix) that start with the 5 character prefix.
+// Only the prefix leaves the domain, so a lookup never discloses the full hash of a password (k-anonymity).
+type BreachedRangeFunc func(prefix string) ([]string, error)
+
+// PasswordPolicy is the set of rules a new password has to satisfy.
+type PasswordPolicy struct {
+	MinLength int
+	// bcrypt ignores everything after 72 bytes, so passwords longer than that are rejected rather than silently truncated
+	MaxLength   int
+	MinStrength int // 0 to 4, see EstimatePasswordStrength
+	// optional, when nil breached passwords are not checked
+	BreachedRange BreachedRangeFunc
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:   8,
+	MaxLength:   72,
+	MinStrength: 2,
+}
+
+// PasswordViolationCode
+type PasswordViolationCode string
+
+var (
+	PasswordTooShort PasswordViolationCode = "too_short"
+	PasswordTooLong  PasswordViolationCode = "too_long"
+	PasswordTooWeak  PasswordViolationCode = "too_weak"
+	PasswordBreached PasswordViolationCode = "breached"
+)
+
+func (c PasswordViolationCode) String() string {
+	return string(c)
+}
+
+type PasswordViolation struct {
+	Code    PasswordViolationCode
+	Message string
+}
+
+// PasswordPolicyError lists every rule a password broke.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return "password does not meet the policy: " + strings.Join(messages, "; ")
+}
+
+func (e *PasswordPolicyError) add(code PasswordViolationCode, message string) {
+	e.Violations = append(e.Violations, PasswordViolation{Code: code, Message: message})
+}
+
+// Validate checks the password against the policy. userInputs are values the password should not be built from,
+// e.g. the user's name and email.
+func (p PasswordPolicy) Validate(text string, userInputs ...string) error {
+	var policyErr PasswordPolicyError
+
+	if length := len([]rune(text)); length < p.MinLength {
+		policyErr.add(PasswordTooShort, fmt.Sprintf("password must be at least %d characters long", p.MinLength))
+	}
+	if p.MaxLength > 0 && len(text) > p.MaxLength {
+		policyErr.add(PasswordTooLong, fmt.Sprintf("password must not exceed %d bytes", p.MaxLength))
+	}
+	if strength := EstimatePasswordStrength(text, userInputs...); strength.Score < p.MinStrength {
+		message := "password is too easy to guess"
+		if strength.Feedback != "" {
+			message += ", " + strength.Feedback
+		}
+		policyErr.add(PasswordTooWeak, message)
+	}
+	if p.BreachedRange != nil {
+		breached, err := isBreachedPassword(text, p.BreachedRange)
+		if err != nil {
+			return fmt.Errorf("error checking breached passwords: %w", err)
+		}
+		if breached {
+			policyErr.add(PasswordBreached, "password has appeared in a data breach, please choose another")
+		}
+	}
+
+	if len(policyErr.Violations) > 0 {
+		return &policyErr
+	}
+	return nil
+}
+
+func isBreachedPassword(text string, lookup BreachedRangeFunc) (bool, error) {
+	sum := sha1.Sum([]byte(text))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	suffixes, err := lookup(prefix)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range suffixes {
+		if strings.EqualFold(s, suffix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,102 @@
+package user
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func violationCodes(t *testing.T, err error) []PasswordViolationCode {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("err = %v, want a PasswordPolicyError", err)
+	}
+	codes := make([]PasswordViolationCode, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	const strong = "vR7#kq2!Lm9z" // 12 bytes
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		want       []PasswordViolationCode
+	}{
+		{name: "strong password", password: strong},
+		{name: "one character short of the minimum", password: "Kx8!pz#", want: []PasswordViolationCode{PasswordTooShort}},
+		{name: "exactly the minimum length", password: "Kx8!pz#q"},
+		{name: "exactly 72 bytes", password: strings.Repeat(strong, 6)},
+		{name: "73 bytes", password: strings.Repeat(strong, 6) + "x", want: []PasswordViolationCode{PasswordTooLong}},
+		// the maximum is in bytes because bcrypt truncates bytes, 37 two byte runes pass the rune based minimum but not the maximum
+		{name: "multibyte runes within 72 bytes", password: strings.Repeat("é", 36), want: []PasswordViolationCode{PasswordTooWeak}},
+		{name: "multibyte runes over 72 bytes", password: strings.Repeat("é", 37), want: []PasswordViolationCode{PasswordTooLong, PasswordTooWeak}},
+		{name: "common password", password: "password", want: []PasswordViolationCode{PasswordTooWeak}},
+		{name: "short and common", password: "qwerty", want: []PasswordViolationCode{PasswordTooShort, PasswordTooWeak}},
+		{name: "built from the name", password: "AdaLovelace2024", userInputs: []string{"Ada Lovelace", "ada@example.com"}, want: []PasswordViolationCode{PasswordTooWeak}},
+		{name: "built from the email", password: "ada.lovelace", userInputs: []string{"Ada", "ada.lovelace@example.com"}, want: []PasswordViolationCode{PasswordTooWeak}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := violationCodes(t, DefaultPasswordPolicy.Validate(tt.password, tt.userInputs...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyBreachedPasswords(t *testing.T) {
+	const password = "vR7#kq2!Lm9z"
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	tests := []struct {
+		name     string
+		suffixes []string
+		want     []PasswordViolationCode
+	}{
+		{name: "in the breached range", suffixes: []string{strings.Repeat("0", 35), hash[5:]}, want: []PasswordViolationCode{PasswordBreached}},
+		{name: "suffix in lower case", suffixes: []string{strings.ToLower(hash[5:])}, want: []PasswordViolationCode{PasswordBreached}},
+		{name: "not in the breached range", suffixes: []string{strings.Repeat("0", 35)}},
+		{name: "empty range", suffixes: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := DefaultPasswordPolicy
+			policy.BreachedRange = func(prefix string) ([]string, error) {
+				// only the prefix of the hash may leave the domain
+				if prefix != hash[:5] {
+					t.Errorf("prefix = %q, want %q", prefix, hash[:5])
+				}
+				return tt.suffixes, nil
+			}
+			got := violationCodes(t, policy.Validate(password))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyBreachedLookupFailure(t *testing.T) {
+	lookupErr := errors.New("range unavailable")
+	policy := DefaultPasswordPolicy
+	policy.BreachedRange = func(prefix string) ([]string, error) { return nil, lookupErr }
+
+	err := policy.Validate("vR7#kq2!Lm9z")
+	var policyErr *PasswordPolicyError
+	if !errors.Is(err, lookupErr) || errors.As(err, &policyErr) {
+		t.Errorf("err = %v, want the lookup error and no policy violation", err)
+	}
+}
@@ -0,0 +1,261 @@
+package user
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PasswordStrength is a zxcvbn style estimate of how hard a password is to guess.
+// Score goes from 0 (too guessable) to 4 (very unguessable).
+type PasswordStrength struct {
+	Score        int
+	GuessesLog10 float64
+	Feedback     string
+}
+
+// most common passwords, in rank order, used as the guessing dictionary
+var commonPasswords = []string{
+	"password", "123456", "qwerty", "abc123", "letmein", "monkey", "dragon", "111111", "baseball", "iloveyou",
+	"trustno1", "sunshine", "master", "welcome", "shadow", "ashley", "football", "jesus", "michael", "ninja",
+	"mustang", "admin", "login", "princess", "starwars", "whatever", "freedom", "hello", "charlie", "secret",
+	"summer", "winter", "spring", "autumn", "flower", "computer", "student", "teacher", "school", "assessmate",
+}
+
+var keyboardRows = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm", "1234567890", "!@#$%^&*()"}
+
+var leetSubstitutions = map[rune]rune{
+	'@': 'a', '4': 'a', '3': 'e', '1': 'i', '!': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't',
+}
+
+// EstimatePasswordStrength breaks the password into the patterns an attacker would guess first
+// (dictionary words and the user's own details, keyboard walks, sequences, repeats and years),
+// charges each pattern what it would take to guess it and brute forces whatever is left.
+func EstimatePasswordStrength(text string, userInputs ...string) PasswordStrength {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return PasswordStrength{Feedback: "add a password"}
+	}
+	normalised := normalisePassword(runes)
+	covered := make([]bool, len(runes))
+	bits := 0.0
+	var feedback []string
+
+	// dictionary words, longest first so that "password" wins over "pass"
+	dictionary := passwordDictionary(userInputs)
+	for _, entry := range dictionary {
+		if n := coverMatches(normalised, covered, []rune(entry.word)); n > 0 {
+			bits += float64(n) * (math.Log2(float64(entry.rank)+1) + 1)
+			if entry.isUserInput {
+				feedback = append(feedback, "avoid using your name or email")
+			} else {
+				feedback = append(feedback, "avoid common words and passwords")
+			}
+		}
+	}
+
+	// keyboard walks such as qwer or 1234
+	for _, row := range keyboardRows {
+		for _, r := range []string{row, reverse(row)} {
+			for length := len(r); length >= 4; length-- {
+				for start := 0; start+length <= len(r); start++ {
+					if n := coverMatches(normalised, covered, []rune(r[start:start+length])); n > 0 {
+						bits += float64(n) * (math.Log2(float64(len(row))) + math.Log2(float64(length)))
+						feedback = append(feedback, "avoid keyboard patterns")
+					}
+				}
+			}
+		}
+	}
+
+	// sequences (abc, 987) and repeats (aaa)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		delta := 0
+		if j < len(runes) {
+			delta = int(unicode.ToLower(runes[j])) - int(unicode.ToLower(runes[i]))
+		}
+		if delta >= -1 && delta <= 1 {
+			for j < len(runes) && int(unicode.ToLower(runes[j]))-int(unicode.ToLower(runes[j-1])) == delta {
+				j++
+			}
+		}
+		if length := j - i; length >= 3 && !anyCovered(covered[i:j]) {
+			for k := i; k < j; k++ {
+				covered[k] = true
+			}
+			bits += math.Log2(float64(charsetSize(runes[i:i+1]))) + math.Log2(float64(length))
+			if delta == 0 {
+				feedback = append(feedback, "avoid repeated characters")
+			} else {
+				feedback = append(feedback, "avoid sequences like abc or 123")
+			}
+			i = j
+			continue
+		}
+		i++
+	}
+
+	// years
+	for i := 0; i+4 <= len(runes); i++ {
+		if anyCovered(covered[i:i+4]) || !isRecentYear(runes[i:i+4]) {
+			continue
+		}
+		for k := i; k < i+4; k++ {
+			covered[k] = true
+		}
+		bits += math.Log2(130)
+		feedback = append(feedback, "avoid years and dates")
+	}
+
+	// brute force whatever no pattern explains
+	size := math.Log2(float64(charsetSize(runes)))
+	for _, c := range covered {
+		if !c {
+			bits += size
+		}
+	}
+
+	guessesLog10 := bits * math.Log10(2)
+	strength := PasswordStrength{GuessesLog10: guessesLog10, Score: scoreFromGuesses(guessesLog10)}
+	if strength.Score < 3 {
+		if len(feedback) > 0 {
+			strength.Feedback = feedback[0]
+		} else {
+			strength.Feedback = "use a longer password or a few unrelated words"
+		}
+	}
+	return strength
+}
+
+func scoreFromGuesses(guessesLog10 float64) int {
+	switch {
+	case guessesLog10 < 3:
+		return 0
+	case guessesLog10 < 6:
+		return 1
+	case guessesLog10 < 8:
+		return 2
+	case guessesLog10 < 10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+type dictionaryEntry struct {
+	word        string
+	rank        int
+	isUserInput bool
+}
+
+func passwordDictionary(userInputs []string) []dictionaryEntry {
+	var entries []dictionaryEntry
+	for i, word := range commonPasswords {
+		entries = append(entries, dictionaryEntry{word: word, rank: i + 1})
+	}
+	for _, input := range userInputs {
+		input = strings.ToLower(input)
+		// an email contributes its local part, a name each of its words
+		if local, _, found := strings.Cut(input, "@"); found {
+			input = local
+		}
+		for _, word := range strings.FieldsFunc(input, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+			if len([]rune(word)) >= 3 {
+				entries = append(entries, dictionaryEntry{word: word, rank: 1, isUserInput: true})
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return len(entries[i].word) > len(entries[j].word)
+	})
+	return entries
+}
+
+// coverMatches marks every uncovered occurrence of word in text and returns how many were found
+func coverMatches(text []rune, covered []bool, word []rune) int {
+	found := 0
+	for i := 0; i+len(word) <= len(text); i++ {
+		if anyCovered(covered[i:i+len(word)]) || string(text[i:i+len(word)]) != string(word) {
+			continue
+		}
+		for k := i; k < i+len(word); k++ {
+			covered[k] = true
+		}
+		found++
+		i += len(word) - 1
+	}
+	return found
+}
+
+func anyCovered(covered []bool) bool {
+	for _, c := range covered {
+		if c {
+			return true
+		}
+	}
+	return false
+}
+
+// normalisePassword lower cases the password and undoes common leet substitutions, keeping one rune per input rune
+func normalisePassword(runes []rune) []rune {
+	normalised := make([]rune, len(runes))
+	for i, r := range runes {
+		r = unicode.ToLower(r)
+		if sub, ok := leetSubstitutions[r]; ok {
+			r = sub
+		}
+		normalised[i] = r
+	}
+	return normalised
+}
+
+func charsetSize(runes []rune) int {
+	var lower, upper, digit, other bool
+	for _, r := range runes {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	size := 0
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if digit {
+		size += 10
+	}
+	if other {
+		size += 33
+	}
+	return size
+}
+
+func isRecentYear(runes []rune) bool {
+	year := 0
+	for _, r := range runes {
+		if r < '0' || r > '9' {
+			return false
+		}
+		year = year*10 + int(r-'0')
+	}
+	return year >= 1900 && year <= 2029
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
@@ -0,0 +1,48 @@
+package user
+
+import "testing"
+
+func TestEstimatePasswordStrength(t *testing.T) {
+	tests := []struct {
+		name               string
+		password           string
+		userInputs         []string
+		minScore, maxScore int
+		feedback           string
+	}{
+		{name: "empty", password: "", maxScore: 0, feedback: "add a password"},
+		{name: "common password", password: "password", maxScore: 0, feedback: "avoid common words and passwords"},
+		{name: "common password with leet substitutions", password: "P@ssw0rd", maxScore: 0, feedback: "avoid common words and passwords"},
+		{name: "common word and a year", password: "summer2019", maxScore: 1, feedback: "avoid common words and passwords"},
+		{name: "keyboard walk", password: "zxcvbnm123", maxScore: 1, feedback: "avoid keyboard patterns"},
+		{name: "sequence", password: "12345678", maxScore: 0, feedback: "avoid sequences like abc or 123"},
+		{name: "repeated character", password: "aaaaaaaaaa", maxScore: 0, feedback: "avoid repeated characters"},
+		{name: "built from the name", password: "AdaLovelace2024", userInputs: []string{"Ada Lovelace", "ada@example.com"}, maxScore: 1, feedback: "avoid using your name or email"},
+		{name: "built from the email", password: "ada.lovelace", userInputs: []string{"Ada", "ada.lovelace@example.com"}, maxScore: 0, feedback: "avoid using your name or email"},
+		{name: "unrelated words", password: "correct horse battery staple", minScore: 4, maxScore: 4},
+		{name: "random characters", password: "vR7#kq2!Lm9z", minScore: 4, maxScore: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimatePasswordStrength(tt.password, tt.userInputs...)
+			if got.Score < tt.minScore || got.Score > tt.maxScore {
+				t.Errorf("score = %d, want %d to %d", got.Score, tt.minScore, tt.maxScore)
+			}
+			if got.Feedback != tt.feedback {
+				t.Errorf("feedback = %q, want %q", got.Feedback, tt.feedback)
+			}
+		})
+	}
+}
+
+func TestEstimatePasswordStrengthCountsUserInputsAsGuessable(t *testing.T) {
+	const password = "AdaLovelace2024"
+	without := EstimatePasswordStrength(password)
+	with := EstimatePasswordStrength(password, "Ada Lovelace", "ada@example.com")
+	if without.Score < DefaultPasswordPolicy.MinStrength {
+		t.Fatalf("score without user inputs = %d, want at least %d", without.Score, DefaultPasswordPolicy.MinStrength)
+	}
+	if with.GuessesLog10 >= without.GuessesLog10 || with.Score >= DefaultPasswordPolicy.MinStrength {
+		t.Errorf("with user inputs = %+v, want fewer guesses than %+v and a failing score", with, without)
+	}
+}
@@ -51,6 +51,8 @@ type UserStatus string
 var (
 	InActive UserStatus = "inactive"
 	Active   UserStatus = "active"
+	// blocked by a platform admin, the user cannot sign in until reactivated
+	Suspended UserStatus = "suspended"
 )
 
 func NewUserStatus(val string) (UserStatus, error) {
@@ -71,7 +73,7 @@ func (st UserStatus) String() string {
 // IsValid checks if the UserStatus is one of the predefined valid types.
 func isValidUserStatus(val string) bool {
 	switch UserStatus(val) {
-	case Active, InActive:
+	case Active, InActive, Suspended:
 		return true
 	default:
 		return false
@@ -134,6 +136,12 @@ var (
 	Verification  TokenType = "verification"
 	ResetPassword TokenType = "reset-password"
 	RefreshToken  TokenType = "refresh-token"
+	// sent to the new address of an email change
+	EmailChangeConfirmation TokenType = "email-change"
+	// sent to the old address so the owner can undo an email change
+	EmailChangeRevert TokenType = "email-change-revert"
+	// passwordless sign in, single use and bound to the device that requested it
+	MagicLink TokenType = "magic-link"
 )
 
 func NewTokenType(val string) (TokenType, error) {
@@ -154,7 +162,7 @@ func (st TokenType) String() string {
 // IsValid checks if the TokenType is one of the predefined valid types.
 func isValidTokenType(val string) bool {
 	switch TokenType(val) {
-	case RefreshToken, ResetPassword, Verification:
+	case RefreshToken, ResetPassword, Verification, EmailChangeConfirmation, EmailChangeRevert, MagicLink:
 		return true
 	default:
 		return false
@@ -229,7 +237,11 @@ type password struct {
 	hash []byte
 }
 
-func (p *password) NewHash(text string) error {
+// NewHash validates the text against the policy before hashing it
+func (p *password) NewHash(text string, policy PasswordPolicy, userInputs ...string) error {
+	if err := policy.Validate(text, userInputs...); err != nil {
+		return err
+	}
 	hash, err := bcrypt.GenerateFromPassword([]byte(text), bcrypt.DefaultCost)
 	if err != nil {
 		return err
@@ -254,5 +266,24 @@ func (p *password) GetHash() []byte {
 
 // user filter
 type UserFilter struct {
-	Status *UserStatus
+	Status     *UserStatus
+	IsVerified *bool
+	// matched against the start of the name or email
+	Search        string
+	CreatedAfter  *DateTime
+	CreatedBefore *DateTime
+	// 0 means no limit
+	Limit  int
+	Offset int
+}
+
+// Role is a platform wide role held through the userRoles table
+type Role string
+
+var (
+	PlatformAdmin Role = "platform-admin"
+)
+
+func (r Role) String() string {
+	return string(r)
 }
@@ -0,0 +1,18 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/audit"
+)
+
+// AuditLogRepository is append only, there is deliberately no way to change or remove an entry.
+// The one exception is purging an account, which erases the ip and user agent the user made entries from.
+type AuditLogRepository interface {
+	AppendAuditEntry(ctx context.Context, entry *audit.Entry) (*audit.Entry, error)
+	// GetAuditEntries returns the newest entries first along with the total matching the filter
+	GetAuditEntries(ctx context.Context, filter audit.Filter) ([]audit.Entry, int, error)
+	// ListAuditEntriesAboutUser returns, oldest first, the entries made by the user, by an admin acting as them or by them acting as someone else,
+	// and the entries targeting them or one of their staff records
+	ListAuditEntriesAboutUser(ctx context.Context, userId int, staffIds []int) ([]audit.Entry, error)
+}
@@ -0,0 +1,10 @@
+package breachedpassword
+
+import "context"
+
+// BreachedPasswordStore looks up breached password hashes by range (k-anonymity), only the first 5 characters
+// of the uppercase SHA-1 hash are ever handed to the store.
+type BreachedPasswordStore interface {
+	// Range returns the 35 character hash suffixes of every breached password whose hash starts with prefix
+	Range(ctx context.Context, prefix string) ([]string, error)
+}
@@ -0,0 +1,9 @@
+package dnsresolver
+
+import "context"
+
+// TxtResolver looks up the TXT records published under a name, used to check a domain is controlled by whoever claims it.
+// A name with no TXT records returns no records rather than an error.
+type TxtResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
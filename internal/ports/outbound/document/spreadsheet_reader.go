@@ -0,0 +1,32 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var ErrUnsupportedSpreadsheet = errors.New("only .csv and .xlsx files are supported")
+
+type SpreadsheetFormat string
+
+var (
+	CSV  SpreadsheetFormat = "csv"
+	XLSX SpreadsheetFormat = "xlsx"
+)
+
+// SpreadsheetFormatOf tells the format from the extension of the file name
+func SpreadsheetFormatOf(fileName string) (SpreadsheetFormat, error) {
+	switch format := SpreadsheetFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")); format {
+	case CSV, XLSX:
+		return format, nil
+	}
+	return "", ErrUnsupportedSpreadsheet
+}
+
+// SpreadsheetReader reads the cells of the first sheet, row by row, as text
+type SpreadsheetReader interface {
+	ReadRows(ctx context.Context, content io.ReaderAt, size int64, format SpreadsheetFormat) ([][]string, error)
+}
@@ -0,0 +1,14 @@
+package filestorage
+
+import (
+	"context"
+	"io"
+)
+
+// FileStorage keeps uploaded files, e.g. avatars, and serves them from a public url
+type FileStorage interface {
+	// Put stores the content under key, replacing what was there, and returns the url it is served from
+	Put(ctx context.Context, key, contentType string, content io.Reader) (string, error)
+	// Delete removes the file, deleting a key that does not exist is not an error
+	Delete(ctx context.Context, key string) error
+}
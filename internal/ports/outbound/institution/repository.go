@@ -2,23 +2,113 @@ package institution
 
 import (
 	"context"
+	"errors"
 
 	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
 )
 
+var (
+	ErrInstitutionNotFound = errors.New("institution not found")
+	ErrStaffNotFound       = errors.New("staff not found")
+	ErrStaffExists         = errors.New("a staff with this email already exists in the institution")
+	ErrGroupNotFound       = errors.New("group not found")
+	ErrGroupExists         = errors.New("a group with this name already exists in the institution")
+	ErrCourseNotFound      = errors.New("course not found")
+	ErrCourseExists        = errors.New("a course with this name already exists in the institution")
+	ErrInviteNotFound      = errors.New("invite not found")
+	ErrImportNotFound      = errors.New("roster import not found")
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrTransferNotFound    = errors.New("ownership transfer not found")
+	ErrEmailDomainNotFound = errors.New("email domain not found")
+	ErrEmailDomainExists   = errors.New("the institution has already claimed this email domain")
+	ErrEmailDomainClaimed  = errors.New("the email domain is verified by another institution")
+)
+
+// MembershipReader lists the institutions a user belongs to, it is read outside the institution context e.g. at sign in
+type MembershipReader interface {
+	ListMembershipsByUser(ctx context.Context, userId institution.Id) ([]institution.Membership, error)
+}
+
+// PersonalDataReader finds what institutions hold about a person, it is read outside the institution context to export their account data.
+// Purging the account removes the same records.
+type PersonalDataReader interface {
+	// ListStaffRecordsOfPerson returns the staff records linked to the account or held under its email, removed ones included
+	ListStaffRecordsOfPerson(ctx context.Context, userId institution.Id, email institution.Email) ([]institution.StaffRecord, error)
+	// ListInvitesToPerson returns the invites sent to the email or to one of the staff records
+	ListInvitesToPerson(ctx context.Context, email institution.Email, staffIds []institution.Id) ([]institution.Invite, error)
+}
+
+// EmailDomainJoiner adds people who sign up with an email on a verified domain to its institution, it is used outside the institution context at sign up
+type EmailDomainJoiner interface {
+	// GetVerifiedEmailDomain returns the claim verified for the domain, a domain is verified by one institution at most
+	GetVerifiedEmailDomain(ctx context.Context, domain institution.DomainName) (*institution.EmailDomain, error)
+	GetStaffByEmail(ctx context.Context, institutionId institution.Id, email institution.Email) (*institution.Staff, error)
+	AddStaffToInstitution(ctx context.Context, institutionId institution.Id, staff institution.Staff) (*institution.Staff, error)
+	// LinkStaffUser saves the account and status of a staff who joined by email domain
+	LinkStaffUser(ctx context.Context, institutionId institution.Id, staff *institution.Staff) error
+}
+
 // InstitutionRepository defines the contract for interacting with institution aggregates.
 type InstitutionRepository interface {
-	// Institution
+	// Institution, created together with the staff already added to it
 	CreateInstitution(ctx context.Context, payload *institution.Institution) (*institution.Institution, error)
 	GetInstitutionById(ctx context.Context, id institution.Id) (*institution.Institution, error)
 	UpdateInstitution(ctx context.Context, inst *institution.Institution) error
 	DeleteInstitution(ctx context.Context, id institution.Id) error
 
 	// Staff
-	AddStaffToInstitution(ctx context.Context, institutionId institution.Id, staff institution.Staff) error
+	AddStaffToInstitution(ctx context.Context, institutionId institution.Id, staff institution.Staff) (*institution.Staff, error)
 	GetStaffById(ctx context.Context, institutionId, staffId institution.Id) (*institution.Staff, error)
+	GetStaffByEmail(ctx context.Context, institutionId institution.Id, email institution.Email) (*institution.Staff, error)
+	// UpdateStaff saves the name, role and status of the staff with the reason for the status
+	UpdateStaff(ctx context.Context, institutionId institution.Id, staff *institution.Staff) error
 	RemoveStaffFromInstitution(ctx context.Context, institutionId, staffId institution.Id) error
-	ListStaff(ctx context.Context, institutionId institution.Id) ([]institution.Staff, int, error)
+	// GetRemovedStaffById returns a soft deleted staff, ErrStaffNotFound if the staff is not removed
+	GetRemovedStaffById(ctx context.Context, institutionId, staffId institution.Id) (*institution.Staff, error)
+	RestoreStaff(ctx context.Context, institutionId institution.Id, staff *institution.Staff) error
+	ListStaff(ctx context.Context, institutionId institution.Id, filter institution.StaffFilter) ([]institution.Staff, int, error)
+
+	// Membership
+	IsInstitutionAdmin(ctx context.Context, institutionId, userId institution.Id) (bool, error)
+	GetStaffByUserId(ctx context.Context, institutionId, userId institution.Id) (*institution.Staff, error)
+
+	// Invites, tokens are stored hashed
+	// CreateInvite also revokes any other pending invite of the staff
+	CreateInvite(ctx context.Context, invite *institution.Invite) (*institution.Invite, error)
+	GetInviteById(ctx context.Context, institutionId, inviteId institution.Id) (*institution.Invite, error)
+	GetInviteByToken(ctx context.Context, token string) (*institution.Invite, error)
+	ListInvites(ctx context.Context, institutionId institution.Id) ([]institution.Invite, error)
+	// UpdateInvite saves the status and timestamps, and the token when it was renewed
+	UpdateInvite(ctx context.Context, invite *institution.Invite) error
+	// AcceptInvite marks the invite accepted and activates its staff as the user, all or nothing
+	AcceptInvite(ctx context.Context, invite *institution.Invite, userId institution.Id) error
+
+	// Ownership transfers, tokens are stored hashed
+	// CreateOwnershipTransfer also cancels any other pending transfer of the institution
+	CreateOwnershipTransfer(ctx context.Context, transfer *institution.OwnershipTransfer) (*institution.OwnershipTransfer, error)
+	// GetPendingOwnershipTransfer returns the pending transfer of the institution, which may have expired
+	GetPendingOwnershipTransfer(ctx context.Context, institutionId institution.Id) (*institution.OwnershipTransfer, error)
+	// GetOwnershipTransferByToken finds the transfer by the token of either party, with the staff id of the party it was sent to
+	GetOwnershipTransferByToken(ctx context.Context, token string) (transfer *institution.OwnershipTransfer, partyId institution.Id, err error)
+	// UpdateOwnershipTransfer saves the status, confirmations and timestamps of a pending transfer
+	UpdateOwnershipTransfer(ctx context.Context, transfer *institution.OwnershipTransfer) error
+	// CompleteOwnershipTransfer marks the transfer completed, makes the owner an admin and the other party the owner, all or nothing
+	CompleteOwnershipTransfer(ctx context.Context, transfer *institution.OwnershipTransfer) error
+
+	// Email domains, a mailbox token is stored hashed
+	CreateEmailDomain(ctx context.Context, domain *institution.EmailDomain) (*institution.EmailDomain, error)
+	GetEmailDomainById(ctx context.Context, institutionId, domainId institution.Id) (*institution.EmailDomain, error)
+	// GetEmailDomainByToken finds a mailbox claim by the token sent to the mailbox
+	GetEmailDomainByToken(ctx context.Context, token string) (*institution.EmailDomain, error)
+	ListEmailDomains(ctx context.Context, institutionId institution.Id) ([]institution.EmailDomain, error)
+	// UpdateEmailDomain saves the status and the join policy, ErrEmailDomainClaimed if another institution verified the domain first
+	UpdateEmailDomain(ctx context.Context, domain *institution.EmailDomain) error
+	DeleteEmailDomain(ctx context.Context, institutionId, domainId institution.Id) error
+
+	// Roster imports, the row results are saved with the import
+	CreateRosterImport(ctx context.Context, ri *institution.RosterImport) (*institution.RosterImport, error)
+	GetRosterImportById(ctx context.Context, institutionId, importId institution.Id) (*institution.RosterImport, error)
+	UpdateRosterImport(ctx context.Context, ri *institution.RosterImport) error
 
 	// Group
 	CreateGroup(ctx context.Context, institutionId institution.Id, group *institution.Group) (*institution.Group, error)
@@ -27,10 +117,38 @@ type InstitutionRepository interface {
 	DeleteGroup(ctx context.Context, institutionId, groupId institution.Id) error
 	AddStaffToGroup(ctx context.Context, institutionId, groupId, staffId institution.Id) error
 	RemoveStaffFromGroup(ctx context.Context, institutionId, groupId, staffId institution.Id) error
-	ListGroups(ctx context.Context, institutionId institution.Id) ([]institution.Group, int, error)
+	ListGroups(ctx context.Context, institutionId institution.Id, page institution.Page) ([]institution.Group, int, error)
+
+	// Course
+	CreateCourse(ctx context.Context, institutionId institution.Id, course *institution.Course) (*institution.Course, error)
+	GetCourseById(ctx context.Context, institutionId, courseId institution.Id) (*institution.Course, error)
+	UpdateCourse(ctx context.Context, institutionId institution.Id, course *institution.Course) error
+	// DeleteCourse also takes the course away from every group that could access it
+	DeleteCourse(ctx context.Context, institutionId, courseId institution.Id) error
+	ListCourses(ctx context.Context, institutionId institution.Id, filter institution.CourseFilter) ([]institution.Course, int, error)
+	// SetCourseCategory saves the category the course is in
+	SetCourseCategory(ctx context.Context, institutionId institution.Id, course *institution.Course) error
+
+	// Category
+	CreateCategory(ctx context.Context, institutionId institution.Id, category *institution.Category) (*institution.Category, error)
+	ListCategories(ctx context.Context, institutionId institution.Id) ([]institution.Category, error)
+	// UpdateCategory saves the name and the parent of the category
+	UpdateCategory(ctx context.Context, institutionId institution.Id, category *institution.Category) error
+	// DeleteCategory moves what is directly in the category, its sub categories, courses and staff, to newParentId first.
+	// With no new parent the sub categories go to the top level, the courses are left without a category and the staff leave it.
+	DeleteCategory(ctx context.Context, institutionId, categoryId institution.Id, newParentId *institution.Id) error
+	AddStaffToCategory(ctx context.Context, institutionId, categoryId, staffId institution.Id) error
+	RemoveStaffFromCategory(ctx context.Context, institutionId, categoryId, staffId institution.Id) error
+	ListStaffInCategories(ctx context.Context, institutionId institution.Id, categoryIds []institution.Id) ([]institution.Staff, error)
 
 	// Course Access (Group <-> Course)
 	AddAccessibleCourseToGroup(ctx context.Context, institutionId, groupId, courseId institution.Id) error
 	RemoveAccessibleCourseFromGroup(ctx context.Context, institutionId, groupId, courseId institution.Id) error
 	ListAccessibleCoursesForGroup(ctx context.Context, institutionId, groupId institution.Id) ([]institution.Course, error)
+	// ListAccessibleCoursesForStaff returns the courses any group of the staff can access, in any of the categories unless they are nil
+	ListAccessibleCoursesForStaff(ctx context.Context, institutionId, staffId institution.Id, categoryIds []institution.Id) ([]institution.Course, error)
+	CanStaffAccessCourse(ctx context.Context, institutionId, staffId, courseId institution.Id) (bool, error)
+
+	// Analytics
+	GetStaffActivity(ctx context.Context, institutionId institution.Id) (*institution.StaffActivity, error)
 }
@@ -0,0 +1,32 @@
+package institution
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/institution"
+)
+
+var ErrCrossTenantAccess = errors.New("the request is scoped to another institution")
+
+type tenantKey struct{}
+
+// WithTenant scopes the context to the tenant, repositories refuse to touch another institution's data with it
+func WithTenant(ctx context.Context, tenant *institution.Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant the context is scoped to, there is none for work not tied to one institution
+// e.g. creating an institution or accepting an invite
+func TenantFromContext(ctx context.Context) (*institution.Tenant, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(*institution.Tenant)
+	return tenant, ok && tenant != nil
+}
+
+// CheckTenant returns ErrCrossTenantAccess if the context is scoped to an institution other than institutionId
+func CheckTenant(ctx context.Context, institutionId institution.Id) error {
+	if tenant, ok := TenantFromContext(ctx); ok && tenant.InstitutionId() != institutionId {
+		return ErrCrossTenantAccess
+	}
+	return nil
+}
@@ -7,12 +7,47 @@ import (
 )
 
 type CustomClaims struct {
-	UserID string `json:"sub"`
-	Email  string `json:"email,omitempty"` // Optional field
+	UserID    string `json:"sub"`
+	Email     string `json:"email,omitempty"` // Optional field
+	SessionID string `json:"sid,omitempty"`   // session the token was issued for
+	// ImpersonatorID is the admin acting as the user, only set on impersonation tokens
+	ImpersonatorID string
+	// InstitutionID is the institution the token is scoped to, only set on tokens from switching institution
+	InstitutionID string
 }
+
+// IsImpersonation reports whether the token was issued for an admin to act as the user
+func (c *CustomClaims) IsImpersonation() bool {
+	return c.ImpersonatorID != ""
+}
+
+// JSONWebKey is the public part of a signing key (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
 type JwtMaker interface {
-	// CreateToken generates a JWT signed with HS256
-	CreateToken(userID, userEmail string, duration time.Duration) (string, error)
+	// CreateToken generates a JWT signed with the current signing key
+	CreateToken(userID, userEmail, sessionID string, duration time.Duration) (string, error)
+
+	// CreateTenantToken generates a JWT for the session that is scoped to one institution
+	CreateTenantToken(userID, userEmail, sessionID, institutionID string, duration time.Duration) (string, error)
+
+	// CreateImpersonationToken generates a JWT for impersonatorID to act as userID, it carries both
+	CreateImpersonationToken(userID, userEmail, impersonatorID string, duration time.Duration) (string, error)
 
 	// VerifyToken parses and validates the JWT token
 	VerifyToken(tokenStr string) (*CustomClaims, error)
@@ -22,6 +57,9 @@ type JwtMaker interface {
 
 	// ExtractAndVerifyToken extracts the token from the request and verifies it
 	ExtractAndVerifyToken(r *http.Request) (*CustomClaims, error)
+
+	// JWKS returns the public keys tokens can be verified with
+	JWKS() JSONWebKeySet
 }
 
 var (
@@ -0,0 +1,19 @@
+package loginattempt
+
+import (
+	"context"
+	"time"
+
+	"github.com/kaasikodes/assessmate_backend/internal/core/domain/user"
+)
+
+// LoginAttemptStore persists failed login counters. An in-memory store is fine for a single node,
+// replicas have to share a store (e.g. sql) so that the limits hold across the cluster.
+type LoginAttemptStore interface {
+	// GetLoginAttempt returns the attempt for the key, or a clean attempt if none is recorded
+	GetLoginAttempt(ctx context.Context, key user.LoginAttemptKey) (*user.LoginAttempt, error)
+	// RecordLoginFailure counts a failure against the key as one atomic step, so concurrent failures are never lost,
+	// and reports whether this failure locked the key
+	RecordLoginFailure(ctx context.Context, key user.LoginAttemptKey, now time.Time, policy user.LockoutPolicy) (*user.LoginAttempt, bool, error)
+	DeleteLoginAttempt(ctx context.Context, key user.LoginAttemptKey) error
+}
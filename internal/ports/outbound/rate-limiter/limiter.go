@@ -0,0 +1,26 @@
+package ratelimiter
+
+import (
+	"context"
+	"time"
+)
+
+// Policy is the number of requests allowed per window for a route.
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Result describes the state of a key after a request was counted against it.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // until the key is back to its full limit
+	RetryAfter time.Duration // until the next request is allowed, zero when allowed
+}
+
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, policy Policy) (Result, error)
+}
@@ -8,11 +8,23 @@ import (
 )
 
 var ErrUserNotFound = errors.New("user not found")
+var ErrSessionNotFound = errors.New("session not found")
+var ErrEmailChangeNotFound = errors.New("email change not found")
+var ErrApiKeyNotFound = errors.New("api key not found")
+var ErrProfileNotFound = errors.New("profile not found")
+var ErrRefreshTokenUsed = errors.New("refresh token already used")
 
 // ports should conform to language of core(in this case the domain and not application, as application is a bridge for adapter to domain(business) logic)
 type UserRepository interface {
 	CreateUser(ctx context.Context, payload *user.User) (*user.User, error)
 	UpdateUserPassword(ctx context.Context, payload *user.User) (*user.User, error)
+	UpdateUserEmail(ctx context.Context, payload *user.User) (*user.User, error)
+	// UpdateUserDeletedAt persists a scheduled or cancelled deletion
+	UpdateUserDeletedAt(ctx context.Context, payload *user.User) (*user.User, error)
+	// GetUsersDeletedBefore lists soft-deleted users that have not been purged yet
+	GetUsersDeletedBefore(ctx context.Context, cutoff user.DateTime) ([]user.User, error)
+	// PurgeUser persists an anonymised user and removes everything else held about them
+	PurgeUser(ctx context.Context, payload *user.User) error
 	VerifyUser(ctx context.Context, payload *user.User) (*user.User, error)
 	ChangeUserStatus(ctx context.Context, userId user.Id, status user.UserStatus) (*user.User, error)
 	CreateToken(ctx context.Context, value user.TokenValue, tokenType user.TokenType, userId user.Id, expiresAt user.DateTime) (*user.Token, error)
@@ -21,4 +33,47 @@ type UserRepository interface {
 	GetUserById(ctx context.Context, userId user.Id) (*user.User, error)
 	GetUserByEmail(ctx context.Context, user user.Email) (*user.User, error)
 	GetUsers(ctx context.Context, filter *user.UserFilter) ([]user.User, int, error)
+	// GetUserRoles returns the user's active roles
+	GetUserRoles(ctx context.Context, userId user.Id) ([]user.Role, error)
+
+	// Sessions
+	CreateSession(ctx context.Context, session *user.Session) (*user.Session, error)
+	GetSessionById(ctx context.Context, sessionId user.Id) (*user.Session, error)
+	ListActiveSessions(ctx context.Context, userId user.Id) ([]user.Session, error)
+	RevokeSession(ctx context.Context, userId, sessionId user.Id) error
+	// TouchSessions sets the last seen time of many sessions in one go
+	TouchSessions(ctx context.Context, lastSeen map[user.Id]user.DateTime) error
+	CreateRefreshToken(ctx context.Context, value user.TokenValue, userId, sessionId user.Id, expiresAt user.DateTime) (*user.Token, error)
+	GetRefreshToken(ctx context.Context, value user.TokenValue) (*user.Token, error)
+	// UseRefreshToken marks the token used once and for all, ErrRefreshTokenUsed when it already was
+	UseRefreshToken(ctx context.Context, id user.Id) error
+	// CreateDeviceBoundToken saves a token together with the hash of the device nonce it is bound to
+	CreateDeviceBoundToken(ctx context.Context, token *user.Token) (*user.Token, error)
+
+	// Email changes
+	CreateEmailChange(ctx context.Context, change *user.EmailChange) (*user.EmailChange, error)
+	GetEmailChangeById(ctx context.Context, userId, changeId user.Id) (*user.EmailChange, error)
+	UpdateEmailChange(ctx context.Context, change *user.EmailChange) error
+	// CancelPendingEmailChanges also deletes the tokens issued for the changes it cancels
+	CancelPendingEmailChanges(ctx context.Context, userId user.Id) error
+	// CreateEmailChangeToken saves a confirmation or revert token for the email change it was issued for
+	CreateEmailChangeToken(ctx context.Context, value user.TokenValue, tokenType user.TokenType, userId, changeId user.Id, expiresAt user.DateTime) (*user.Token, error)
+	// DeleteEmailChangeTokens deletes every confirmation and revert token of the user
+	DeleteEmailChangeTokens(ctx context.Context, userId user.Id) error
+	ListEmailChanges(ctx context.Context, userId user.Id) ([]user.EmailChange, error)
+
+	// Api keys
+	CreateApiKey(ctx context.Context, key *user.ApiKey) (*user.ApiKey, error)
+	GetApiKeyByPrefix(ctx context.Context, prefix string) (*user.ApiKey, error)
+	// ListApiKeys returns the user's keys that have not been revoked
+	ListApiKeys(ctx context.Context, userId user.Id) ([]user.ApiKey, error)
+	RevokeApiKey(ctx context.Context, userId, keyId user.Id) error
+	UpdateApiKeyLastUsedAt(ctx context.Context, key *user.ApiKey) error
+	// CanManageInstitution reports whether the user is an active admin of the institution
+	CanManageInstitution(ctx context.Context, userId, institutionId user.Id) (bool, error)
+
+	// Profiles
+	GetProfile(ctx context.Context, userId user.Id) (*user.Profile, error)
+	// SaveProfile creates the profile or replaces the saved one
+	SaveProfile(ctx context.Context, profile *user.Profile) error
 }